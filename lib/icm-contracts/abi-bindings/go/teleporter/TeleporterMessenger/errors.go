@@ -0,0 +1,70 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package teleportermessenger
+
+import "errors"
+
+// Errors matching the revert reasons of the TeleporterMessenger contract and its ReceiptQueue
+var (
+	ErrZeroFeeAssetContractAddress = errors.New("TeleporterMessenger: zero fee asset contract address")
+	ErrZeroAdditionalFeeAmount     = errors.New("TeleporterMessenger: zero additional fee amount")
+	ErrInvalidFeeAssetAddress      = errors.New("TeleporterMessenger: invalid fee asset contract address")
+	ErrInvalidDestinationChainID   = errors.New("TeleporterMessenger: invalid destination chain ID")
+	ErrInvalidOriginSenderAddress  = errors.New("TeleporterMessenger: invalid origin sender address")
+	ErrInvalidWarpMessage          = errors.New("TeleporterMessenger: invalid warp message")
+	ErrInvalidMessageHash          = errors.New("TeleporterMessenger: invalid message hash")
+	ErrZeroBlockchainID            = errors.New("TeleporterMessenger: zero blockchain ID")
+	ErrZeroMessageNonce            = errors.New("TeleporterMessenger: zero message nonce")
+	ErrMessageNotFound             = errors.New("TeleporterMessenger: message not found")
+	ErrMessageNotReceived          = errors.New("TeleporterMessenger: message not received")
+	ErrMessageAlreadyReceived      = errors.New("TeleporterMessenger: message already received")
+	ErrMessageIDNotFromSource      = errors.New("TeleporterMessenger: message ID not from source blockchain")
+	ErrUnauthorizedRelayer         = errors.New("TeleporterMessenger: unauthorized relayer")
+	ErrInsufficientGas             = errors.New("TeleporterMessenger: insufficient gas")
+	ErrDestinationAddressNoCode    = errors.New("TeleporterMessenger: destination address has no code")
+	ErrRetryExecutionFailed        = errors.New("TeleporterMessenger: retry execution failed")
+	ErrReceiptNotFound             = errors.New("TeleporterMessenger: receipt not found")
+	ErrNoRewardToRedeem            = errors.New("TeleporterMessenger: no reward to redeem")
+	ErrEmptyReceiptQueue           = errors.New("ReceiptQueue: empty queue")
+	ErrReceiptIndexOutOfBounds     = errors.New("ReceiptQueue: index out of bounds")
+)
+
+var revertErrors = make(map[string]error)
+
+func init() {
+	for _, err := range []error{
+		ErrZeroFeeAssetContractAddress,
+		ErrZeroAdditionalFeeAmount,
+		ErrInvalidFeeAssetAddress,
+		ErrInvalidDestinationChainID,
+		ErrInvalidOriginSenderAddress,
+		ErrInvalidWarpMessage,
+		ErrInvalidMessageHash,
+		ErrZeroBlockchainID,
+		ErrZeroMessageNonce,
+		ErrMessageNotFound,
+		ErrMessageNotReceived,
+		ErrMessageAlreadyReceived,
+		ErrMessageIDNotFromSource,
+		ErrUnauthorizedRelayer,
+		ErrInsufficientGas,
+		ErrDestinationAddressNoCode,
+		ErrRetryExecutionFailed,
+		ErrReceiptNotFound,
+		ErrNoRewardToRedeem,
+		ErrEmptyReceiptQueue,
+		ErrReceiptIndexOutOfBounds,
+	} {
+		revertErrors[err.Error()] = err
+	}
+}
+
+// RevertError returns the error matching a revert reason of the TeleporterMessenger contract, so
+// that on-chain reverts, Validate errors and simulated reverts can be handled alike.
+func RevertError(reason string) error {
+	if err, ok := revertErrors[reason]; ok {
+		return err
+	}
+	return errors.New(reason)
+}
@@ -0,0 +1,16 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package teleportermessenger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRevertError(t *testing.T) {
+	require.ErrorIs(t, RevertError("TeleporterMessenger: insufficient gas"), ErrInsufficientGas)
+	reason := "TeleporterMessenger: unauthorized relayer"
+	require.EqualError(t, RevertError(reason), reason)
+}
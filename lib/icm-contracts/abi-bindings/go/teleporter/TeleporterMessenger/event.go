@@ -5,6 +5,7 @@ package teleportermessenger
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
 	"strings"
@@ -26,6 +27,7 @@ const (
 	MessageExecuted
 	RelayerRewardsRedeemed
 	ReceiptReceived
+	BlockchainIDInitialized
 
 	sendCrossChainMessageStr    = "SendCrossChainMessage"
 	receiveCrossChainMessageStr = "ReceiveCrossChainMessage"
@@ -34,9 +36,15 @@ const (
 	messageExecutedStr          = "MessageExecuted"
 	relayerRewardsRedeemedStr   = "RelayerRewardsRedeemed"
 	receiptReceivedStr          = "ReceiptReceived"
+	blockchainIDInitializedStr  = "BlockchainIDInitialized"
 	unknownStr                  = "Unknown"
 )
 
+var (
+	ErrNoTopics     = errors.New("log has no topics")
+	ErrUnknownEvent = errors.New("unknown event")
+)
+
 // String returns the string representation of an Event
 func (e Event) String() string {
 	switch e {
@@ -54,6 +62,8 @@ func (e Event) String() string {
 		return relayerRewardsRedeemedStr
 	case ReceiptReceived:
 		return receiptReceivedStr
+	case BlockchainIDInitialized:
+		return blockchainIDInitializedStr
 	default:
 		return unknownStr
 	}
@@ -76,54 +86,181 @@ func ToEvent(e string) (Event, error) {
 		return RelayerRewardsRedeemed, nil
 	case strings.ToLower(receiptReceivedStr):
 		return ReceiptReceived, nil
+	case strings.ToLower(blockchainIDInitializedStr):
+		return BlockchainIDInitialized, nil
 	default:
-		return Unknown, fmt.Errorf("unknown event %s", e)
+		return Unknown, fmt.Errorf("%w %s", ErrUnknownEvent, e)
 	}
 }
 
+// ParsedLog is a Teleporter log parsed into its event and the corresponding event struct
+type ParsedLog struct {
+	Event Event
+	Value any
+}
+
 // FilterTeleporterEvents parses the topics and data of a Teleporter log into the corresponding Teleporter event
 func FilterTeleporterEvents(topics []common.Hash, data []byte, event string) (fmt.Stringer, error) {
 	e, err := ToEvent(event)
 	if err != nil {
 		return nil, err
 	}
-	var out fmt.Stringer
+	out, err := newEvent(e)
+	if err != nil {
+		return nil, err
+	}
+	if err := UnpackEvent(out, e.String(), topics, data); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ParseLog parses a TeleporterMessenger log into the event identified by its first topic, and a
+// pointer to the corresponding TeleporterMessenger event struct, with Raw set to the log
+func ParseLog(log types.Log) (Event, any, error) {
+	if len(log.Topics) == 0 {
+		return Unknown, nil, ErrNoTopics
+	}
+	teleporterABI, err := TeleporterMessengerMetaData.GetAbi()
+	if err != nil {
+		return Unknown, nil, fmt.Errorf("failed to get abi: %v", err)
+	}
+	abiEvent, err := teleporterABI.EventByID(log.Topics[0])
+	if err != nil {
+		return Unknown, nil, fmt.Errorf("%w: topic %s", ErrUnknownEvent, log.Topics[0])
+	}
+	e, err := ToEvent(abiEvent.Name)
+	if err != nil {
+		return Unknown, nil, err
+	}
+	out, err := newEvent(e)
+	if err != nil {
+		return Unknown, nil, err
+	}
+	if err := UnpackEvent(out, e.String(), log.Topics, log.Data); err != nil {
+		return Unknown, nil, err
+	}
+	setRaw(out, log)
+	return e, out, nil
+}
+
+// ParseLogs parses each of the logs with ParseLog, stopping at the first log that fails to parse
+func ParseLogs(logs []types.Log) ([]ParsedLog, error) {
+	parsed := make([]ParsedLog, 0, len(logs))
+	for i, log := range logs {
+		e, out, err := ParseLog(log)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse log %d: %w", i, err)
+		}
+		parsed = append(parsed, ParsedLog{Event: e, Value: out})
+	}
+	return parsed, nil
+}
+
+// newEvent returns a pointer to an empty event struct of the given event
+func newEvent(e Event) (fmt.Stringer, error) {
 	switch e {
 	case SendCrossChainMessage:
-		out = new(TeleporterMessengerSendCrossChainMessage)
+		return new(TeleporterMessengerSendCrossChainMessage), nil
 	case ReceiveCrossChainMessage:
-		out = new(TeleporterMessengerReceiveCrossChainMessage)
+		return new(TeleporterMessengerReceiveCrossChainMessage), nil
 	case AddFeeAmount:
-		out = new(TeleporterMessengerAddFeeAmount)
+		return new(TeleporterMessengerAddFeeAmount), nil
 	case MessageExecutionFailed:
-		out = new(TeleporterMessengerMessageExecutionFailed)
+		return new(TeleporterMessengerMessageExecutionFailed), nil
 	case MessageExecuted:
-		out = new(TeleporterMessengerMessageExecuted)
+		return new(TeleporterMessengerMessageExecuted), nil
 	case RelayerRewardsRedeemed:
-		out = new(TeleporterMessengerRelayerRewardsRedeemed)
+		return new(TeleporterMessengerRelayerRewardsRedeemed), nil
 	case ReceiptReceived:
-		out = new(TeleporterMessengerReceiptReceived)
+		return new(TeleporterMessengerReceiptReceived), nil
+	case BlockchainIDInitialized:
+		return new(TeleporterMessengerBlockchainIDInitialized), nil
 	default:
-		return nil, fmt.Errorf("unknown event %s", e.String())
+		return nil, fmt.Errorf("%w %s", ErrUnknownEvent, e.String())
 	}
-	if err := UnpackEvent(out, e.String(), topics, data); err != nil {
-		return nil, err
+}
+
+// setRaw sets the raw log of an event struct returned by newEvent, which UnpackEvent leaves unset
+func setRaw(out fmt.Stringer, log types.Log) {
+	switch e := out.(type) {
+	case *TeleporterMessengerSendCrossChainMessage:
+		e.Raw = log
+	case *TeleporterMessengerReceiveCrossChainMessage:
+		e.Raw = log
+	case *TeleporterMessengerAddFeeAmount:
+		e.Raw = log
+	case *TeleporterMessengerMessageExecutionFailed:
+		e.Raw = log
+	case *TeleporterMessengerMessageExecuted:
+		e.Raw = log
+	case *TeleporterMessengerRelayerRewardsRedeemed:
+		e.Raw = log
+	case *TeleporterMessengerReceiptReceived:
+		e.Raw = log
+	case *TeleporterMessengerBlockchainIDInitialized:
+		e.Raw = log
 	}
-	return out, nil
 }
 
 func (t TeleporterMessengerSendCrossChainMessage) String() string {
-	outJson, _ := json.MarshalIndent(ReadableTeleporterMessengerSendCrossChainMessage{
-		MessageID:               common.Hash(t.MessageID),
-		DestinationBlockchainID: ids.ID(t.DestinationBlockchainID),
-		Message:                 toReadableTeleporterMessage(t.Message),
-		FeeInfo:                 t.FeeInfo,
-		Raw:                     t.Raw,
-	}, "", "  ")
+	outJson, _ := json.MarshalIndent(t, "", "  ")
+
+	return string(outJson)
+}
+
+func (t TeleporterMessengerReceiveCrossChainMessage) String() string {
+	outJson, _ := json.MarshalIndent(t, "", "  ")
+
+	return string(outJson)
+}
+
+func (t TeleporterMessengerAddFeeAmount) String() string {
+	outJson, _ := json.MarshalIndent(t, "", "  ")
+
+	return string(outJson)
+}
+
+func (t TeleporterMessengerMessageExecutionFailed) String() string {
+	outJson, _ := json.MarshalIndent(t, "", "  ")
+
+	return string(outJson)
+}
+
+func (t TeleporterMessengerMessageExecuted) String() string {
+	outJson, _ := json.MarshalIndent(t, "", "  ")
+
+	return string(outJson)
+}
+
+func (t TeleporterMessengerRelayerRewardsRedeemed) String() string {
+	outJson, _ := json.MarshalIndent(t, "", "  ")
+
+	return string(outJson)
+}
+
+func (t TeleporterMessengerReceiptReceived) String() string {
+	outJson, _ := json.MarshalIndent(t, "", "  ")
+
+	return string(outJson)
+}
+
+func (t TeleporterMessengerBlockchainIDInitialized) String() string {
+	outJson, _ := json.MarshalIndent(t, "", "  ")
+
+	return string(outJson)
+}
+
+func (t TeleporterMessage) String() string {
+	outJson, _ := json.MarshalIndent(t, "", "  ")
 
 	return string(outJson)
 }
 
+// ReadableTeleporterMessengerSendCrossChainMessage was the readable JSON form of
+// TeleporterMessengerSendCrossChainMessage, which now marshals to readable JSON itself.
+//
+// Deprecated: marshal TeleporterMessengerSendCrossChainMessage directly.
 type ReadableTeleporterMessengerSendCrossChainMessage struct {
 	MessageID               common.Hash
 	DestinationBlockchainID ids.ID
@@ -132,19 +269,10 @@ type ReadableTeleporterMessengerSendCrossChainMessage struct {
 	Raw                     types.Log
 }
 
-func (t TeleporterMessengerReceiveCrossChainMessage) String() string {
-	outJson, _ := json.MarshalIndent(ReadableTeleporterMessengerReceiveCrossChainMessage{
-		MessageID:          common.Hash(t.MessageID),
-		SourceBlockchainID: ids.ID(t.SourceBlockchainID),
-		Deliverer:          t.Deliverer,
-		RewardRedeemer:     t.RewardRedeemer,
-		Message:            toReadableTeleporterMessage(t.Message),
-		Raw:                t.Raw,
-	}, "", "  ")
-
-	return string(outJson)
-}
-
+// ReadableTeleporterMessengerReceiveCrossChainMessage was the readable JSON form of
+// TeleporterMessengerReceiveCrossChainMessage, which now marshals to readable JSON itself.
+//
+// Deprecated: marshal TeleporterMessengerReceiveCrossChainMessage directly.
 type ReadableTeleporterMessengerReceiveCrossChainMessage struct {
 	MessageID          common.Hash
 	SourceBlockchainID ids.ID
@@ -154,33 +282,20 @@ type ReadableTeleporterMessengerReceiveCrossChainMessage struct {
 	Raw                types.Log
 }
 
-func (t TeleporterMessengerAddFeeAmount) String() string {
-	outJson, _ := json.MarshalIndent(ReadableTeleporterMessengerAddFeeAmount{
-		MessageID:      common.Hash(t.MessageID),
-		UpdatedFeeInfo: t.UpdatedFeeInfo,
-		Raw:            t.Raw,
-	}, "", "  ")
-
-	return string(outJson)
-}
-
+// ReadableTeleporterMessengerAddFeeAmount was the readable JSON form of
+// TeleporterMessengerAddFeeAmount, which now marshals to readable JSON itself.
+//
+// Deprecated: marshal TeleporterMessengerAddFeeAmount directly.
 type ReadableTeleporterMessengerAddFeeAmount struct {
 	MessageID      common.Hash
 	UpdatedFeeInfo TeleporterFeeInfo
 	Raw            types.Log
 }
 
-func (t TeleporterMessengerMessageExecutionFailed) String() string {
-	outJson, _ := json.MarshalIndent(ReadableTeleporterMessengerMessageExecutionFailed{
-		MessageID:          common.Hash(t.MessageID),
-		SourceBlockchainID: ids.ID(t.SourceBlockchainID),
-		Message:            toReadableTeleporterMessage(t.Message),
-		Raw:                t.Raw,
-	}, "", "  ")
-
-	return string(outJson)
-}
-
+// ReadableTeleporterMessengerMessageExecutionFailed was the readable JSON form of
+// TeleporterMessengerMessageExecutionFailed, which now marshals to readable JSON itself.
+//
+// Deprecated: marshal TeleporterMessengerMessageExecutionFailed directly.
 type ReadableTeleporterMessengerMessageExecutionFailed struct {
 	MessageID          common.Hash
 	SourceBlockchainID ids.ID
@@ -188,40 +303,20 @@ type ReadableTeleporterMessengerMessageExecutionFailed struct {
 	Raw                types.Log
 }
 
-func (t TeleporterMessengerMessageExecuted) String() string {
-	outJson, _ := json.MarshalIndent(ReadableTeleporterMessengerMessageExecuted{
-		MessageID:          common.Hash(t.MessageID),
-		SourceBlockchainID: ids.ID(t.SourceBlockchainID),
-		Raw:                t.Raw,
-	}, "", "  ")
-
-	return string(outJson)
-}
-
+// ReadableTeleporterMessengerMessageExecuted was the readable JSON form of
+// TeleporterMessengerMessageExecuted, which now marshals to readable JSON itself.
+//
+// Deprecated: marshal TeleporterMessengerMessageExecuted directly.
 type ReadableTeleporterMessengerMessageExecuted struct {
 	MessageID          common.Hash
 	SourceBlockchainID ids.ID
 	Raw                types.Log
 }
 
-func (t TeleporterMessengerRelayerRewardsRedeemed) String() string {
-	outJson, _ := json.MarshalIndent(t, "", "  ")
-
-	return string(outJson)
-}
-
-func (t TeleporterMessengerReceiptReceived) String() string {
-	outJson, _ := json.MarshalIndent(ReadableTeleporterMessengerReceiptReceived{
-		MessageID:               common.Hash(t.MessageID),
-		DestinationBlockchainID: ids.ID(t.DestinationBlockchainID),
-		RelayerRewardAddress:    t.RelayerRewardAddress,
-		FeeInfo:                 t.FeeInfo,
-		Raw:                     t.Raw,
-	}, "", "  ")
-
-	return string(outJson)
-}
-
+// ReadableTeleporterMessengerReceiptReceived was the readable JSON form of
+// TeleporterMessengerReceiptReceived, which now marshals to readable JSON itself.
+//
+// Deprecated: marshal TeleporterMessengerReceiptReceived directly.
 type ReadableTeleporterMessengerReceiptReceived struct {
 	MessageID               common.Hash
 	DestinationBlockchainID ids.ID
@@ -230,25 +325,19 @@ type ReadableTeleporterMessengerReceiptReceived struct {
 	Raw                     types.Log
 }
 
-func toReadableTeleporterMessage(t TeleporterMessage) ReadableTeleporterMessage {
-	return ReadableTeleporterMessage{
-		MessageNonce:            t.MessageNonce,
-		OriginSenderAddress:     t.OriginSenderAddress,
-		DestinationBlockchainID: ids.ID(t.DestinationBlockchainID),
-		DestinationAddress:      t.DestinationAddress,
-		RequiredGasLimit:        t.RequiredGasLimit,
-		AllowedRelayerAddresses: t.AllowedRelayerAddresses,
-		Receipts:                t.Receipts,
-		Message:                 t.Message,
-	}
-}
-
-func (t TeleporterMessage) String() string {
-	outJson, _ := json.MarshalIndent(toReadableTeleporterMessage(t), "", "  ")
-
-	return string(outJson)
+// ReadableTeleporterMessengerBlockchainIDInitialized was the readable JSON form of
+// TeleporterMessengerBlockchainIDInitialized, which now marshals to readable JSON itself.
+//
+// Deprecated: marshal TeleporterMessengerBlockchainIDInitialized directly.
+type ReadableTeleporterMessengerBlockchainIDInitialized struct {
+	BlockchainID ids.ID
+	Raw          types.Log
 }
 
+// ReadableTeleporterMessage was the readable JSON form of TeleporterMessage, which now marshals to
+// readable JSON itself.
+//
+// Deprecated: marshal TeleporterMessage directly.
 type ReadableTeleporterMessage struct {
 	MessageNonce            *big.Int
 	OriginSenderAddress     common.Address
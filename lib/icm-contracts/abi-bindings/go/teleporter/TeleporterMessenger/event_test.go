@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/subnet-evm/core/types"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/stretchr/testify/require"
 )
@@ -25,6 +26,7 @@ func TestEventString(t *testing.T) {
 			{MessageExecutionFailed, messageExecutionFailedStr},
 			{MessageExecuted, messageExecutedStr},
 			{RelayerRewardsRedeemed, relayerRewardsRedeemedStr},
+			{BlockchainIDInitialized, blockchainIDInitializedStr},
 		}
 	)
 
@@ -49,6 +51,7 @@ func TestToEvent(t *testing.T) {
 			{messageExecutionFailedStr, MessageExecutionFailed, false},
 			{messageExecutedStr, MessageExecuted, false},
 			{relayerRewardsRedeemedStr, RelayerRewardsRedeemed, false},
+			{blockchainIDInitializedStr, BlockchainIDInitialized, false},
 		}
 	)
 
@@ -143,3 +146,104 @@ func TestFilterTeleporterEvents(t *testing.T) {
 		})
 	}
 }
+
+func TestParseLog(t *testing.T) {
+	mockBlockchainID := ids.ID{1, 2, 3, 4}
+	mockMessageID := ids.ID{9, 10, 11, 12}
+	feeInfo := TeleporterFeeInfo{
+		FeeTokenAddress: common.HexToAddress("0x0123456789abcdef0123456789abcdef01234567"),
+		Amount:          big.NewInt(1),
+	}
+
+	teleporterABI, err := TeleporterMessengerMetaData.GetAbi()
+	require.NoError(t, err)
+	newLog := func(event Event, args ...interface{}) types.Log {
+		topics, data, err := teleporterABI.PackEvent(event.String(), args...)
+		require.NoError(t, err)
+		return types.Log{Topics: topics, Data: data, BlockNumber: 7, Index: 2}
+	}
+
+	blockchainIDLog := newLog(BlockchainIDInitialized, mockBlockchainID)
+	addFeeLog := newLog(AddFeeAmount, mockMessageID, feeInfo)
+	executedLog := newLog(MessageExecuted, mockMessageID, mockBlockchainID)
+
+	var (
+		tests = []struct {
+			name     string
+			log      types.Log
+			event    Event
+			expected interface{}
+			err      error
+		}{
+			{
+				name:  "BlockchainIDInitialized",
+				log:   blockchainIDLog,
+				event: BlockchainIDInitialized,
+				expected: &TeleporterMessengerBlockchainIDInitialized{
+					BlockchainID: mockBlockchainID,
+					Raw:          blockchainIDLog,
+				},
+			},
+			{
+				name:  "AddFeeAmount",
+				log:   addFeeLog,
+				event: AddFeeAmount,
+				expected: &TeleporterMessengerAddFeeAmount{
+					MessageID:      mockMessageID,
+					UpdatedFeeInfo: feeInfo,
+					Raw:            addFeeLog,
+				},
+			},
+			{
+				name:  "MessageExecuted",
+				log:   executedLog,
+				event: MessageExecuted,
+				expected: &TeleporterMessengerMessageExecuted{
+					MessageID:          mockMessageID,
+					SourceBlockchainID: mockBlockchainID,
+					Raw:                executedLog,
+				},
+			},
+			{
+				name:  "no topics",
+				log:   types.Log{},
+				event: Unknown,
+				err:   ErrNoTopics,
+			},
+			{
+				name:  "unknown topic",
+				log:   types.Log{Topics: []common.Hash{{1}}},
+				event: Unknown,
+				err:   ErrUnknownEvent,
+			},
+		}
+	)
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			event, out, err := ParseLog(test.log)
+			require.ErrorIs(t, err, test.err)
+			require.Equal(t, test.event, event)
+			if test.err == nil {
+				require.Equal(t, test.expected, out)
+			}
+		})
+	}
+
+	parsed, err := ParseLogs([]types.Log{blockchainIDLog, executedLog})
+	require.NoError(t, err)
+	require.Equal(t, []ParsedLog{
+		{Event: BlockchainIDInitialized, Value: &TeleporterMessengerBlockchainIDInitialized{
+			BlockchainID: mockBlockchainID,
+			Raw:          blockchainIDLog,
+		}},
+		{Event: MessageExecuted, Value: &TeleporterMessengerMessageExecuted{
+			MessageID:          mockMessageID,
+			SourceBlockchainID: mockBlockchainID,
+			Raw:                executedLog,
+		}},
+	}, parsed)
+
+	_, err = ParseLogs([]types.Log{executedLog, {}})
+	require.ErrorIs(t, err, ErrNoTopics)
+}
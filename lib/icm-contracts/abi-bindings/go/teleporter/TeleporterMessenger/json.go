@@ -0,0 +1,560 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package teleportermessenger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+)
+
+// JSONVersion is the version of the JSON schema of the Teleporter types. Messages, message
+// inputs and events carry it in their "version" field. Fee infos and receipts are versioned by
+// the object containing them.
+const JSONVersion = 1
+
+var (
+	ErrUnsupportedJSONVersion = errors.New("unsupported JSON schema version")
+	ErrJSONEventMismatch      = errors.New("JSON event does not match the event type")
+	ErrInvalidJSONAmount      = errors.New("invalid amount, expected a uint256 as a decimal string")
+)
+
+// jsonAmount is a uint256 encoded as a decimal string. Plain JSON numbers and 0x prefixed hex
+// strings are accepted when decoding, so that hand written JSON and YAML files can be read.
+type jsonAmount struct {
+	*big.Int
+}
+
+func (a jsonAmount) MarshalJSON() ([]byte, error) {
+	if a.Int == nil {
+		return []byte("null"), nil
+	}
+	return json.Marshal(a.Int.String())
+}
+
+func (a *jsonAmount) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		a.Int = nil
+		return nil
+	}
+	s := string(b)
+	if unquoted, ok := strings.CutPrefix(s, `"`); ok {
+		s, ok = strings.CutSuffix(unquoted, `"`)
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrInvalidJSONAmount, b)
+		}
+	}
+	var (
+		amount = new(big.Int)
+		ok     bool
+	)
+	if hex, isHex := strings.CutPrefix(s, "0x"); isHex {
+		amount, ok = amount.SetString(hex, 16)
+	} else {
+		amount, ok = amount.SetString(s, 10)
+	}
+	if !ok || amount.Sign() < 0 || amount.Cmp(math.MaxBig256) > 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidJSONAmount, b)
+	}
+	a.Int = amount
+	return nil
+}
+
+// jsonLog returns the log to encode for the raw log of an event, or nil if it is unset.
+func jsonLog(log types.Log) *types.Log {
+	if reflect.ValueOf(log).IsZero() {
+		return nil
+	}
+	return &log
+}
+
+func fromJSONLog(log *types.Log) types.Log {
+	if log == nil {
+		return types.Log{}
+	}
+	return *log
+}
+
+// checkJSONVersion returns an error if version is set to a version other than JSONVersion.
+// A missing version is read as the current one.
+func checkJSONVersion(version *int) error {
+	if version != nil && *version != JSONVersion {
+		return fmt.Errorf("%w: %d", ErrUnsupportedJSONVersion, *version)
+	}
+	return nil
+}
+
+// checkJSONEvent returns an error if event is set to an event other than expected.
+func checkJSONEvent(event string, expected Event) error {
+	if event != "" && event != expected.String() {
+		return fmt.Errorf("%w: %s is not %s", ErrJSONEventMismatch, event, expected)
+	}
+	return nil
+}
+
+// unmarshalStrict decodes b into v, rejecting unknown fields so that misspelled fields are not
+// silently dropped.
+func unmarshalStrict(b []byte, v any) error {
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.DisallowUnknownFields()
+	return d.Decode(v)
+}
+
+type teleporterFeeInfoJSON struct {
+	FeeTokenAddress common.Address `json:"feeTokenAddress"`
+	Amount          jsonAmount     `json:"amount"`
+}
+
+// MarshalJSON encodes the fee info with its amount as a decimal string
+func (f TeleporterFeeInfo) MarshalJSON() ([]byte, error) {
+	return json.Marshal(teleporterFeeInfoJSON{
+		FeeTokenAddress: f.FeeTokenAddress,
+		Amount:          jsonAmount{f.Amount},
+	})
+}
+
+// UnmarshalJSON decodes a fee info encoded by MarshalJSON
+func (f *TeleporterFeeInfo) UnmarshalJSON(b []byte) error {
+	var dec teleporterFeeInfoJSON
+	if err := unmarshalStrict(b, &dec); err != nil {
+		return err
+	}
+	*f = TeleporterFeeInfo{
+		FeeTokenAddress: dec.FeeTokenAddress,
+		Amount:          dec.Amount.Int,
+	}
+	return nil
+}
+
+type teleporterMessageReceiptJSON struct {
+	ReceivedMessageNonce jsonAmount     `json:"receivedMessageNonce"`
+	RelayerRewardAddress common.Address `json:"relayerRewardAddress"`
+}
+
+// MarshalJSON encodes the receipt with its nonce as a decimal string
+func (r TeleporterMessageReceipt) MarshalJSON() ([]byte, error) {
+	return json.Marshal(teleporterMessageReceiptJSON{
+		ReceivedMessageNonce: jsonAmount{r.ReceivedMessageNonce},
+		RelayerRewardAddress: r.RelayerRewardAddress,
+	})
+}
+
+// UnmarshalJSON decodes a receipt encoded by MarshalJSON
+func (r *TeleporterMessageReceipt) UnmarshalJSON(b []byte) error {
+	var dec teleporterMessageReceiptJSON
+	if err := unmarshalStrict(b, &dec); err != nil {
+		return err
+	}
+	*r = TeleporterMessageReceipt{
+		ReceivedMessageNonce: dec.ReceivedMessageNonce.Int,
+		RelayerRewardAddress: dec.RelayerRewardAddress,
+	}
+	return nil
+}
+
+type teleporterMessageJSON struct {
+	Version                 *int                       `json:"version"`
+	MessageNonce            jsonAmount                 `json:"messageNonce"`
+	OriginSenderAddress     common.Address             `json:"originSenderAddress"`
+	DestinationBlockchainID ids.ID                     `json:"destinationBlockchainID"`
+	DestinationAddress      common.Address             `json:"destinationAddress"`
+	RequiredGasLimit        jsonAmount                 `json:"requiredGasLimit"`
+	AllowedRelayerAddresses []common.Address           `json:"allowedRelayerAddresses"`
+	Receipts                []TeleporterMessageReceipt `json:"receipts"`
+	Message                 hexutil.Bytes              `json:"message"`
+}
+
+// MarshalJSON encodes the message with hex bytes, decimal string integers and a CB58
+// destination blockchain ID
+func (m TeleporterMessage) MarshalJSON() ([]byte, error) {
+	version := JSONVersion
+	return json.Marshal(teleporterMessageJSON{
+		Version:                 &version,
+		MessageNonce:            jsonAmount{m.MessageNonce},
+		OriginSenderAddress:     m.OriginSenderAddress,
+		DestinationBlockchainID: m.DestinationBlockchainID,
+		DestinationAddress:      m.DestinationAddress,
+		RequiredGasLimit:        jsonAmount{m.RequiredGasLimit},
+		AllowedRelayerAddresses: m.AllowedRelayerAddresses,
+		Receipts:                m.Receipts,
+		Message:                 m.Message,
+	})
+}
+
+// UnmarshalJSON decodes a message encoded by MarshalJSON
+func (m *TeleporterMessage) UnmarshalJSON(b []byte) error {
+	var dec teleporterMessageJSON
+	if err := unmarshalStrict(b, &dec); err != nil {
+		return err
+	}
+	if err := checkJSONVersion(dec.Version); err != nil {
+		return err
+	}
+	*m = TeleporterMessage{
+		MessageNonce:            dec.MessageNonce.Int,
+		OriginSenderAddress:     dec.OriginSenderAddress,
+		DestinationBlockchainID: dec.DestinationBlockchainID,
+		DestinationAddress:      dec.DestinationAddress,
+		RequiredGasLimit:        dec.RequiredGasLimit.Int,
+		AllowedRelayerAddresses: dec.AllowedRelayerAddresses,
+		Receipts:                dec.Receipts,
+		Message:                 dec.Message,
+	}
+	return nil
+}
+
+type teleporterMessageInputJSON struct {
+	Version                 *int              `json:"version"`
+	DestinationBlockchainID ids.ID            `json:"destinationBlockchainID"`
+	DestinationAddress      common.Address    `json:"destinationAddress"`
+	FeeInfo                 TeleporterFeeInfo `json:"feeInfo"`
+	RequiredGasLimit        jsonAmount        `json:"requiredGasLimit"`
+	AllowedRelayerAddresses []common.Address  `json:"allowedRelayerAddresses"`
+	Message                 hexutil.Bytes     `json:"message"`
+}
+
+// MarshalJSON encodes the message input with hex bytes, decimal string integers and a CB58
+// destination blockchain ID
+func (i TeleporterMessageInput) MarshalJSON() ([]byte, error) {
+	version := JSONVersion
+	return json.Marshal(teleporterMessageInputJSON{
+		Version:                 &version,
+		DestinationBlockchainID: i.DestinationBlockchainID,
+		DestinationAddress:      i.DestinationAddress,
+		FeeInfo:                 i.FeeInfo,
+		RequiredGasLimit:        jsonAmount{i.RequiredGasLimit},
+		AllowedRelayerAddresses: i.AllowedRelayerAddresses,
+		Message:                 i.Message,
+	})
+}
+
+// UnmarshalJSON decodes a message input encoded by MarshalJSON
+func (i *TeleporterMessageInput) UnmarshalJSON(b []byte) error {
+	var dec teleporterMessageInputJSON
+	if err := unmarshalStrict(b, &dec); err != nil {
+		return err
+	}
+	if err := checkJSONVersion(dec.Version); err != nil {
+		return err
+	}
+	*i = TeleporterMessageInput{
+		DestinationBlockchainID: dec.DestinationBlockchainID,
+		DestinationAddress:      dec.DestinationAddress,
+		FeeInfo:                 dec.FeeInfo,
+		RequiredGasLimit:        dec.RequiredGasLimit.Int,
+		AllowedRelayerAddresses: dec.AllowedRelayerAddresses,
+		Message:                 dec.Message,
+	}
+	return nil
+}
+
+// eventJSON holds the fields shared by the JSON encoding of every event. The raw log is only
+// encoded if it is set, as it is for events parsed from logs.
+type eventJSON struct {
+	Version *int       `json:"version"`
+	Event   string     `json:"event"`
+	Log     *types.Log `json:"log,omitempty"`
+}
+
+func newEventJSON(event Event, log types.Log) eventJSON {
+	version := JSONVersion
+	return eventJSON{Version: &version, Event: event.String(), Log: jsonLog(log)}
+}
+
+func (e eventJSON) check(expected Event) error {
+	if err := checkJSONVersion(e.Version); err != nil {
+		return err
+	}
+	return checkJSONEvent(e.Event, expected)
+}
+
+type sendCrossChainMessageJSON struct {
+	eventJSON
+	MessageID               common.Hash       `json:"messageID"`
+	DestinationBlockchainID ids.ID            `json:"destinationBlockchainID"`
+	Message                 TeleporterMessage `json:"message"`
+	FeeInfo                 TeleporterFeeInfo `json:"feeInfo"`
+}
+
+// MarshalJSON encodes the event along with its name and raw log
+func (t TeleporterMessengerSendCrossChainMessage) MarshalJSON() ([]byte, error) {
+	return json.Marshal(sendCrossChainMessageJSON{
+		eventJSON:               newEventJSON(SendCrossChainMessage, t.Raw),
+		MessageID:               t.MessageID,
+		DestinationBlockchainID: t.DestinationBlockchainID,
+		Message:                 t.Message,
+		FeeInfo:                 t.FeeInfo,
+	})
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON
+func (t *TeleporterMessengerSendCrossChainMessage) UnmarshalJSON(b []byte) error {
+	var dec sendCrossChainMessageJSON
+	if err := unmarshalStrict(b, &dec); err != nil {
+		return err
+	}
+	if err := dec.check(SendCrossChainMessage); err != nil {
+		return err
+	}
+	*t = TeleporterMessengerSendCrossChainMessage{
+		MessageID:               dec.MessageID,
+		DestinationBlockchainID: dec.DestinationBlockchainID,
+		Message:                 dec.Message,
+		FeeInfo:                 dec.FeeInfo,
+		Raw:                     fromJSONLog(dec.Log),
+	}
+	return nil
+}
+
+type receiveCrossChainMessageJSON struct {
+	eventJSON
+	MessageID          common.Hash       `json:"messageID"`
+	SourceBlockchainID ids.ID            `json:"sourceBlockchainID"`
+	Deliverer          common.Address    `json:"deliverer"`
+	RewardRedeemer     common.Address    `json:"rewardRedeemer"`
+	Message            TeleporterMessage `json:"message"`
+}
+
+// MarshalJSON encodes the event along with its name and raw log
+func (t TeleporterMessengerReceiveCrossChainMessage) MarshalJSON() ([]byte, error) {
+	return json.Marshal(receiveCrossChainMessageJSON{
+		eventJSON:          newEventJSON(ReceiveCrossChainMessage, t.Raw),
+		MessageID:          t.MessageID,
+		SourceBlockchainID: t.SourceBlockchainID,
+		Deliverer:          t.Deliverer,
+		RewardRedeemer:     t.RewardRedeemer,
+		Message:            t.Message,
+	})
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON
+func (t *TeleporterMessengerReceiveCrossChainMessage) UnmarshalJSON(b []byte) error {
+	var dec receiveCrossChainMessageJSON
+	if err := unmarshalStrict(b, &dec); err != nil {
+		return err
+	}
+	if err := dec.check(ReceiveCrossChainMessage); err != nil {
+		return err
+	}
+	*t = TeleporterMessengerReceiveCrossChainMessage{
+		MessageID:          dec.MessageID,
+		SourceBlockchainID: dec.SourceBlockchainID,
+		Deliverer:          dec.Deliverer,
+		RewardRedeemer:     dec.RewardRedeemer,
+		Message:            dec.Message,
+		Raw:                fromJSONLog(dec.Log),
+	}
+	return nil
+}
+
+type addFeeAmountJSON struct {
+	eventJSON
+	MessageID      common.Hash       `json:"messageID"`
+	UpdatedFeeInfo TeleporterFeeInfo `json:"updatedFeeInfo"`
+}
+
+// MarshalJSON encodes the event along with its name and raw log
+func (t TeleporterMessengerAddFeeAmount) MarshalJSON() ([]byte, error) {
+	return json.Marshal(addFeeAmountJSON{
+		eventJSON:      newEventJSON(AddFeeAmount, t.Raw),
+		MessageID:      t.MessageID,
+		UpdatedFeeInfo: t.UpdatedFeeInfo,
+	})
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON
+func (t *TeleporterMessengerAddFeeAmount) UnmarshalJSON(b []byte) error {
+	var dec addFeeAmountJSON
+	if err := unmarshalStrict(b, &dec); err != nil {
+		return err
+	}
+	if err := dec.check(AddFeeAmount); err != nil {
+		return err
+	}
+	*t = TeleporterMessengerAddFeeAmount{
+		MessageID:      dec.MessageID,
+		UpdatedFeeInfo: dec.UpdatedFeeInfo,
+		Raw:            fromJSONLog(dec.Log),
+	}
+	return nil
+}
+
+type messageExecutionFailedJSON struct {
+	eventJSON
+	MessageID          common.Hash       `json:"messageID"`
+	SourceBlockchainID ids.ID            `json:"sourceBlockchainID"`
+	Message            TeleporterMessage `json:"message"`
+}
+
+// MarshalJSON encodes the event along with its name and raw log
+func (t TeleporterMessengerMessageExecutionFailed) MarshalJSON() ([]byte, error) {
+	return json.Marshal(messageExecutionFailedJSON{
+		eventJSON:          newEventJSON(MessageExecutionFailed, t.Raw),
+		MessageID:          t.MessageID,
+		SourceBlockchainID: t.SourceBlockchainID,
+		Message:            t.Message,
+	})
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON
+func (t *TeleporterMessengerMessageExecutionFailed) UnmarshalJSON(b []byte) error {
+	var dec messageExecutionFailedJSON
+	if err := unmarshalStrict(b, &dec); err != nil {
+		return err
+	}
+	if err := dec.check(MessageExecutionFailed); err != nil {
+		return err
+	}
+	*t = TeleporterMessengerMessageExecutionFailed{
+		MessageID:          dec.MessageID,
+		SourceBlockchainID: dec.SourceBlockchainID,
+		Message:            dec.Message,
+		Raw:                fromJSONLog(dec.Log),
+	}
+	return nil
+}
+
+type messageExecutedJSON struct {
+	eventJSON
+	MessageID          common.Hash `json:"messageID"`
+	SourceBlockchainID ids.ID      `json:"sourceBlockchainID"`
+}
+
+// MarshalJSON encodes the event along with its name and raw log
+func (t TeleporterMessengerMessageExecuted) MarshalJSON() ([]byte, error) {
+	return json.Marshal(messageExecutedJSON{
+		eventJSON:          newEventJSON(MessageExecuted, t.Raw),
+		MessageID:          t.MessageID,
+		SourceBlockchainID: t.SourceBlockchainID,
+	})
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON
+func (t *TeleporterMessengerMessageExecuted) UnmarshalJSON(b []byte) error {
+	var dec messageExecutedJSON
+	if err := unmarshalStrict(b, &dec); err != nil {
+		return err
+	}
+	if err := dec.check(MessageExecuted); err != nil {
+		return err
+	}
+	*t = TeleporterMessengerMessageExecuted{
+		MessageID:          dec.MessageID,
+		SourceBlockchainID: dec.SourceBlockchainID,
+		Raw:                fromJSONLog(dec.Log),
+	}
+	return nil
+}
+
+type relayerRewardsRedeemedJSON struct {
+	eventJSON
+	Redeemer common.Address `json:"redeemer"`
+	Asset    common.Address `json:"asset"`
+	Amount   jsonAmount     `json:"amount"`
+}
+
+// MarshalJSON encodes the event along with its name and raw log
+func (t TeleporterMessengerRelayerRewardsRedeemed) MarshalJSON() ([]byte, error) {
+	return json.Marshal(relayerRewardsRedeemedJSON{
+		eventJSON: newEventJSON(RelayerRewardsRedeemed, t.Raw),
+		Redeemer:  t.Redeemer,
+		Asset:     t.Asset,
+		Amount:    jsonAmount{t.Amount},
+	})
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON
+func (t *TeleporterMessengerRelayerRewardsRedeemed) UnmarshalJSON(b []byte) error {
+	var dec relayerRewardsRedeemedJSON
+	if err := unmarshalStrict(b, &dec); err != nil {
+		return err
+	}
+	if err := dec.check(RelayerRewardsRedeemed); err != nil {
+		return err
+	}
+	*t = TeleporterMessengerRelayerRewardsRedeemed{
+		Redeemer: dec.Redeemer,
+		Asset:    dec.Asset,
+		Amount:   dec.Amount.Int,
+		Raw:      fromJSONLog(dec.Log),
+	}
+	return nil
+}
+
+type receiptReceivedJSON struct {
+	eventJSON
+	MessageID               common.Hash       `json:"messageID"`
+	DestinationBlockchainID ids.ID            `json:"destinationBlockchainID"`
+	RelayerRewardAddress    common.Address    `json:"relayerRewardAddress"`
+	FeeInfo                 TeleporterFeeInfo `json:"feeInfo"`
+}
+
+// MarshalJSON encodes the event along with its name and raw log
+func (t TeleporterMessengerReceiptReceived) MarshalJSON() ([]byte, error) {
+	return json.Marshal(receiptReceivedJSON{
+		eventJSON:               newEventJSON(ReceiptReceived, t.Raw),
+		MessageID:               t.MessageID,
+		DestinationBlockchainID: t.DestinationBlockchainID,
+		RelayerRewardAddress:    t.RelayerRewardAddress,
+		FeeInfo:                 t.FeeInfo,
+	})
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON
+func (t *TeleporterMessengerReceiptReceived) UnmarshalJSON(b []byte) error {
+	var dec receiptReceivedJSON
+	if err := unmarshalStrict(b, &dec); err != nil {
+		return err
+	}
+	if err := dec.check(ReceiptReceived); err != nil {
+		return err
+	}
+	*t = TeleporterMessengerReceiptReceived{
+		MessageID:               dec.MessageID,
+		DestinationBlockchainID: dec.DestinationBlockchainID,
+		RelayerRewardAddress:    dec.RelayerRewardAddress,
+		FeeInfo:                 dec.FeeInfo,
+		Raw:                     fromJSONLog(dec.Log),
+	}
+	return nil
+}
+
+type blockchainIDInitializedJSON struct {
+	eventJSON
+	BlockchainID ids.ID `json:"blockchainID"`
+}
+
+// MarshalJSON encodes the event along with its name and raw log
+func (t TeleporterMessengerBlockchainIDInitialized) MarshalJSON() ([]byte, error) {
+	return json.Marshal(blockchainIDInitializedJSON{
+		eventJSON:    newEventJSON(BlockchainIDInitialized, t.Raw),
+		BlockchainID: t.BlockchainID,
+	})
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON
+func (t *TeleporterMessengerBlockchainIDInitialized) UnmarshalJSON(b []byte) error {
+	var dec blockchainIDInitializedJSON
+	if err := unmarshalStrict(b, &dec); err != nil {
+		return err
+	}
+	if err := dec.check(BlockchainIDInitialized); err != nil {
+		return err
+	}
+	*t = TeleporterMessengerBlockchainIDInitialized{
+		BlockchainID: dec.BlockchainID,
+		Raw:          fromJSONLog(dec.Log),
+	}
+	return nil
+}
@@ -0,0 +1,246 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package teleportermessenger
+
+import (
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	blockchainID := ids.ID{1, 2, 3, 4}
+	messageID := ids.ID{9, 10, 11, 12}
+	address := common.HexToAddress("0x0123456789abcdef0123456789abcdef01234567")
+	// Amounts beyond the range of a uint64 or a float64 must survive the round trip.
+	amount, ok := new(big.Int).SetString("123456789012345678901234567890", 10)
+	require.True(t, ok)
+	feeInfo := TeleporterFeeInfo{FeeTokenAddress: address, Amount: amount}
+	message := createTestTeleporterMessage(big.NewInt(8))
+	log := types.Log{
+		Address:     address,
+		Topics:      []common.Hash{{1}, {2}},
+		Data:        []byte{3, 4},
+		BlockNumber: 5,
+		TxHash:      common.Hash{6},
+		TxIndex:     7,
+		BlockHash:   common.Hash{8},
+		Index:       9,
+	}
+
+	var tests = []struct {
+		name  string
+		value any
+	}{
+		{
+			name:  "fee info",
+			value: &feeInfo,
+		},
+		{
+			name:  "receipt",
+			value: &message.Receipts[0],
+		},
+		{
+			name:  "message",
+			value: &message,
+		},
+		{
+			name: "message input",
+			value: &TeleporterMessageInput{
+				DestinationBlockchainID: blockchainID,
+				DestinationAddress:      address,
+				FeeInfo:                 feeInfo,
+				RequiredGasLimit:        big.NewInt(100_000),
+				AllowedRelayerAddresses: []common.Address{address},
+				Message:                 []byte{1, 2, 3},
+			},
+		},
+		{
+			name: "SendCrossChainMessage",
+			value: &TeleporterMessengerSendCrossChainMessage{
+				MessageID:               messageID,
+				DestinationBlockchainID: blockchainID,
+				Message:                 message,
+				FeeInfo:                 feeInfo,
+				Raw:                     log,
+			},
+		},
+		{
+			name: "ReceiveCrossChainMessage",
+			value: &TeleporterMessengerReceiveCrossChainMessage{
+				MessageID:          messageID,
+				SourceBlockchainID: blockchainID,
+				Deliverer:          address,
+				RewardRedeemer:     address,
+				Message:            message,
+				Raw:                log,
+			},
+		},
+		{
+			name: "AddFeeAmount",
+			value: &TeleporterMessengerAddFeeAmount{
+				MessageID:      messageID,
+				UpdatedFeeInfo: feeInfo,
+				Raw:            log,
+			},
+		},
+		{
+			name: "MessageExecutionFailed",
+			value: &TeleporterMessengerMessageExecutionFailed{
+				MessageID:          messageID,
+				SourceBlockchainID: blockchainID,
+				Message:            message,
+				Raw:                log,
+			},
+		},
+		{
+			name: "MessageExecuted",
+			value: &TeleporterMessengerMessageExecuted{
+				MessageID:          messageID,
+				SourceBlockchainID: blockchainID,
+				Raw:                log,
+			},
+		},
+		{
+			name: "RelayerRewardsRedeemed",
+			value: &TeleporterMessengerRelayerRewardsRedeemed{
+				Redeemer: address,
+				Asset:    address,
+				Amount:   amount,
+				Raw:      log,
+			},
+		},
+		{
+			name: "ReceiptReceived",
+			value: &TeleporterMessengerReceiptReceived{
+				MessageID:               messageID,
+				DestinationBlockchainID: blockchainID,
+				RelayerRewardAddress:    address,
+				FeeInfo:                 feeInfo,
+				Raw:                     log,
+			},
+		},
+		{
+			name: "BlockchainIDInitialized without raw log",
+			value: &TeleporterMessengerBlockchainIDInitialized{
+				BlockchainID: blockchainID,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := json.Marshal(tt.value)
+			require.NoError(t, err)
+
+			decoded := reflect.New(reflect.TypeOf(tt.value).Elem()).Interface()
+			require.NoError(t, json.Unmarshal(b, decoded))
+			require.Equal(t, tt.value, decoded)
+
+			// Re-encoding must be stable, so that encoded values can be compared and hashed.
+			reencoded, err := json.Marshal(decoded)
+			require.NoError(t, err)
+			require.JSONEq(t, string(b), string(reencoded))
+		})
+	}
+}
+
+func TestJSONEncoding(t *testing.T) {
+	blockchainID := ids.ID{1, 2, 3, 4}
+	b, err := json.Marshal(TeleporterMessengerMessageExecuted{
+		MessageID:          common.Hash{31: 1},
+		SourceBlockchainID: blockchainID,
+	})
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"version": 1,
+		"event": "MessageExecuted",
+		"messageID": "0x0000000000000000000000000000000000000000000000000000000000000001",
+		"sourceBlockchainID": "`+blockchainID.String()+`"
+	}`, string(b))
+
+	b, err = json.Marshal(TeleporterFeeInfo{Amount: big.NewInt(1_000_000_000_000_000_000)})
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"feeTokenAddress": "0x0000000000000000000000000000000000000000",
+		"amount": "1000000000000000000"
+	}`, string(b))
+
+	b, err = json.Marshal(TeleporterMessageReceipt{ReceivedMessageNonce: big.NewInt(3)})
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"receivedMessageNonce": "3",
+		"relayerRewardAddress": "0x0000000000000000000000000000000000000000"
+	}`, string(b))
+
+	msg := createTestTeleporterMessage(big.NewInt(1))
+	b, err = json.Marshal(msg)
+	require.NoError(t, err)
+	var fields map[string]any
+	require.NoError(t, json.Unmarshal(b, &fields))
+	require.Equal(t, "0x"+common.Bytes2Hex(msg.Message), fields["message"])
+	require.Equal(t, ids.ID(msg.DestinationBlockchainID).String(), fields["destinationBlockchainID"])
+}
+
+func TestJSONDecodingErrors(t *testing.T) {
+	var tests = []struct {
+		name  string
+		input string
+		value any
+		err   error
+	}{
+		{
+			name:  "unsupported version",
+			input: `{"version": 2, "messageNonce": "1"}`,
+			value: &TeleporterMessage{},
+			err:   ErrUnsupportedJSONVersion,
+		},
+		{
+			name:  "event mismatch",
+			input: `{"version": 1, "event": "MessageExecutionFailed"}`,
+			value: &TeleporterMessengerMessageExecuted{},
+			err:   ErrJSONEventMismatch,
+		},
+		{
+			name:  "negative amount",
+			input: `{"amount": "-1"}`,
+			value: &TeleporterFeeInfo{},
+			err:   ErrInvalidJSONAmount,
+		},
+		{
+			name:  "amount overflows uint256",
+			input: `{"amount": "0x10000000000000000000000000000000000000000000000000000000000000000"}`,
+			value: &TeleporterFeeInfo{},
+			err:   ErrInvalidJSONAmount,
+		},
+		{
+			name:  "fractional amount",
+			input: `{"amount": 1.5}`,
+			value: &TeleporterFeeInfo{},
+			err:   ErrInvalidJSONAmount,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorIs(t, json.Unmarshal([]byte(tt.input), tt.value), tt.err)
+		})
+	}
+
+	// Unknown fields are rejected rather than silently dropped.
+	require.Error(t, json.Unmarshal([]byte(`{"amount": "1", "fee": "2"}`), &TeleporterFeeInfo{}))
+
+	// Plain numbers and hex strings are accepted for amounts, and a missing version is read as
+	// the current one.
+	var msg TeleporterMessage
+	require.NoError(t, json.Unmarshal([]byte(`{"messageNonce": 7, "requiredGasLimit": "0x10"}`), &msg))
+	require.Equal(t, big.NewInt(7), msg.MessageNonce)
+	require.Equal(t, big.NewInt(16), msg.RequiredGasLimit)
+}
@@ -0,0 +1,558 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package lifecycle tracks the lifecycle of Teleporter messages off-chain, from the decoded
+// TeleporterMessenger events emitted on their source and destination chains.
+package lifecycle
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/ava-labs/avalanchego/ids"
+	teleportermessenger "github.com/ava-labs/icm-contracts/abi-bindings/go/teleporter/TeleporterMessenger"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// State is a lifecycle state of a Teleporter message
+type State string
+
+const (
+	// StateSent is reached when the message is sent, or its sending is retried, on the source chain
+	StateSent State = "sent"
+	// StateFeeToppedUp is reached when the fee of the message is increased on the source chain
+	StateFeeToppedUp State = "fee-topped-up"
+	// StateDelivered is reached when the message is received on the destination chain
+	StateDelivered State = "delivered"
+	// StateExecuted is reached when the message is executed as it is received
+	StateExecuted State = "executed"
+	// StateFailed is reached when the execution of the message fails as it is received
+	StateFailed State = "failed"
+	// StateRetried is reached when the execution of a failed message is successfully retried
+	StateRetried State = "retried"
+	// StateReceipted is reached when the receipt of the message is received on the source chain
+	StateReceipted State = "receipted"
+	// StateRewarded is reached when the relayer redeems the rewards including the message's fee
+	StateRewarded State = "rewarded"
+)
+
+// stateRanks orders the states by how far along the lifecycle they are. A message's State is
+// the furthest state it has reached.
+var stateRanks = map[State]int{
+	StateSent:        1,
+	StateFeeToppedUp: 2,
+	StateDelivered:   3,
+	StateExecuted:    4,
+	StateFailed:      4,
+	StateRetried:     5,
+	StateReceipted:   6,
+	StateRewarded:    7,
+}
+
+var (
+	ErrImpossibleTransition = errors.New("impossible lifecycle transition")
+	ErrChainMismatch        = errors.New("event does not match the message's blockchains")
+	ErrZeroBlockchainID     = errors.New("zero blockchain ID")
+	ErrUnsupportedEvent     = errors.New("unsupported event")
+)
+
+// Transition is a state reached by a message, and the log of the event that reached it
+type Transition struct {
+	State        State
+	BlockchainID ids.ID
+	BlockNumber  uint64
+	TxHash       common.Hash
+	TxIndex      uint
+	LogIndex     uint
+	// OutOfOrder is set if the event was applied before the events that precede it in the
+	// lifecycle, such as a delivery applied before the message is sent.
+	OutOfOrder bool
+}
+
+// before reports whether t was emitted before o. Transitions on different chains are not ordered.
+func (t Transition) before(o Transition) bool {
+	if t.BlockNumber != o.BlockNumber {
+		return t.BlockNumber < o.BlockNumber
+	}
+	if t.TxIndex != o.TxIndex {
+		return t.TxIndex < o.TxIndex
+	}
+	return t.LogIndex < o.LogIndex
+}
+
+func (t Transition) sameLog(o Transition) bool {
+	return t.BlockchainID == o.BlockchainID && t.TxHash == o.TxHash && t.LogIndex == o.LogIndex
+}
+
+// Message is the lifecycle of a Teleporter message, as known from the events applied so far
+type Message struct {
+	MessageID               ids.ID
+	SourceBlockchainID      ids.ID
+	DestinationBlockchainID ids.ID
+	State                   State
+	// Message is the Teleporter message, once an event carrying it has been applied.
+	Message *teleportermessenger.TeleporterMessage
+	// FeeInfo is the fee info of the latest event carrying it on the source chain.
+	FeeInfo              teleportermessenger.TeleporterFeeInfo
+	RelayerRewardAddress common.Address
+	// OutOfOrder is set if any of the transitions was applied out of order.
+	OutOfOrder  bool
+	Transitions []Transition
+
+	feeTransition *Transition
+}
+
+// Reached reports whether the message has reached state, even if it has since moved past it
+func (m *Message) Reached(state State) bool {
+	return m.transition(state) != nil
+}
+
+// ExecutionFailed reports whether the message failed to execute and has not been retried
+// successfully yet. Receipts are returned whether or not execution succeeded, so a receipted
+// message may still be waiting for its execution to be retried.
+func (m *Message) ExecutionFailed() bool {
+	return m.Reached(StateFailed) && !m.Reached(StateRetried)
+}
+
+// transition returns the first transition to state, if any.
+func (m *Message) transition(state State) *Transition {
+	for i := range m.Transitions {
+		if m.Transitions[i].State == state {
+			return &m.Transitions[i]
+		}
+	}
+	return nil
+}
+
+func (m *Message) seen(t Transition) bool {
+	return slices.ContainsFunc(m.Transitions, t.sameLog)
+}
+
+func (m *Message) record(t Transition) {
+	m.Transitions = append(m.Transitions, t)
+	m.OutOfOrder = m.OutOfOrder || t.OutOfOrder
+	m.resolveState()
+}
+
+func (m *Message) resolveState() {
+	m.State = ""
+	for _, t := range m.Transitions {
+		if stateRanks[t.State] > stateRanks[m.State] {
+			m.State = t.State
+		}
+	}
+}
+
+// checkBlockchains checks that the event of t was emitted on a blockchain, and checks the source
+// and destination blockchain IDs of the event against those already set. Zero IDs are ignored.
+func (m *Message) checkBlockchains(t Transition, source ids.ID, destination ids.ID) error {
+	if t.BlockchainID == ids.Empty {
+		return fmt.Errorf("%w: message %s %s", ErrZeroBlockchainID, m.MessageID, t.State)
+	}
+	if source != ids.Empty && m.SourceBlockchainID != ids.Empty && m.SourceBlockchainID != source {
+		return fmt.Errorf("%w: source blockchain %s, expected %s", ErrChainMismatch, source, m.SourceBlockchainID)
+	}
+	if destination != ids.Empty && m.DestinationBlockchainID != ids.Empty && m.DestinationBlockchainID != destination {
+		return fmt.Errorf(
+			"%w: destination blockchain %s, expected %s",
+			ErrChainMismatch,
+			destination,
+			m.DestinationBlockchainID,
+		)
+	}
+	return nil
+}
+
+// setBlockchains sets the source and destination blockchain IDs of the message, once checked by
+// checkBlockchains. Zero IDs are ignored.
+func (m *Message) setBlockchains(source ids.ID, destination ids.ID) {
+	if source != ids.Empty {
+		m.SourceBlockchainID = source
+	}
+	if destination != ids.Empty {
+		m.DestinationBlockchainID = destination
+	}
+}
+
+// setFeeInfo sets the fee info of the message if t is the latest event carrying it.
+func (m *Message) setFeeInfo(t Transition, feeInfo teleportermessenger.TeleporterFeeInfo) {
+	if m.feeTransition == nil || m.feeTransition.before(t) {
+		m.FeeInfo = feeInfo
+		m.feeTransition = &t
+	}
+}
+
+// rewardable reports whether the relayer rewards redeemed by redemption include the message's fee,
+// which is only included in the first redemption on chain after the receipt.
+func (m *Message) rewardable(r redemption) bool {
+	receipt := m.transition(StateReceipted)
+	rewarded := m.transition(StateRewarded)
+	return receipt != nil &&
+		(rewarded == nil || r.transition.before(*rewarded)) &&
+		m.SourceBlockchainID == r.transition.BlockchainID &&
+		m.RelayerRewardAddress == r.redeemer &&
+		m.FeeInfo.FeeTokenAddress == r.asset &&
+		m.FeeInfo.Amount != nil && m.FeeInfo.Amount.Sign() > 0 &&
+		receipt.before(r.transition)
+}
+
+// reward records the redemption of the rewards including the message's fee, replacing a later
+// redemption recorded before it.
+func (m *Message) reward(t Transition) {
+	if rewarded := m.transition(StateRewarded); rewarded != nil {
+		*rewarded = t
+		m.OutOfOrder = m.OutOfOrder || t.OutOfOrder
+		return
+	}
+	m.record(t)
+}
+
+func (m *Message) copy() *Message {
+	c := *m
+	c.Transitions = slices.Clone(m.Transitions)
+	c.feeTransition = nil
+	return &c
+}
+
+// redemption is a RelayerRewardsRedeemed event, kept to reward receipts applied after it.
+type redemption struct {
+	redeemer   common.Address
+	asset      common.Address
+	transition Transition
+}
+
+// Tracker maintains the lifecycle of Teleporter messages from the TeleporterMessenger events of
+// any number of chains, keyed by message ID. Events may be applied in any order, such as when
+// each chain is indexed independently. Events applied before the events that precede them in
+// the lifecycle are flagged as out of order, and events that cannot follow the events already
+// applied, such as a message received twice, are rejected with ErrImpossibleTransition. Rejected
+// events leave the tracker unchanged. Events on the same chain are ordered by their position in
+// the chain. Applying the same log twice has no effect. A Tracker is safe for concurrent use.
+type Tracker struct {
+	lock        sync.Mutex
+	messages    map[ids.ID]*Message
+	redemptions []redemption
+}
+
+func NewTracker() *Tracker {
+	return &Tracker{messages: make(map[ids.ID]*Message)}
+}
+
+// Message returns a copy of the lifecycle of the message with the given ID, if any of its events
+// has been applied.
+func (t *Tracker) Message(messageID ids.ID) (*Message, bool) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	m, ok := t.messages[messageID]
+	if !ok {
+		return nil, false
+	}
+	return m.copy(), true
+}
+
+// Messages returns a copy of the lifecycle of every message, ordered by message ID.
+func (t *Tracker) Messages() []*Message {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	messages := make([]*Message, 0, len(t.messages))
+	for _, m := range t.messages {
+		messages = append(messages, m.copy())
+	}
+	slices.SortFunc(messages, func(a, b *Message) int {
+		return bytes.Compare(a.MessageID[:], b.MessageID[:])
+	})
+	return messages
+}
+
+// ApplyLog parses a TeleporterMessenger log emitted on the given blockchain and applies its event.
+// BlockchainIDInitialized logs are ignored, since they do not concern any message.
+func (t *Tracker) ApplyLog(blockchainID ids.ID, log types.Log) error {
+	event, out, err := teleportermessenger.ParseLog(log)
+	if err != nil {
+		return err
+	}
+	if event == teleportermessenger.BlockchainIDInitialized {
+		return nil
+	}
+	return t.Apply(blockchainID, out)
+}
+
+// Apply applies a TeleporterMessenger event emitted on the given blockchain, as returned by
+// teleportermessenger.ParseLog or the binding's filters. The event's Raw log is used to order
+// it against the other events of the same chain.
+func (t *Tracker) Apply(blockchainID ids.ID, event any) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	switch e := event.(type) {
+	case *teleportermessenger.TeleporterMessengerSendCrossChainMessage:
+		return t.applySend(blockchainID, e)
+	case *teleportermessenger.TeleporterMessengerAddFeeAmount:
+		return t.applyAddFeeAmount(blockchainID, e)
+	case *teleportermessenger.TeleporterMessengerReceiveCrossChainMessage:
+		return t.applyReceive(blockchainID, e)
+	case *teleportermessenger.TeleporterMessengerMessageExecutionFailed:
+		return t.applyExecutionFailed(blockchainID, e)
+	case *teleportermessenger.TeleporterMessengerMessageExecuted:
+		return t.applyExecuted(blockchainID, e)
+	case *teleportermessenger.TeleporterMessengerReceiptReceived:
+		return t.applyReceipt(blockchainID, e)
+	case *teleportermessenger.TeleporterMessengerRelayerRewardsRedeemed:
+		return t.applyRewardsRedeemed(blockchainID, e)
+	default:
+		return fmt.Errorf("%w: %T", ErrUnsupportedEvent, event)
+	}
+}
+
+// message returns the lifecycle of the message with the given ID. The lifecycle of a new message
+// is only tracked once a transition is recorded, so that a rejected event leaves no trace.
+func (t *Tracker) message(messageID ids.ID) *Message {
+	m, ok := t.messages[messageID]
+	if !ok {
+		m = &Message{MessageID: messageID}
+	}
+	return m
+}
+
+// record records the transition of an accepted event, tracking its message if it is new.
+func (t *Tracker) record(m *Message, tr Transition) {
+	t.messages[m.MessageID] = m
+	m.record(tr)
+}
+
+func newTransition(state State, blockchainID ids.ID, log types.Log) Transition {
+	return Transition{
+		State:        state,
+		BlockchainID: blockchainID,
+		BlockNumber:  log.BlockNumber,
+		TxHash:       log.TxHash,
+		TxIndex:      log.TxIndex,
+		LogIndex:     log.Index,
+	}
+}
+
+func impossible(m *Message, t Transition, reason string) error {
+	return fmt.Errorf(
+		"%w: message %s %s in state %s: %s",
+		ErrImpossibleTransition,
+		m.MessageID,
+		t.State,
+		m.State,
+		reason,
+	)
+}
+
+// checkBeforeReceipt rejects a source chain event emitted after the message's receipt, which
+// deletes the message's sent message info.
+func checkBeforeReceipt(m *Message, t Transition) error {
+	if receipt := m.transition(StateReceipted); receipt != nil && receipt.before(t) {
+		return impossible(m, t, "emitted after the receipt was received")
+	}
+	return nil
+}
+
+func (t *Tracker) applySend(
+	blockchainID ids.ID,
+	e *teleportermessenger.TeleporterMessengerSendCrossChainMessage,
+) error {
+	m := t.message(e.MessageID)
+	tr := newTransition(StateSent, blockchainID, e.Raw)
+	if m.seen(tr) {
+		return nil
+	}
+	if err := m.checkBlockchains(tr, blockchainID, e.DestinationBlockchainID); err != nil {
+		return err
+	}
+	if err := checkBeforeReceipt(m, tr); err != nil {
+		return err
+	}
+	message := e.Message
+	m.Message = &message
+	m.setFeeInfo(tr, e.FeeInfo)
+	m.setBlockchains(blockchainID, e.DestinationBlockchainID)
+	t.record(m, tr)
+	return nil
+}
+
+func (t *Tracker) applyAddFeeAmount(blockchainID ids.ID, e *teleportermessenger.TeleporterMessengerAddFeeAmount) error {
+	m := t.message(e.MessageID)
+	tr := newTransition(StateFeeToppedUp, blockchainID, e.Raw)
+	if m.seen(tr) {
+		return nil
+	}
+	if err := m.checkBlockchains(tr, blockchainID, ids.Empty); err != nil {
+		return err
+	}
+	if err := checkBeforeReceipt(m, tr); err != nil {
+		return err
+	}
+	tr.OutOfOrder = !m.Reached(StateSent)
+	m.setFeeInfo(tr, e.UpdatedFeeInfo)
+	m.setBlockchains(blockchainID, ids.Empty)
+	t.record(m, tr)
+	return nil
+}
+
+func (t *Tracker) applyReceive(
+	blockchainID ids.ID,
+	e *teleportermessenger.TeleporterMessengerReceiveCrossChainMessage,
+) error {
+	m := t.message(e.MessageID)
+	tr := newTransition(StateDelivered, blockchainID, e.Raw)
+	if m.seen(tr) {
+		return nil
+	}
+	if err := m.checkBlockchains(tr, e.SourceBlockchainID, blockchainID); err != nil {
+		return err
+	}
+	if m.Reached(StateDelivered) {
+		return impossible(m, tr, "message already received")
+	}
+	for _, state := range []State{StateExecuted, StateFailed, StateRetried} {
+		if execution := m.transition(state); execution != nil && execution.before(tr) {
+			return impossible(m, tr, "received after its execution")
+		}
+	}
+	tr.OutOfOrder = !m.Reached(StateSent)
+	if m.Message == nil {
+		message := e.Message
+		m.Message = &message
+	}
+	if m.RelayerRewardAddress == (common.Address{}) {
+		m.RelayerRewardAddress = e.RewardRedeemer
+	}
+	m.setBlockchains(e.SourceBlockchainID, blockchainID)
+	t.record(m, tr)
+	return nil
+}
+
+func (t *Tracker) applyExecutionFailed(
+	blockchainID ids.ID,
+	e *teleportermessenger.TeleporterMessengerMessageExecutionFailed,
+) error {
+	m := t.message(e.MessageID)
+	tr := newTransition(StateFailed, blockchainID, e.Raw)
+	if m.seen(tr) {
+		return nil
+	}
+	if err := m.checkBlockchains(tr, e.SourceBlockchainID, blockchainID); err != nil {
+		return err
+	}
+	if m.Reached(StateFailed) {
+		return impossible(m, tr, "execution already failed")
+	}
+	if executed := m.transition(StateExecuted); executed != nil {
+		if executed.before(tr) {
+			return impossible(m, tr, "message already executed")
+		}
+		// The execution applied earlier was a successful retry of this failed execution.
+		executed.State = StateRetried
+		executed.OutOfOrder = true
+	}
+	tr.OutOfOrder = !m.Reached(StateDelivered)
+	if m.Message == nil {
+		message := e.Message
+		m.Message = &message
+	}
+	m.setBlockchains(e.SourceBlockchainID, blockchainID)
+	t.record(m, tr)
+	return nil
+}
+
+func (t *Tracker) applyExecuted(blockchainID ids.ID, e *teleportermessenger.TeleporterMessengerMessageExecuted) error {
+	m := t.message(e.MessageID)
+	tr := newTransition(StateExecuted, blockchainID, e.Raw)
+	if m.seen(tr) {
+		return nil
+	}
+	if err := m.checkBlockchains(tr, e.SourceBlockchainID, blockchainID); err != nil {
+		return err
+	}
+	if m.Reached(StateExecuted) || m.Reached(StateRetried) {
+		return impossible(m, tr, "message already executed")
+	}
+	if failed := m.transition(StateFailed); failed != nil {
+		if tr.before(*failed) {
+			return impossible(m, tr, "executed before its execution failed")
+		}
+		tr.State = StateRetried
+	}
+	tr.OutOfOrder = !m.Reached(StateDelivered)
+	m.setBlockchains(e.SourceBlockchainID, blockchainID)
+	t.record(m, tr)
+	return nil
+}
+
+func (t *Tracker) applyReceipt(blockchainID ids.ID, e *teleportermessenger.TeleporterMessengerReceiptReceived) error {
+	m := t.message(e.MessageID)
+	tr := newTransition(StateReceipted, blockchainID, e.Raw)
+	if m.seen(tr) {
+		return nil
+	}
+	if err := m.checkBlockchains(tr, blockchainID, e.DestinationBlockchainID); err != nil {
+		return err
+	}
+	if m.Reached(StateReceipted) {
+		return impossible(m, tr, "receipt already received")
+	}
+	for _, state := range []State{StateSent, StateFeeToppedUp} {
+		for _, source := range m.Transitions {
+			if source.State == state && tr.before(source) {
+				return impossible(m, tr, fmt.Sprintf("received before the message was %s", state))
+			}
+		}
+	}
+	tr.OutOfOrder = !m.Reached(StateDelivered)
+	m.RelayerRewardAddress = e.RelayerRewardAddress
+	m.setFeeInfo(tr, e.FeeInfo)
+	m.setBlockchains(blockchainID, e.DestinationBlockchainID)
+	t.record(m, tr)
+
+	// Rewards redeemed after the receipt include its fee, even if they were applied first. The fee
+	// is included in the first of them on chain, whatever the order they were applied in.
+	var rewarded *Transition
+	for i := range t.redemptions {
+		if r := &t.redemptions[i]; m.rewardable(*r) && (rewarded == nil || r.transition.before(*rewarded)) {
+			rewarded = &r.transition
+		}
+	}
+	if rewarded != nil {
+		reward := *rewarded
+		reward.OutOfOrder = true
+		m.record(reward)
+	}
+	return nil
+}
+
+// applyRewardsRedeemed marks the receipted messages whose fees are included in the redeemed
+// rewards as rewarded. Rewards accrue per relayer reward address and fee token, so a redemption
+// includes the fees of every receipt received before it for the same address and token, that an
+// earlier redemption does not include.
+func (t *Tracker) applyRewardsRedeemed(
+	blockchainID ids.ID,
+	e *teleportermessenger.TeleporterMessengerRelayerRewardsRedeemed,
+) error {
+	r := redemption{
+		redeemer:   e.Redeemer,
+		asset:      e.Asset,
+		transition: newTransition(StateRewarded, blockchainID, e.Raw),
+	}
+	for _, applied := range t.redemptions {
+		if applied.transition.sameLog(r.transition) {
+			return nil
+		}
+	}
+	t.redemptions = append(t.redemptions, r)
+	for _, m := range t.messages {
+		if m.rewardable(r) {
+			m.reward(r.transition)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,360 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package lifecycle
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	teleportermessenger "github.com/ava-labs/icm-contracts/abi-bindings/go/teleporter/TeleporterMessenger"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	sourceID      = ids.ID{1}
+	destinationID = ids.ID{2}
+	otherID       = ids.ID{3}
+	messageID     = ids.ID{4}
+	relayer       = common.HexToAddress("0x0123456789abcdef0123456789abcdef01234567")
+	feeToken      = common.HexToAddress("0x89abcdef0123456789abcdef0123456789abcdef")
+)
+
+// step is an event emitted on a blockchain, at the given block.
+type step struct {
+	blockchainID ids.ID
+	block        uint64
+	event        any
+}
+
+// raw returns a log at the given block. Each kind of event has its own log index, so that
+// events emitted in the same block are distinct logs.
+func raw(block uint64, index uint) types.Log {
+	return types.Log{BlockNumber: block, TxHash: common.Hash{byte(block)}, Index: index}
+}
+
+func feeInfo(amount int64) teleportermessenger.TeleporterFeeInfo {
+	return teleportermessenger.TeleporterFeeInfo{FeeTokenAddress: feeToken, Amount: big.NewInt(amount)}
+}
+
+func sent(block uint64) step {
+	return step{sourceID, block, &teleportermessenger.TeleporterMessengerSendCrossChainMessage{
+		MessageID:               messageID,
+		DestinationBlockchainID: destinationID,
+		FeeInfo:                 feeInfo(1),
+		Raw:                     raw(block, 0),
+	}}
+}
+
+func feeAdded(block uint64) step {
+	return step{sourceID, block, &teleportermessenger.TeleporterMessengerAddFeeAmount{
+		MessageID:      messageID,
+		UpdatedFeeInfo: feeInfo(2),
+		Raw:            raw(block, 1),
+	}}
+}
+
+func received(block uint64) step {
+	return step{destinationID, block, &teleportermessenger.TeleporterMessengerReceiveCrossChainMessage{
+		MessageID:          messageID,
+		SourceBlockchainID: sourceID,
+		RewardRedeemer:     relayer,
+		Raw:                raw(block, 0),
+	}}
+}
+
+func executed(block uint64) step {
+	return step{destinationID, block, &teleportermessenger.TeleporterMessengerMessageExecuted{
+		MessageID:          messageID,
+		SourceBlockchainID: sourceID,
+		Raw:                raw(block, 2),
+	}}
+}
+
+func failed(block uint64) step {
+	return step{destinationID, block, &teleportermessenger.TeleporterMessengerMessageExecutionFailed{
+		MessageID:          messageID,
+		SourceBlockchainID: sourceID,
+		Raw:                raw(block, 1),
+	}}
+}
+
+func receipted(block uint64) step {
+	return step{sourceID, block, &teleportermessenger.TeleporterMessengerReceiptReceived{
+		MessageID:               messageID,
+		DestinationBlockchainID: destinationID,
+		RelayerRewardAddress:    relayer,
+		FeeInfo:                 feeInfo(2),
+		Raw:                     raw(block, 2),
+	}}
+}
+
+func redeemed(block uint64) step {
+	return step{sourceID, block, &teleportermessenger.TeleporterMessengerRelayerRewardsRedeemed{
+		Redeemer: relayer,
+		Asset:    feeToken,
+		Amount:   big.NewInt(2),
+		Raw:      raw(block, 3),
+	}}
+}
+
+func on(blockchainID ids.ID, s step) step {
+	s.blockchainID = blockchainID
+	return s
+}
+
+func TestTracker(t *testing.T) {
+	var tests = []struct {
+		name        string
+		steps       []step
+		state       State
+		transitions []State
+		outOfOrder  bool
+		failed      bool
+		// err is the error expected from the last step, which must leave the message unchanged.
+		err error
+	}{
+		{
+			name:        "sent",
+			steps:       []step{sent(1)},
+			state:       StateSent,
+			transitions: []State{StateSent},
+		},
+		{
+			name:        "fee topped up",
+			steps:       []step{sent(1), feeAdded(2)},
+			state:       StateFeeToppedUp,
+			transitions: []State{StateSent, StateFeeToppedUp},
+		},
+		{
+			name:        "executed",
+			steps:       []step{sent(1), received(1), executed(1)},
+			state:       StateExecuted,
+			transitions: []State{StateSent, StateDelivered, StateExecuted},
+		},
+		{
+			name:        "failed",
+			steps:       []step{sent(1), received(1), failed(1)},
+			state:       StateFailed,
+			transitions: []State{StateSent, StateDelivered, StateFailed},
+			failed:      true,
+		},
+		{
+			name:        "retried",
+			steps:       []step{sent(1), received(1), failed(1), executed(2)},
+			state:       StateRetried,
+			transitions: []State{StateSent, StateDelivered, StateFailed, StateRetried},
+		},
+		{
+			name:  "rewarded",
+			steps: []step{sent(1), feeAdded(2), received(1), executed(1), receipted(3), redeemed(4)},
+			state: StateRewarded,
+			transitions: []State{
+				StateSent, StateFeeToppedUp, StateDelivered, StateExecuted, StateReceipted, StateRewarded,
+			},
+		},
+		{
+			name:        "receipted with execution pending retry",
+			steps:       []step{sent(1), received(1), failed(1), receipted(2)},
+			state:       StateReceipted,
+			transitions: []State{StateSent, StateDelivered, StateFailed, StateReceipted},
+			failed:      true,
+		},
+		{
+			name:        "send retried",
+			steps:       []step{sent(1), sent(2)},
+			state:       StateSent,
+			transitions: []State{StateSent, StateSent},
+		},
+		{
+			name:        "duplicate log",
+			steps:       []step{sent(1), received(1), received(1)},
+			state:       StateDelivered,
+			transitions: []State{StateSent, StateDelivered},
+		},
+		{
+			name:        "delivered before sent",
+			steps:       []step{received(1), sent(1)},
+			state:       StateDelivered,
+			transitions: []State{StateDelivered, StateSent},
+			outOfOrder:  true,
+		},
+		{
+			name:        "fee topped up before sent",
+			steps:       []step{feeAdded(2), sent(1)},
+			state:       StateFeeToppedUp,
+			transitions: []State{StateFeeToppedUp, StateSent},
+			outOfOrder:  true,
+		},
+		{
+			name:        "receipted before delivered",
+			steps:       []step{sent(1), receipted(3), received(1)},
+			state:       StateReceipted,
+			transitions: []State{StateSent, StateReceipted, StateDelivered},
+			outOfOrder:  true,
+		},
+		{
+			name:        "retry applied before failure",
+			steps:       []step{received(1), executed(2), failed(1)},
+			state:       StateRetried,
+			transitions: []State{StateDelivered, StateRetried, StateFailed},
+			outOfOrder:  true,
+		},
+		{
+			name:        "redeemed before receipted",
+			steps:       []step{sent(1), received(1), redeemed(4), receipted(3)},
+			state:       StateRewarded,
+			transitions: []State{StateSent, StateDelivered, StateReceipted, StateRewarded},
+			outOfOrder:  true,
+		},
+		{
+			name:        "redeemed before the receipt was received",
+			steps:       []step{sent(1), received(1), redeemed(2), receipted(3)},
+			state:       StateReceipted,
+			transitions: []State{StateSent, StateDelivered, StateReceipted},
+		},
+		{
+			name:        "double receive",
+			steps:       []step{sent(1), received(1), received(2)},
+			state:       StateDelivered,
+			transitions: []State{StateSent, StateDelivered},
+			err:         ErrImpossibleTransition,
+		},
+		{
+			name:        "double execution",
+			steps:       []step{sent(1), received(1), executed(1), executed(2)},
+			state:       StateExecuted,
+			transitions: []State{StateSent, StateDelivered, StateExecuted},
+			err:         ErrImpossibleTransition,
+		},
+		{
+			name:        "failed after executed",
+			steps:       []step{sent(1), received(1), executed(1), failed(2)},
+			state:       StateExecuted,
+			transitions: []State{StateSent, StateDelivered, StateExecuted},
+			err:         ErrImpossibleTransition,
+		},
+		{
+			name:        "double failure",
+			steps:       []step{sent(1), received(1), failed(1), failed(2)},
+			state:       StateFailed,
+			transitions: []State{StateSent, StateDelivered, StateFailed},
+			failed:      true,
+			err:         ErrImpossibleTransition,
+		},
+		{
+			name:        "double retry",
+			steps:       []step{sent(1), received(1), failed(1), executed(2), executed(3)},
+			state:       StateRetried,
+			transitions: []State{StateSent, StateDelivered, StateFailed, StateRetried},
+			err:         ErrImpossibleTransition,
+		},
+		{
+			name:        "fee topped up after receipt",
+			steps:       []step{sent(1), received(1), receipted(2), feeAdded(3)},
+			state:       StateReceipted,
+			transitions: []State{StateSent, StateDelivered, StateReceipted},
+			err:         ErrImpossibleTransition,
+		},
+		{
+			name:        "double receipt",
+			steps:       []step{sent(1), received(1), receipted(2), receipted(3)},
+			state:       StateReceipted,
+			transitions: []State{StateSent, StateDelivered, StateReceipted},
+			err:         ErrImpossibleTransition,
+		},
+		{
+			name:        "delivered on another destination",
+			steps:       []step{sent(1), on(otherID, received(1))},
+			state:       StateSent,
+			transitions: []State{StateSent},
+			err:         ErrChainMismatch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := NewTracker()
+			for i, s := range tt.steps {
+				err := tracker.Apply(s.blockchainID, s.event)
+				if i == len(tt.steps)-1 && tt.err != nil {
+					require.ErrorIs(t, err, tt.err)
+				} else {
+					require.NoError(t, err)
+				}
+			}
+
+			m, ok := tracker.Message(messageID)
+			require.True(t, ok)
+			require.Equal(t, tt.state, m.State)
+			states := make([]State, 0, len(m.Transitions))
+			for _, tr := range m.Transitions {
+				states = append(states, tr.State)
+			}
+			require.Equal(t, tt.transitions, states)
+			require.Equal(t, tt.outOfOrder, m.OutOfOrder)
+			require.Equal(t, tt.failed, m.ExecutionFailed())
+			require.Equal(t, sourceID, m.SourceBlockchainID)
+			require.Equal(t, destinationID, m.DestinationBlockchainID)
+		})
+	}
+}
+
+func TestTrackerFeeInfo(t *testing.T) {
+	tracker := NewTracker()
+	// The latest fee info wins, whatever the order the events are applied in.
+	for _, s := range []step{feeAdded(2), sent(1)} {
+		require.NoError(t, tracker.Apply(s.blockchainID, s.event))
+	}
+	m, ok := tracker.Message(messageID)
+	require.True(t, ok)
+	require.Equal(t, feeInfo(2), m.FeeInfo)
+
+	// Returned messages are copies.
+	m.Transitions[0].State = StateRewarded
+	m, _ = tracker.Message(messageID)
+	require.Equal(t, StateFeeToppedUp, m.Transitions[0].State)
+}
+
+func TestTrackerUnsupportedEvent(t *testing.T) {
+	tracker := NewTracker()
+	err := tracker.Apply(sourceID, &teleportermessenger.TeleporterMessengerBlockchainIDInitialized{})
+	require.ErrorIs(t, err, ErrUnsupportedEvent)
+	require.Empty(t, tracker.Messages())
+}
+
+func TestTrackerRejectedFirstEvent(t *testing.T) {
+	tracker := NewTracker()
+	s := sent(1)
+	err := tracker.Apply(ids.Empty, s.event)
+	require.ErrorIs(t, err, ErrZeroBlockchainID)
+	_, ok := tracker.Message(messageID)
+	require.False(t, ok)
+	require.Empty(t, tracker.Messages())
+}
+
+func TestTrackerEarliestRedemption(t *testing.T) {
+	// The fee is included in the first redemption after the receipt on chain, whatever the order
+	// the redemptions and the receipt are applied in.
+	for _, steps := range [][]step{
+		{sent(1), received(1), receipted(3), redeemed(5), redeemed(4)},
+		{sent(1), received(1), receipted(3), redeemed(4), redeemed(5)},
+		{sent(1), received(1), redeemed(5), redeemed(4), receipted(3)},
+		{sent(1), received(1), redeemed(4), redeemed(5), receipted(3)},
+	} {
+		tracker := NewTracker()
+		for _, s := range steps {
+			require.NoError(t, tracker.Apply(s.blockchainID, s.event))
+		}
+		m, ok := tracker.Message(messageID)
+		require.True(t, ok)
+		require.Equal(t, StateRewarded, m.State)
+		rewarded := m.transition(StateRewarded)
+		require.NotNil(t, rewarded)
+		require.Equal(t, uint64(4), rewarded.BlockNumber)
+		require.Len(t, m.Transitions, 4)
+	}
+}
@@ -0,0 +1,560 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package model is a deterministic, pure Go reference model of the TeleporterMessenger contract
+// and its ReceiptQueue, for simulating Teleporter off-chain. It models message nonces and IDs,
+// fee escrow, receipt batching, relayer rewards and failed message execution and retries, but
+// not gas, so relayers are assumed to provide the gas required to deliver messages, and fee
+// tokens are assumed to transfer the exact amounts requested.
+package model
+
+import (
+	"math/big"
+	"slices"
+
+	"github.com/ava-labs/avalanchego/ids"
+	teleportermessenger "github.com/ava-labs/icm-contracts/abi-bindings/go/teleporter/TeleporterMessenger"
+	teleporterutils "github.com/ava-labs/icm-contracts/utils/teleporter-utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ExecutionResult is the outcome of calling receiveTeleporterMessage on a message's destination
+type ExecutionResult int
+
+const (
+	ExecutionSucceeded ExecutionResult = iota
+	ExecutionFailed
+	// ExecutionNoCode is the outcome for a destination address without code, which fails the
+	// initial execution and reverts a retry.
+	ExecutionNoCode
+)
+
+// Executor executes a message on its destination address. Retried executions are given all of the
+// transaction's gas, rather than the message's required gas limit.
+type Executor func(
+	sourceBlockchainID ids.ID,
+	message *teleportermessenger.TeleporterMessage,
+	retry bool,
+) ExecutionResult
+
+// WarpMessage is a verified Warp message delivered to receiveCrossChainMessage
+type WarpMessage struct {
+	SourceBlockchainID  ids.ID
+	OriginSenderAddress common.Address
+	Message             teleportermessenger.TeleporterMessage
+}
+
+type sentMessageInfo struct {
+	messageHash common.Hash
+	feeInfo     teleportermessenger.TeleporterFeeInfo
+}
+
+// Messenger models a TeleporterMessenger contract deployed at an address on a blockchain. Each
+// operation either applies all of its effects and returns the events the contract emits, as
+// the bindings' event types without their Raw log, or returns the error matching the contract's
+// revert reason and has no effect.
+type Messenger struct {
+	// Executor executes received messages. If nil, every execution succeeds.
+	Executor Executor
+
+	address          common.Address
+	warpBlockchainID ids.ID
+	blockchainID     ids.ID
+	messageNonce     *big.Int
+
+	receiptQueues               map[ids.ID]*ReceiptQueue
+	sentMessageInfo             map[ids.ID]sentMessageInfo
+	receivedFailedMessageHashes map[ids.ID]common.Hash
+	receivedMessageNonces       map[ids.ID]*big.Int
+	relayerRewardAddresses      map[ids.ID]common.Address
+	relayerRewardAmounts        map[common.Address]map[common.Address]*big.Int
+	escrow                      map[common.Address]*big.Int
+}
+
+// NewMessenger returns the model of a TeleporterMessenger deployed at address on the blockchain
+// with the given ID, as returned by the Warp precompile.
+func NewMessenger(address common.Address, blockchainID ids.ID) *Messenger {
+	return &Messenger{
+		address:                     address,
+		warpBlockchainID:            blockchainID,
+		messageNonce:                new(big.Int),
+		receiptQueues:               make(map[ids.ID]*ReceiptQueue),
+		sentMessageInfo:             make(map[ids.ID]sentMessageInfo),
+		receivedFailedMessageHashes: make(map[ids.ID]common.Hash),
+		receivedMessageNonces:       make(map[ids.ID]*big.Int),
+		relayerRewardAddresses:      make(map[ids.ID]common.Address),
+		relayerRewardAmounts:        make(map[common.Address]map[common.Address]*big.Int),
+		escrow:                      make(map[common.Address]*big.Int),
+	}
+}
+
+func (m *Messenger) Address() common.Address {
+	return m.address
+}
+
+// BlockchainID returns the blockchain ID stored by the contract, which is empty until the first
+// message is sent or received.
+func (m *Messenger) BlockchainID() ids.ID {
+	return m.blockchainID
+}
+
+func (m *Messenger) MessageNonce() *big.Int {
+	return new(big.Int).Set(m.messageNonce)
+}
+
+func (m *Messenger) CalculateMessageID(
+	sourceBlockchainID ids.ID,
+	destinationBlockchainID ids.ID,
+	nonce *big.Int,
+) (ids.ID, error) {
+	return teleporterutils.CalculateMessageID(m.address, sourceBlockchainID, destinationBlockchainID, nonce)
+}
+
+func (m *Messenger) GetNextMessageID(destinationBlockchainID ids.ID) (ids.ID, error) {
+	if m.blockchainID == ids.Empty {
+		return ids.Empty, teleportermessenger.ErrZeroBlockchainID
+	}
+	return m.CalculateMessageID(m.blockchainID, destinationBlockchainID, new(big.Int).Add(m.messageNonce, common.Big1))
+}
+
+func (m *Messenger) GetFeeInfo(messageID ids.ID) teleportermessenger.TeleporterFeeInfo {
+	return copyFeeInfo(m.sentMessageInfo[messageID].feeInfo)
+}
+
+func (m *Messenger) GetMessageHash(messageID ids.ID) common.Hash {
+	return m.sentMessageInfo[messageID].messageHash
+}
+
+func (m *Messenger) ReceivedFailedMessageHash(messageID ids.ID) common.Hash {
+	return m.receivedFailedMessageHashes[messageID]
+}
+
+func (m *Messenger) MessageReceived(messageID ids.ID) bool {
+	return m.receivedMessageNonces[messageID] != nil
+}
+
+func (m *Messenger) GetRelayerRewardAddress(messageID ids.ID) (common.Address, error) {
+	if !m.MessageReceived(messageID) {
+		return common.Address{}, teleportermessenger.ErrMessageNotReceived
+	}
+	return m.relayerRewardAddresses[messageID], nil
+}
+
+func (m *Messenger) CheckRelayerRewardAmount(relayer common.Address, feeAsset common.Address) *big.Int {
+	if amount := m.relayerRewardAmounts[relayer][feeAsset]; amount != nil {
+		return new(big.Int).Set(amount)
+	}
+	return new(big.Int)
+}
+
+func (m *Messenger) GetReceiptQueueSize(sourceBlockchainID ids.ID) uint64 {
+	return m.receiptQueue(sourceBlockchainID).Size()
+}
+
+func (m *Messenger) GetReceiptAtIndex(
+	sourceBlockchainID ids.ID,
+	index uint64,
+) (teleportermessenger.TeleporterMessageReceipt, error) {
+	return m.receiptQueue(sourceBlockchainID).GetReceiptAtIndex(index)
+}
+
+// Escrow returns the amount of the fee token held by the contract, for fees not yet redeemed.
+func (m *Messenger) Escrow(feeToken common.Address) *big.Int {
+	if amount := m.escrow[feeToken]; amount != nil {
+		return new(big.Int).Set(amount)
+	}
+	return new(big.Int)
+}
+
+// SendCrossChainMessage models sendCrossChainMessage called by sender, attaching the outstanding
+// receipts of the messages received from the destination blockchain.
+func (m *Messenger) SendCrossChainMessage(
+	sender common.Address,
+	input teleportermessenger.TeleporterMessageInput,
+) (ids.ID, []any, error) {
+	if err := checkFeeInfo(input.FeeInfo); err != nil {
+		return ids.Empty, nil, err
+	}
+	// The outstanding receipts are only dequeued once the message is sent.
+	queue := m.receiptQueue(input.DestinationBlockchainID)
+	receipts := make([]teleportermessenger.TeleporterMessageReceipt, queue.outstandingReceiptCount())
+	for i := range receipts {
+		receipts[i], _ = queue.GetReceiptAtIndex(uint64(i))
+	}
+	messageID, events, err := m.sendTeleporterMessage(sender, input, receipts)
+	if err != nil {
+		return ids.Empty, nil, err
+	}
+	queue.GetOutstandingReceiptsToSend()
+	return messageID, events, nil
+}
+
+// RetrySendCrossChainMessage models retrySendCrossChainMessage, re-emitting a message that has not
+// been acknowledged with a receipt yet.
+func (m *Messenger) RetrySendCrossChainMessage(message teleportermessenger.TeleporterMessage) ([]any, error) {
+	messageID, err := m.CalculateMessageID(m.blockchainID, message.DestinationBlockchainID, message.MessageNonce)
+	if err != nil {
+		return nil, err
+	}
+	info, ok := m.sentMessageInfo[messageID]
+	if !ok {
+		return nil, teleportermessenger.ErrMessageNotFound
+	}
+	messageHash, err := hashMessage(&message)
+	if err != nil {
+		return nil, err
+	}
+	if messageHash != info.messageHash {
+		return nil, teleportermessenger.ErrInvalidMessageHash
+	}
+	return []any{&teleportermessenger.TeleporterMessengerSendCrossChainMessage{
+		MessageID:               messageID,
+		DestinationBlockchainID: message.DestinationBlockchainID,
+		Message:                 message,
+		FeeInfo:                 copyFeeInfo(info.feeInfo),
+	}}, nil
+}
+
+// AddFeeAmount models addFeeAmount, escrowing an additional fee for a message that has not been
+// acknowledged with a receipt yet.
+func (m *Messenger) AddFeeAmount(
+	messageID ids.ID,
+	feeTokenAddress common.Address,
+	additionalFeeAmount *big.Int,
+) ([]any, error) {
+	if additionalFeeAmount == nil || additionalFeeAmount.Sign() == 0 {
+		return nil, teleportermessenger.ErrZeroAdditionalFeeAmount
+	}
+	if feeTokenAddress == (common.Address{}) {
+		return nil, teleportermessenger.ErrZeroFeeAssetContractAddress
+	}
+	info, ok := m.sentMessageInfo[messageID]
+	if !ok {
+		return nil, teleportermessenger.ErrMessageNotFound
+	}
+	if info.feeInfo.FeeTokenAddress != feeTokenAddress {
+		return nil, teleportermessenger.ErrInvalidFeeAssetAddress
+	}
+
+	m.addEscrow(feeTokenAddress, additionalFeeAmount)
+	info.feeInfo.Amount = new(big.Int).Add(info.feeInfo.Amount, additionalFeeAmount)
+	m.sentMessageInfo[messageID] = info
+	return []any{&teleportermessenger.TeleporterMessengerAddFeeAmount{
+		MessageID:      messageID,
+		UpdatedFeeInfo: copyFeeInfo(info.feeInfo),
+	}}, nil
+}
+
+// ReceiveCrossChainMessage models receiveCrossChainMessage called by deliverer with a verified
+// Warp message: the receipts it carries are processed, its own receipt is queued, and it is
+// executed if it has a payload.
+func (m *Messenger) ReceiveCrossChainMessage(
+	deliverer common.Address,
+	relayerRewardAddress common.Address,
+	warpMessage WarpMessage,
+) ([]any, error) {
+	if warpMessage.OriginSenderAddress != m.address {
+		return nil, teleportermessenger.ErrInvalidOriginSenderAddress
+	}
+	message := warpMessage.Message
+	if ids.ID(message.DestinationBlockchainID) != m.warpBlockchainID {
+		return nil, teleportermessenger.ErrInvalidDestinationChainID
+	}
+	messageID, err := m.CalculateMessageID(warpMessage.SourceBlockchainID, m.warpBlockchainID, message.MessageNonce)
+	if err != nil {
+		return nil, err
+	}
+	if m.MessageReceived(messageID) {
+		return nil, teleportermessenger.ErrMessageAlreadyReceived
+	}
+	if len(message.AllowedRelayerAddresses) > 0 && !slices.Contains(message.AllowedRelayerAddresses, deliverer) {
+		return nil, teleportermessenger.ErrUnauthorizedRelayer
+	}
+	if message.MessageNonce.Sign() == 0 {
+		return nil, teleportermessenger.ErrZeroMessageNonce
+	}
+	var messageHash common.Hash
+	if len(message.Message) > 0 {
+		// Hash before any effect, so that a message that cannot be encoded has none.
+		if messageHash, err = hashMessage(&message); err != nil {
+			return nil, err
+		}
+	}
+	for _, receipt := range message.Receipts {
+		_, err := m.CalculateMessageID(m.warpBlockchainID, warpMessage.SourceBlockchainID, receipt.ReceivedMessageNonce)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	events := m.initializeBlockchainID()
+	m.receivedMessageNonces[messageID] = new(big.Int).Set(message.MessageNonce)
+	if relayerRewardAddress != (common.Address{}) {
+		m.relayerRewardAddresses[messageID] = relayerRewardAddress
+	}
+	for _, receipt := range message.Receipts {
+		events = append(events, m.markReceipt(warpMessage.SourceBlockchainID, receipt)...)
+	}
+	m.receiptQueue(warpMessage.SourceBlockchainID).Enqueue(teleportermessenger.TeleporterMessageReceipt{
+		ReceivedMessageNonce: message.MessageNonce,
+		RelayerRewardAddress: relayerRewardAddress,
+	})
+	events = append(events, &teleportermessenger.TeleporterMessengerReceiveCrossChainMessage{
+		MessageID:          messageID,
+		SourceBlockchainID: warpMessage.SourceBlockchainID,
+		Deliverer:          deliverer,
+		RewardRedeemer:     relayerRewardAddress,
+		Message:            message,
+	})
+	if len(message.Message) == 0 {
+		return events, nil
+	}
+
+	if m.execute(warpMessage.SourceBlockchainID, &message, false) != ExecutionSucceeded {
+		m.receivedFailedMessageHashes[messageID] = messageHash
+		return append(events, &teleportermessenger.TeleporterMessengerMessageExecutionFailed{
+			MessageID:          messageID,
+			SourceBlockchainID: warpMessage.SourceBlockchainID,
+			Message:            message,
+		}), nil
+	}
+	return append(events, &teleportermessenger.TeleporterMessengerMessageExecuted{
+		MessageID:          messageID,
+		SourceBlockchainID: warpMessage.SourceBlockchainID,
+	}), nil
+}
+
+// RetryMessageExecution models retryMessageExecution of a message whose execution failed.
+func (m *Messenger) RetryMessageExecution(
+	sourceBlockchainID ids.ID,
+	message teleportermessenger.TeleporterMessage,
+) ([]any, error) {
+	messageID, err := m.CalculateMessageID(sourceBlockchainID, m.blockchainID, message.MessageNonce)
+	if err != nil {
+		return nil, err
+	}
+	failedMessageHash, ok := m.receivedFailedMessageHashes[messageID]
+	if !ok {
+		return nil, teleportermessenger.ErrMessageNotFound
+	}
+	messageHash, err := hashMessage(&message)
+	if err != nil {
+		return nil, err
+	}
+	if messageHash != failedMessageHash {
+		return nil, teleportermessenger.ErrInvalidMessageHash
+	}
+	switch m.execute(sourceBlockchainID, &message, true) {
+	case ExecutionNoCode:
+		return nil, teleportermessenger.ErrDestinationAddressNoCode
+	case ExecutionFailed:
+		return nil, teleportermessenger.ErrRetryExecutionFailed
+	}
+
+	delete(m.receivedFailedMessageHashes, messageID)
+	return []any{&teleportermessenger.TeleporterMessengerMessageExecuted{
+		MessageID:          messageID,
+		SourceBlockchainID: sourceBlockchainID,
+	}}, nil
+}
+
+// SendSpecifiedReceipts models sendSpecifiedReceipts called by sender, sending the receipts of the
+// given messages received from the source blockchain back to it, without dequeuing them.
+func (m *Messenger) SendSpecifiedReceipts(
+	sender common.Address,
+	sourceBlockchainID ids.ID,
+	messageIDs []ids.ID,
+	feeInfo teleportermessenger.TeleporterFeeInfo,
+	allowedRelayerAddresses []common.Address,
+) (ids.ID, []any, error) {
+	receipts := make([]teleportermessenger.TeleporterMessageReceipt, len(messageIDs))
+	for i, messageID := range messageIDs {
+		nonce := m.receivedMessageNonces[messageID]
+		if nonce == nil {
+			return ids.Empty, nil, teleportermessenger.ErrReceiptNotFound
+		}
+		expectedID, err := m.CalculateMessageID(sourceBlockchainID, m.blockchainID, nonce)
+		if err != nil {
+			return ids.Empty, nil, err
+		}
+		if messageID != expectedID {
+			return ids.Empty, nil, teleportermessenger.ErrMessageIDNotFromSource
+		}
+		receipts[i] = teleportermessenger.TeleporterMessageReceipt{
+			ReceivedMessageNonce: new(big.Int).Set(nonce),
+			RelayerRewardAddress: m.relayerRewardAddresses[messageID],
+		}
+	}
+	if err := checkFeeInfo(feeInfo); err != nil {
+		return ids.Empty, nil, err
+	}
+	return m.sendTeleporterMessage(sender, teleportermessenger.TeleporterMessageInput{
+		DestinationBlockchainID: sourceBlockchainID,
+		FeeInfo:                 feeInfo,
+		RequiredGasLimit:        new(big.Int),
+		AllowedRelayerAddresses: allowedRelayerAddresses,
+		Message:                 []byte{},
+	}, receipts)
+}
+
+// RedeemRelayerRewards models redeemRelayerRewards called by relayer, paying out its rewards in
+// the fee asset from escrow.
+func (m *Messenger) RedeemRelayerRewards(relayer common.Address, feeAsset common.Address) ([]any, error) {
+	amount := m.relayerRewardAmounts[relayer][feeAsset]
+	if amount == nil || amount.Sign() == 0 {
+		return nil, teleportermessenger.ErrNoRewardToRedeem
+	}
+	delete(m.relayerRewardAmounts[relayer], feeAsset)
+	m.escrow[feeAsset] = new(big.Int).Sub(m.escrow[feeAsset], amount)
+	return []any{&teleportermessenger.TeleporterMessengerRelayerRewardsRedeemed{
+		Redeemer: relayer,
+		Asset:    feeAsset,
+		Amount:   new(big.Int).Set(amount),
+	}}, nil
+}
+
+func (m *Messenger) initializeBlockchainID() []any {
+	if m.blockchainID != ids.Empty {
+		return nil
+	}
+	m.blockchainID = m.warpBlockchainID
+	return []any{&teleportermessenger.TeleporterMessengerBlockchainIDInitialized{BlockchainID: m.blockchainID}}
+}
+
+func (m *Messenger) sendTeleporterMessage(
+	sender common.Address,
+	input teleportermessenger.TeleporterMessageInput,
+	receipts []teleportermessenger.TeleporterMessageReceipt,
+) (ids.ID, []any, error) {
+	nonce := new(big.Int).Add(m.messageNonce, common.Big1)
+	messageID, err := m.CalculateMessageID(m.warpBlockchainID, input.DestinationBlockchainID, nonce)
+	if err != nil {
+		return ids.Empty, nil, err
+	}
+	message := teleportermessenger.TeleporterMessage{
+		MessageNonce:            nonce,
+		OriginSenderAddress:     sender,
+		DestinationBlockchainID: input.DestinationBlockchainID,
+		DestinationAddress:      input.DestinationAddress,
+		RequiredGasLimit:        new(big.Int),
+		// Empty slices rather than nil ones, as decoded from the contract's events.
+		AllowedRelayerAddresses: append([]common.Address{}, input.AllowedRelayerAddresses...),
+		Receipts:                receipts,
+		Message:                 append([]byte{}, input.Message...),
+	}
+	if input.RequiredGasLimit != nil {
+		message.RequiredGasLimit.Set(input.RequiredGasLimit)
+	}
+	messageHash, err := hashMessage(&message)
+	if err != nil {
+		return ids.Empty, nil, err
+	}
+
+	feeInfo := teleportermessenger.TeleporterFeeInfo{
+		FeeTokenAddress: input.FeeInfo.FeeTokenAddress,
+		Amount:          new(big.Int),
+	}
+	if input.FeeInfo.Amount != nil && input.FeeInfo.Amount.Sign() > 0 {
+		feeInfo.Amount.Set(input.FeeInfo.Amount)
+		m.addEscrow(feeInfo.FeeTokenAddress, feeInfo.Amount)
+	}
+	events := m.initializeBlockchainID()
+	m.messageNonce = nonce
+	m.sentMessageInfo[messageID] = sentMessageInfo{messageHash: messageHash, feeInfo: feeInfo}
+	return messageID, append(events, &teleportermessenger.TeleporterMessengerSendCrossChainMessage{
+		MessageID:               messageID,
+		DestinationBlockchainID: input.DestinationBlockchainID,
+		Message:                 message,
+		FeeInfo:                 copyFeeInfo(feeInfo),
+	}), nil
+}
+
+// markReceipt credits the fee of a message sent to destinationBlockchainID to the relayer that
+// delivered it. Receipts of unknown or already receipted messages are ignored.
+func (m *Messenger) markReceipt(
+	destinationBlockchainID ids.ID,
+	receipt teleportermessenger.TeleporterMessageReceipt,
+) []any {
+	// The message ID was checked to be computable before any effect.
+	messageID, _ := m.CalculateMessageID(m.blockchainID, destinationBlockchainID, receipt.ReceivedMessageNonce)
+	info, ok := m.sentMessageInfo[messageID]
+	if !ok {
+		return nil
+	}
+	delete(m.sentMessageInfo, messageID)
+
+	rewards, ok := m.relayerRewardAmounts[receipt.RelayerRewardAddress]
+	if !ok {
+		rewards = make(map[common.Address]*big.Int)
+		m.relayerRewardAmounts[receipt.RelayerRewardAddress] = rewards
+	}
+	reward := new(big.Int).Set(info.feeInfo.Amount)
+	if current := rewards[info.feeInfo.FeeTokenAddress]; current != nil {
+		reward.Add(reward, current)
+	}
+	rewards[info.feeInfo.FeeTokenAddress] = reward
+	return []any{&teleportermessenger.TeleporterMessengerReceiptReceived{
+		MessageID:               messageID,
+		DestinationBlockchainID: destinationBlockchainID,
+		RelayerRewardAddress:    receipt.RelayerRewardAddress,
+		FeeInfo:                 copyFeeInfo(info.feeInfo),
+	}}
+}
+
+func (m *Messenger) execute(
+	sourceBlockchainID ids.ID,
+	message *teleportermessenger.TeleporterMessage,
+	retry bool,
+) ExecutionResult {
+	if m.Executor == nil {
+		return ExecutionSucceeded
+	}
+	return m.Executor(sourceBlockchainID, message, retry)
+}
+
+func (m *Messenger) receiptQueue(blockchainID ids.ID) *ReceiptQueue {
+	queue, ok := m.receiptQueues[blockchainID]
+	if !ok {
+		queue = NewReceiptQueue()
+		m.receiptQueues[blockchainID] = queue
+	}
+	return queue
+}
+
+func (m *Messenger) addEscrow(feeToken common.Address, amount *big.Int) {
+	escrow := new(big.Int).Set(amount)
+	if current := m.escrow[feeToken]; current != nil {
+		escrow.Add(escrow, current)
+	}
+	m.escrow[feeToken] = escrow
+}
+
+func checkFeeInfo(feeInfo teleportermessenger.TeleporterFeeInfo) error {
+	if feeInfo.Amount != nil && feeInfo.Amount.Sign() > 0 && feeInfo.FeeTokenAddress == (common.Address{}) {
+		return teleportermessenger.ErrZeroFeeAssetContractAddress
+	}
+	return nil
+}
+
+func hashMessage(message *teleportermessenger.TeleporterMessage) (common.Hash, error) {
+	b, err := message.Pack()
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return crypto.Keccak256Hash(b), nil
+}
+
+func copyFeeInfo(feeInfo teleportermessenger.TeleporterFeeInfo) teleportermessenger.TeleporterFeeInfo {
+	if feeInfo.Amount != nil {
+		feeInfo.Amount = new(big.Int).Set(feeInfo.Amount)
+	}
+	return feeInfo
+}
+
+func copyReceipt(receipt teleportermessenger.TeleporterMessageReceipt) teleportermessenger.TeleporterMessageReceipt {
+	if receipt.ReceivedMessageNonce != nil {
+		receipt.ReceivedMessageNonce = new(big.Int).Set(receipt.ReceivedMessageNonce)
+	}
+	return receipt
+}
@@ -0,0 +1,612 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package model
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"math/rand/v2"
+	"reflect"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/upgrade"
+	exampleerc20 "github.com/ava-labs/icm-contracts/abi-bindings/go/mocks/ExampleERC20"
+	teleportermessenger "github.com/ava-labs/icm-contracts/abi-bindings/go/teleporter/TeleporterMessenger"
+	"github.com/ava-labs/subnet-evm/accounts/abi"
+	"github.com/ava-labs/subnet-evm/accounts/abi/bind"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/core/vm"
+	"github.com/ava-labs/subnet-evm/eth/ethconfig"
+	"github.com/ava-labs/subnet-evm/ethclient/simulated"
+	"github.com/ava-labs/subnet-evm/interfaces"
+	"github.com/ava-labs/subnet-evm/node"
+	"github.com/ava-labs/subnet-evm/precompile/contracts/warp"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	txGasLimit       = 5_000_000
+	requiredGasLimit = 100_000
+	differentialRuns = 200
+)
+
+var (
+	warpPrecompileAddress = common.HexToAddress("0x0200000000000000000000000000000000000005")
+	// receiverAddress holds a receiver that fails executions until it is called without data.
+	receiverAddress = common.HexToAddress("0x1000000000000000000000000000000000000001")
+	// noCodeAddress has no code, so executions of messages sent to it fail.
+	noCodeAddress = common.HexToAddress("0x1000000000000000000000000000000000000002")
+)
+
+// program assembles EVM bytecode with labeled jump destinations.
+type program struct {
+	code   []byte
+	labels map[string]int
+	jumps  map[int]string
+}
+
+func newProgram() *program {
+	return &program{labels: make(map[string]int), jumps: make(map[int]string)}
+}
+
+func (p *program) op(ops ...vm.OpCode) *program {
+	for _, op := range ops {
+		p.code = append(p.code, byte(op))
+	}
+	return p
+}
+
+func (p *program) push(b ...byte) *program {
+	p.code = append(p.code, byte(vm.PUSH1)+byte(len(b)-1))
+	p.code = append(p.code, b...)
+	return p
+}
+
+// pushLabel pushes the position of a label, resolved once the program is assembled.
+func (p *program) pushLabel(label string) *program {
+	p.jumps[len(p.code)+1] = label
+	return p.push(0, 0)
+}
+
+func (p *program) label(label string) *program {
+	p.labels[label] = len(p.code)
+	return p.op(vm.JUMPDEST)
+}
+
+func (p *program) bytes() []byte {
+	for i, label := range p.jumps {
+		p.code[i] = byte(p.labels[label] >> 8)
+		p.code[i+1] = byte(p.labels[label])
+	}
+	return p.code
+}
+
+// warpMessengerCode mocks the Warp precompile. getBlockchainID returns the given blockchain ID,
+// sendWarpMessage does nothing, and getVerifiedWarpMessage returns the output stored by the last
+// call with a zero selector.
+func warpMessengerCode(blockchainID ids.ID) []byte {
+	getBlockchainID := warp.WarpABI.Methods["getBlockchainID"].ID
+	sendWarpMessage := warp.WarpABI.Methods["sendWarpMessage"].ID
+	return newProgram().
+		push(0).op(vm.CALLDATALOAD).push(0xe0).op(vm.SHR).
+		op(vm.DUP1).push(getBlockchainID...).op(vm.EQ).pushLabel("id").op(vm.JUMPI).
+		op(vm.DUP1).push(sendWarpMessage...).op(vm.EQ).pushLabel("send").op(vm.JUMPI).
+		op(vm.DUP1, vm.ISZERO).pushLabel("set").op(vm.JUMPI).
+		// Copy the stored output, its length in slot 0 and its words from slot 1, to memory.
+		push(0).
+		label("get").
+		push(0).op(vm.SLOAD, vm.DUP2, vm.LT, vm.ISZERO).pushLabel("return").op(vm.JUMPI).
+		op(vm.DUP1).push(5).op(vm.SHR).push(1).op(vm.ADD, vm.SLOAD, vm.DUP2, vm.MSTORE).
+		push(0x20).op(vm.ADD).pushLabel("get").op(vm.JUMP).
+		label("return").
+		push(0).op(vm.SLOAD).push(0).op(vm.RETURN).
+		label("id").
+		push(blockchainID[:]...).push(0).op(vm.MSTORE).push(0x20).push(0).op(vm.RETURN).
+		label("send").
+		push(0x20).push(0).op(vm.RETURN).
+		// Store the calldata following the selector.
+		label("set").
+		push(4).op(vm.CALLDATASIZE, vm.SUB).push(0).op(vm.SSTORE).
+		push(0).
+		label("store").
+		push(0).op(vm.SLOAD, vm.DUP2, vm.LT, vm.ISZERO).pushLabel("stop").op(vm.JUMPI).
+		op(vm.DUP1).push(4).op(vm.ADD, vm.CALLDATALOAD).
+		op(vm.DUP2).push(5).op(vm.SHR).push(1).op(vm.ADD, vm.SSTORE).
+		push(0x20).op(vm.ADD).pushLabel("store").op(vm.JUMP).
+		label("stop").
+		op(vm.STOP).
+		bytes()
+}
+
+// receiverCode reverts calls with data until it is called without data.
+func receiverCode() []byte {
+	return newProgram().
+		op(vm.CALLDATASIZE).pushLabel("receive").op(vm.JUMPI).
+		push(1).push(0).op(vm.SSTORE, vm.STOP).
+		label("receive").
+		push(0).op(vm.SLOAD).pushLabel("stop").op(vm.JUMPI).
+		push(0).push(0).op(vm.REVERT).
+		label("stop").
+		op(vm.STOP).
+		bytes()
+}
+
+// simulatedChain is a TeleporterMessenger deployed on a simulated backend, next to its model.
+type simulatedChain struct {
+	blockchainID ids.ID
+	backend      *simulated.Backend
+	client       simulated.Client
+	teleporter   *teleportermessenger.TeleporterMessenger
+	token        *exampleerc20.ExampleERC20
+	tokenAddress common.Address
+	model        *Messenger
+	// receiverFixed mirrors whether the receiver at receiverAddress has been called without data.
+	receiverFixed bool
+}
+
+// sentMessage is a message sent in the differential test, and where it was sent from.
+type sentMessage struct {
+	source    *simulatedChain
+	messageID ids.ID
+	message   teleportermessenger.TeleporterMessage
+}
+
+type differentialTest struct {
+	t             *testing.T
+	ctx           context.Context
+	rng           *rand.Rand
+	teleporterABI *abi.ABI
+	deployer      *ecdsa.PrivateKey
+	relayers      []*ecdsa.PrivateKey
+	chains        []*simulatedChain
+	teleporter    common.Address
+	sent          []sentMessage
+	// received holds the messages received by each chain, by source chain.
+	received map[*simulatedChain]map[*simulatedChain][]ids.ID
+}
+
+func newKey(t *testing.T, seed byte) *ecdsa.PrivateKey {
+	key, err := crypto.ToECDSA(common.LeftPadBytes([]byte{seed}, 32))
+	require.NoError(t, err)
+	return key
+}
+
+func newDifferentialTest(t *testing.T) *differentialTest {
+	teleporterABI, err := teleportermessenger.TeleporterMessengerMetaData.GetAbi()
+	require.NoError(t, err)
+	d := &differentialTest{
+		t:             t,
+		ctx:           context.Background(),
+		rng:           rand.New(rand.NewPCG(1, 2)),
+		teleporterABI: teleporterABI,
+		deployer:      newKey(t, 1),
+		relayers:      []*ecdsa.PrivateKey{newKey(t, 2), newKey(t, 3)},
+		received:      make(map[*simulatedChain]map[*simulatedChain][]ids.ID),
+	}
+	for _, blockchainID := range []ids.ID{{1}, {2}} {
+		d.chains = append(d.chains, d.newChain(blockchainID))
+	}
+	return d
+}
+
+func (d *differentialTest) newChain(blockchainID ids.ID) *simulatedChain {
+	t := d.t
+	balance := new(big.Int).Exp(big.NewInt(10), big.NewInt(24), nil)
+	alloc := types.GenesisAlloc{
+		warpPrecompileAddress: {Code: warpMessengerCode(blockchainID), Balance: new(big.Int)},
+		receiverAddress:       {Code: receiverCode(), Balance: new(big.Int)},
+	}
+	for _, key := range append([]*ecdsa.PrivateKey{d.deployer}, d.relayers...) {
+		alloc[crypto.PubkeyToAddress(key.PublicKey)] = types.Account{Balance: balance}
+	}
+	// Start after the Durango upgrade, which the contracts' PUSH0 opcodes require.
+	backend := simulated.NewBackend(alloc, func(_ *node.Config, config *ethconfig.Config) {
+		config.Genesis.Timestamp = uint64(upgrade.InitiallyActiveTime.Unix())
+	})
+	t.Cleanup(func() { backend.Close() })
+	c := &simulatedChain{blockchainID: blockchainID, backend: backend, client: backend.Client()}
+
+	// The deployer's nonces are the same on every chain, and so are the contract addresses.
+	opts := d.transactOpts(d.deployer)
+	opts.GasLimit = 0
+	address, _, teleporter, err := teleportermessenger.DeployTeleporterMessenger(opts, c.client)
+	require.NoError(t, err)
+	backend.Commit(true)
+	c.teleporter = teleporter
+	d.teleporter = address
+	c.tokenAddress, _, c.token, err = exampleerc20.DeployExampleERC20(d.transactOpts(d.deployer), c.client)
+	require.NoError(t, err)
+	backend.Commit(true)
+	_, err = c.token.Approve(d.transactOpts(d.deployer), address, abi.MaxUint256)
+	require.NoError(t, err)
+	backend.Commit(true)
+
+	c.model = NewMessenger(address, blockchainID)
+	c.model.Executor = func(_ ids.ID, message *teleportermessenger.TeleporterMessage, _ bool) ExecutionResult {
+		switch {
+		case message.DestinationAddress != receiverAddress:
+			return ExecutionNoCode
+		case c.receiverFixed:
+			return ExecutionSucceeded
+		default:
+			return ExecutionFailed
+		}
+	}
+	return c
+}
+
+func (d *differentialTest) transactOpts(key *ecdsa.PrivateKey) *bind.TransactOpts {
+	opts, err := bind.NewKeyedTransactorWithChainID(key, big.NewInt(1337))
+	require.NoError(d.t, err)
+	opts.GasLimit = txGasLimit
+	opts.Context = d.ctx
+	return opts
+}
+
+// transact calls the contract at address on the chain from key with data, and returns the logs
+// emitted by the TeleporterMessenger, or the revert error.
+func (d *differentialTest) transact(
+	c *simulatedChain,
+	key *ecdsa.PrivateKey,
+	address common.Address,
+	data []byte,
+) ([]*types.Log, error) {
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	_, err := c.client.CallContract(d.ctx, interfaces.CallMsg{From: from, To: &address, Gas: txGasLimit, Data: data}, nil)
+	if err != nil {
+		return nil, err
+	}
+	contract := bind.NewBoundContract(address, abi.ABI{}, c.client, c.client, c.client)
+	tx, err := contract.RawTransact(d.transactOpts(key), data)
+	require.NoError(d.t, err)
+	c.backend.Commit(true)
+	receipt, err := c.client.TransactionReceipt(d.ctx, tx.Hash())
+	require.NoError(d.t, err)
+	require.Equal(d.t, types.ReceiptStatusSuccessful, receipt.Status)
+
+	var logs []*types.Log
+	for _, log := range receipt.Logs {
+		if log.Address == d.teleporter {
+			logs = append(logs, log)
+		}
+	}
+	return logs, nil
+}
+
+// call calls a TeleporterMessenger method, and checks its effects against the model's.
+func (d *differentialTest) call(
+	c *simulatedChain,
+	key *ecdsa.PrivateKey,
+	modelEvents []any,
+	modelErr error,
+	method string,
+	args ...any,
+) {
+	t := d.t
+	data, err := d.teleporterABI.Pack(method, args...)
+	require.NoError(t, err)
+	logs, err := d.transact(c, key, d.teleporter, data)
+	if modelErr != nil {
+		require.ErrorContains(t, err, modelErr.Error(), "%s", method)
+		return
+	}
+	require.NoError(t, err, "%s", method)
+
+	events := make([]any, len(logs))
+	for i, log := range logs {
+		_, events[i], err = teleportermessenger.ParseLog(*log)
+		require.NoError(t, err)
+		// The model does not know where its events are logged.
+		reflect.ValueOf(events[i]).Elem().FieldByName("Raw").Set(reflect.ValueOf(types.Log{}))
+	}
+	expected, err := json.Marshal(modelEvents)
+	require.NoError(t, err)
+	actual, err := json.Marshal(events)
+	require.NoError(t, err)
+	require.JSONEq(t, string(expected), string(actual), "%s", method)
+}
+
+// checkState checks the contract's state on every chain against the model's.
+func (d *differentialTest) checkState() {
+	t := d.t
+	opts := &bind.CallOpts{Context: d.ctx}
+	for _, c := range d.chains {
+		nonce, err := c.teleporter.MessageNonce(opts)
+		require.NoError(t, err)
+		require.Zero(t, c.model.MessageNonce().Cmp(nonce))
+		blockchainID, err := c.teleporter.BlockchainID(opts)
+		require.NoError(t, err)
+		require.Equal(t, c.model.BlockchainID(), ids.ID(blockchainID))
+		escrow, err := c.token.BalanceOf(opts, d.teleporter)
+		require.NoError(t, err)
+		require.Zero(t, c.model.Escrow(c.tokenAddress).Cmp(escrow))
+
+		for _, relayer := range d.relayers {
+			relayerAddress := crypto.PubkeyToAddress(relayer.PublicKey)
+			reward, err := c.teleporter.CheckRelayerRewardAmount(opts, relayerAddress, c.tokenAddress)
+			require.NoError(t, err)
+			require.Zero(t, c.model.CheckRelayerRewardAmount(relayerAddress, c.tokenAddress).Cmp(reward))
+		}
+		for _, other := range d.chains {
+			size, err := c.teleporter.GetReceiptQueueSize(opts, other.blockchainID)
+			require.NoError(t, err)
+			require.Equal(t, c.model.GetReceiptQueueSize(other.blockchainID), size.Uint64())
+		}
+		for _, sent := range d.sent {
+			hash, err := c.teleporter.GetMessageHash(opts, sent.messageID)
+			require.NoError(t, err)
+			require.Equal(t, c.model.GetMessageHash(sent.messageID), common.Hash(hash))
+			received, err := c.teleporter.MessageReceived(opts, sent.messageID)
+			require.NoError(t, err)
+			require.Equal(t, c.model.MessageReceived(sent.messageID), received)
+			failedHash, err := c.teleporter.ReceivedFailedMessageHashes(opts, sent.messageID)
+			require.NoError(t, err)
+			require.Equal(t, c.model.ReceivedFailedMessageHash(sent.messageID), common.Hash(failedHash))
+		}
+	}
+}
+
+func (d *differentialTest) other(c *simulatedChain) *simulatedChain {
+	if c == d.chains[0] {
+		return d.chains[1]
+	}
+	return d.chains[0]
+}
+
+func (d *differentialTest) randomChain() *simulatedChain {
+	return d.chains[d.rng.IntN(len(d.chains))]
+}
+
+func (d *differentialTest) randomRelayer() *ecdsa.PrivateKey {
+	return d.relayers[d.rng.IntN(len(d.relayers))]
+}
+
+func (d *differentialTest) randomSent() (sentMessage, bool) {
+	if len(d.sent) == 0 {
+		return sentMessage{}, false
+	}
+	return d.sent[d.rng.IntN(len(d.sent))], true
+}
+
+func (d *differentialTest) recordSent(c *simulatedChain, events []any) {
+	for _, event := range events {
+		if sent, ok := event.(*teleportermessenger.TeleporterMessengerSendCrossChainMessage); ok {
+			d.sent = append(d.sent, sentMessage{source: c, messageID: sent.MessageID, message: sent.Message})
+		}
+	}
+}
+
+func (d *differentialTest) send() {
+	c := d.randomChain()
+	input := teleportermessenger.TeleporterMessageInput{
+		DestinationBlockchainID: d.other(c).blockchainID,
+		DestinationAddress:      []common.Address{receiverAddress, noCodeAddress}[d.rng.IntN(2)],
+		FeeInfo:                 teleportermessenger.TeleporterFeeInfo{Amount: new(big.Int)},
+		RequiredGasLimit:        big.NewInt(requiredGasLimit),
+		AllowedRelayerAddresses: []common.Address{},
+		Message:                 []byte{},
+	}
+	switch d.rng.IntN(4) {
+	case 0:
+	case 1:
+		// A fee without a fee token is rejected.
+		input.FeeInfo.Amount.SetUint64(d.rng.Uint64N(1000) + 1)
+	default:
+		input.FeeInfo = teleportermessenger.TeleporterFeeInfo{
+			FeeTokenAddress: c.tokenAddress,
+			Amount:          new(big.Int).SetUint64(d.rng.Uint64N(1000) + 1),
+		}
+	}
+	if d.rng.IntN(3) == 0 {
+		input.AllowedRelayerAddresses = []common.Address{crypto.PubkeyToAddress(d.randomRelayer().PublicKey)}
+	}
+	if d.rng.IntN(4) != 0 {
+		input.Message = []byte(fmt.Sprintf("message %d", len(d.sent)))
+	}
+
+	_, events, err := c.model.SendCrossChainMessage(crypto.PubkeyToAddress(d.deployer.PublicKey), input)
+	d.call(c, d.deployer, events, err, "sendCrossChainMessage", input)
+	if err == nil {
+		d.recordSent(c, events)
+	}
+}
+
+func (d *differentialTest) deliver() {
+	sent, ok := d.randomSent()
+	if !ok {
+		return
+	}
+	destination := d.other(sent.source)
+	relayer := d.randomRelayer()
+	relayerAddress := crypto.PubkeyToAddress(relayer.PublicKey)
+	payload, err := sent.message.Pack()
+	require.NoError(d.t, err)
+	output, err := warp.PackGetVerifiedWarpMessageOutput(warp.GetVerifiedWarpMessageOutput{
+		Message: warp.WarpMessage{
+			SourceChainID:       common.Hash(sent.source.blockchainID),
+			OriginSenderAddress: d.teleporter,
+			Payload:             payload,
+		},
+		Valid: true,
+	})
+	require.NoError(d.t, err)
+	_, err = d.transact(destination, d.deployer, warpPrecompileAddress, append(make([]byte, 4), output...))
+	require.NoError(d.t, err)
+
+	events, err := destination.model.ReceiveCrossChainMessage(relayerAddress, relayerAddress, WarpMessage{
+		SourceBlockchainID:  sent.source.blockchainID,
+		OriginSenderAddress: d.teleporter,
+		Message:             sent.message,
+	})
+	d.call(destination, relayer, events, err, "receiveCrossChainMessage", uint32(0), relayerAddress)
+	if err == nil {
+		received := d.received[destination]
+		if received == nil {
+			received = make(map[*simulatedChain][]ids.ID)
+			d.received[destination] = received
+		}
+		received[sent.source] = append(received[sent.source], sent.messageID)
+	}
+}
+
+func (d *differentialTest) addFeeAmount() {
+	sent, ok := d.randomSent()
+	if !ok {
+		return
+	}
+	amount := new(big.Int).SetUint64(d.rng.Uint64N(100))
+	events, err := sent.source.model.AddFeeAmount(sent.messageID, sent.source.tokenAddress, amount)
+	d.call(sent.source, d.deployer, events, err, "addFeeAmount", sent.messageID, sent.source.tokenAddress, amount)
+}
+
+func (d *differentialTest) retrySend() {
+	sent, ok := d.randomSent()
+	if !ok {
+		return
+	}
+	events, err := sent.source.model.RetrySendCrossChainMessage(sent.message)
+	d.call(sent.source, d.deployer, events, err, "retrySendCrossChainMessage", sent.message)
+}
+
+func (d *differentialTest) retryExecution() {
+	sent, ok := d.randomSent()
+	if !ok {
+		return
+	}
+	// Most retries are of messages that failed to execute at the receiver, the others are rejected.
+	failed := d.failedAtReceiver(nil)
+	if len(failed) > 0 && d.rng.IntN(4) != 0 {
+		sent = failed[d.rng.IntN(len(failed))]
+	}
+	destination := d.other(sent.source)
+	events, err := destination.model.RetryMessageExecution(sent.source.blockchainID, sent.message)
+	d.call(
+		destination,
+		d.randomRelayer(),
+		events,
+		err,
+		"retryMessageExecution",
+		sent.source.blockchainID,
+		sent.message,
+	)
+}
+
+// failedAtReceiver returns the messages that failed to execute at the receiver on the destination
+// chain, or on any chain if destination is nil.
+func (d *differentialTest) failedAtReceiver(destination *simulatedChain) []sentMessage {
+	var failed []sentMessage
+	for _, sent := range d.sent {
+		c := d.other(sent.source)
+		if (destination == nil || c == destination) &&
+			sent.message.DestinationAddress == receiverAddress &&
+			c.model.ReceivedFailedMessageHash(sent.messageID) != (common.Hash{}) {
+			failed = append(failed, sent)
+		}
+	}
+	return failed
+}
+
+func (d *differentialTest) fixReceiver() {
+	c := d.randomChain()
+	// Fixing the receiver before any execution fails at it would leave nothing to retry.
+	if c.receiverFixed || len(d.failedAtReceiver(c)) == 0 {
+		return
+	}
+	_, err := d.transact(c, d.deployer, receiverAddress, nil)
+	require.NoError(d.t, err)
+	c.receiverFixed = true
+}
+
+func (d *differentialTest) sendSpecifiedReceipts() {
+	c := d.randomChain()
+	source := d.other(c)
+	received := d.received[c][source]
+	var messageIDs []ids.ID
+	if len(received) > 0 {
+		messageIDs = append(messageIDs, received[d.rng.IntN(len(received))])
+	}
+	if sent, ok := d.randomSent(); ok && d.rng.IntN(4) == 0 {
+		// Receipts of messages not received from the source are rejected.
+		messageIDs = append(messageIDs, sent.messageID)
+	}
+	feeInfo := teleportermessenger.TeleporterFeeInfo{Amount: new(big.Int)}
+	_, events, err := c.model.SendSpecifiedReceipts(
+		crypto.PubkeyToAddress(d.deployer.PublicKey),
+		source.blockchainID,
+		messageIDs,
+		feeInfo,
+		[]common.Address{},
+	)
+	d.call(
+		c,
+		d.deployer,
+		events,
+		err,
+		"sendSpecifiedReceipts",
+		source.blockchainID,
+		toBytes32(messageIDs),
+		feeInfo,
+		[]common.Address{},
+	)
+	if err == nil {
+		d.recordSent(c, events)
+	}
+}
+
+func (d *differentialTest) redeemRelayerRewards() {
+	c := d.randomChain()
+	relayer := d.randomRelayer()
+	events, err := c.model.RedeemRelayerRewards(crypto.PubkeyToAddress(relayer.PublicKey), c.tokenAddress)
+	d.call(c, relayer, events, err, "redeemRelayerRewards", c.tokenAddress)
+}
+
+func toBytes32(messageIDs []ids.ID) [][32]byte {
+	b := make([][32]byte, len(messageIDs))
+	for i, messageID := range messageIDs {
+		b[i] = messageID
+	}
+	return b
+}
+
+// TestDifferential runs random operations against the TeleporterMessenger contract on two
+// simulated chains, and checks that the model emits the same events, reverts with the same
+// reasons and ends up in the same state.
+func TestDifferential(t *testing.T) {
+	d := newDifferentialTest(t)
+	operations := []struct {
+		weight int
+		run    func()
+	}{
+		{weight: 6, run: d.send},
+		{weight: 6, run: d.deliver},
+		{weight: 2, run: d.addFeeAmount},
+		{weight: 1, run: d.retrySend},
+		{weight: 2, run: d.retryExecution},
+		{weight: 1, run: d.fixReceiver},
+		{weight: 2, run: d.sendSpecifiedReceipts},
+		{weight: 2, run: d.redeemRelayerRewards},
+	}
+	var totalWeight int
+	for _, operation := range operations {
+		totalWeight += operation.weight
+	}
+
+	for i := 0; i < differentialRuns; i++ {
+		n := d.rng.IntN(totalWeight)
+		for _, operation := range operations {
+			if n < operation.weight {
+				operation.run()
+				break
+			}
+			n -= operation.weight
+		}
+		d.checkState()
+	}
+}
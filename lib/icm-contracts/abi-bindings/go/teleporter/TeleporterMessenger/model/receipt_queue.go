@@ -0,0 +1,62 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package model
+
+import (
+	teleportermessenger "github.com/ava-labs/icm-contracts/abi-bindings/go/teleporter/TeleporterMessenger"
+)
+
+// ReceiptQueue models the ReceiptQueue library, a FIFO queue of the receipts of the messages
+// received from a blockchain.
+type ReceiptQueue struct {
+	first uint64
+	last  uint64
+	data  map[uint64]teleportermessenger.TeleporterMessageReceipt
+}
+
+func NewReceiptQueue() *ReceiptQueue {
+	return &ReceiptQueue{data: make(map[uint64]teleportermessenger.TeleporterMessageReceipt)}
+}
+
+func (q *ReceiptQueue) Enqueue(receipt teleportermessenger.TeleporterMessageReceipt) {
+	q.data[q.last] = copyReceipt(receipt)
+	q.last++
+}
+
+func (q *ReceiptQueue) Dequeue() (teleportermessenger.TeleporterMessageReceipt, error) {
+	if q.first == q.last {
+		return teleportermessenger.TeleporterMessageReceipt{}, teleportermessenger.ErrEmptyReceiptQueue
+	}
+	receipt := q.data[q.first]
+	delete(q.data, q.first)
+	q.first++
+	return receipt, nil
+}
+
+// GetOutstandingReceiptsToSend dequeues the receipts to attach to the next message sent to the
+// blockchain, at most teleportermessenger.MaximumReceiptCount of them.
+func (q *ReceiptQueue) GetOutstandingReceiptsToSend() []teleportermessenger.TeleporterMessageReceipt {
+	receipts := make([]teleportermessenger.TeleporterMessageReceipt, q.outstandingReceiptCount())
+	for i := range receipts {
+		// The queue holds at least len(receipts) receipts, so dequeuing cannot fail.
+		receipts[i], _ = q.Dequeue()
+	}
+	return receipts
+}
+
+func (q *ReceiptQueue) Size() uint64 {
+	return q.last - q.first
+}
+
+func (q *ReceiptQueue) GetReceiptAtIndex(index uint64) (teleportermessenger.TeleporterMessageReceipt, error) {
+	if index >= q.Size() {
+		return teleportermessenger.TeleporterMessageReceipt{}, teleportermessenger.ErrReceiptIndexOutOfBounds
+	}
+	return copyReceipt(q.data[q.first+index]), nil
+}
+
+// outstandingReceiptCount is the number of receipts to attach to the next message sent.
+func (q *ReceiptQueue) outstandingReceiptCount() uint64 {
+	return min(q.Size(), teleportermessenger.MaximumReceiptCount)
+}
@@ -0,0 +1,97 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package model
+
+import (
+	"math/big"
+	"testing"
+
+	teleportermessenger "github.com/ava-labs/icm-contracts/abi-bindings/go/teleporter/TeleporterMessenger"
+	"github.com/stretchr/testify/require"
+)
+
+func receipt(nonce int64) teleportermessenger.TeleporterMessageReceipt {
+	return teleportermessenger.TeleporterMessageReceipt{ReceivedMessageNonce: big.NewInt(nonce)}
+}
+
+func TestReceiptQueue(t *testing.T) {
+	testCases := []struct {
+		name             string
+		enqueued         int64
+		dequeued         int
+		expectedSent     []int64
+		expectedSize     uint64
+		expectedDequeued error
+	}{
+		{
+			name:         "empty",
+			expectedSent: []int64{},
+		},
+		{
+			name:         "fewer than the maximum",
+			enqueued:     3,
+			expectedSent: []int64{1, 2, 3},
+		},
+		{
+			name:         "more than the maximum",
+			enqueued:     7,
+			expectedSent: []int64{1, 2, 3, 4, 5},
+			expectedSize: 2,
+		},
+		{
+			name:         "after dequeuing",
+			enqueued:     7,
+			dequeued:     1,
+			expectedSent: []int64{2, 3, 4, 5, 6},
+			expectedSize: 1,
+		},
+		{
+			name:             "dequeuing an empty queue",
+			dequeued:         1,
+			expectedSent:     []int64{},
+			expectedDequeued: teleportermessenger.ErrEmptyReceiptQueue,
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			q := NewReceiptQueue()
+			for nonce := int64(1); nonce <= test.enqueued; nonce++ {
+				q.Enqueue(receipt(nonce))
+			}
+			for i := 0; i < test.dequeued; i++ {
+				_, err := q.Dequeue()
+				require.ErrorIs(t, err, test.expectedDequeued)
+			}
+
+			sent := q.GetOutstandingReceiptsToSend()
+			nonces := make([]int64, len(sent))
+			for i, r := range sent {
+				nonces[i] = r.ReceivedMessageNonce.Int64()
+			}
+			require.Equal(t, test.expectedSent, nonces)
+			require.Equal(t, test.expectedSize, q.Size())
+		})
+	}
+}
+
+func TestReceiptQueueGetReceiptAtIndex(t *testing.T) {
+	q := NewReceiptQueue()
+	q.Enqueue(receipt(1))
+	q.Enqueue(receipt(2))
+	_, err := q.Dequeue()
+	require.NoError(t, err)
+
+	r, err := q.GetReceiptAtIndex(0)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), r.ReceivedMessageNonce.Int64())
+
+	// Receipts returned are copies.
+	r.ReceivedMessageNonce.SetInt64(3)
+	r, err = q.GetReceiptAtIndex(0)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), r.ReceivedMessageNonce.Int64())
+
+	_, err = q.GetReceiptAtIndex(1)
+	require.ErrorIs(t, err, teleportermessenger.ErrReceiptIndexOutOfBounds)
+}
@@ -80,6 +80,17 @@ func PackRetryMessageExecution(sourceBlockchainID ids.ID, message TeleporterMess
 	return abi.Pack("retryMessageExecution", sourceBlockchainID, message)
 }
 
+// PackRetrySendCrossChainMessage packs a TeleporterMessage to form
+// a call to the retrySendCrossChainMessage function
+func PackRetrySendCrossChainMessage(message TeleporterMessage) ([]byte, error) {
+	abi, err := TeleporterMessengerMetaData.GetAbi()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get abi")
+	}
+
+	return abi.Pack("retrySendCrossChainMessage", message)
+}
+
 // PackReceiveCrossChainMessage packs a ReceiveCrossChainMessageInput to form
 // a call to the receiveCrossChainMessage function
 func PackReceiveCrossChainMessage(messageIndex uint32, relayerRewardAddress common.Address) ([]byte, error) {
@@ -0,0 +1,197 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package teleportermessenger
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+	"github.com/ava-labs/avalanchego/utils/set"
+	avalancheWarp "github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp/payload"
+	gasUtils "github.com/ava-labs/icm-contracts/utils/gas-utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+)
+
+// MaximumReceiptCount mirrors _MAXIMUM_RECEIPT_COUNT in ReceiptQueue.sol, the number of queued
+// receipts the TeleporterMessenger attaches to each message it sends.
+const MaximumReceiptCount = 5
+
+// Errors for messages the contract accepts, but which cannot be delivered or executed as intended
+var (
+	ErrSameBlockchain          = errors.New("destination blockchain ID is the source blockchain ID")
+	ErrZeroDestinationAddress  = errors.New("zero destination address")
+	ErrZeroAllowedRelayer      = errors.New("zero allowed relayer address")
+	ErrDuplicateAllowedRelayer = errors.New("duplicate allowed relayer address")
+	ErrInvalidFeeAmount        = errors.New("fee amount is not a uint256")
+	ErrInvalidRequiredGasLimit = errors.New("required gas limit is not a uint256")
+	ErrTooManyReceipts         = errors.New("too many receipts")
+)
+
+// ValidationConfig holds the chain parameters messages are validated against
+type ValidationConfig struct {
+	// SourceBlockchainID is the blockchain ID the message is sent from.
+	SourceBlockchainID ids.ID
+	// DestinationBlockGasLimit is the block gas limit of the destination chain. If zero, the gas
+	// required to receive the message is not checked.
+	DestinationBlockGasLimit uint64
+	// NumSigners is the expected number of validators signing the Warp message, used to estimate
+	// the gas required to receive it.
+	NumSigners int
+}
+
+// Validate checks a message input before it is sent with sendCrossChainMessage. All the problems
+// found are returned, joined with errors.Join.
+func (i *TeleporterMessageInput) Validate(config ValidationConfig) error {
+	errs := validateFeeInfo(i.FeeInfo)
+
+	// The receipts are attached by the contract, so assume the worst case.
+	message := TeleporterMessage{
+		MessageNonce:            common.Big1,
+		DestinationBlockchainID: i.DestinationBlockchainID,
+		DestinationAddress:      i.DestinationAddress,
+		RequiredGasLimit:        i.RequiredGasLimit,
+		AllowedRelayerAddresses: i.AllowedRelayerAddresses,
+		Receipts:                make([]TeleporterMessageReceipt, MaximumReceiptCount),
+		Message:                 i.Message,
+	}
+	for r := range message.Receipts {
+		message.Receipts[r].ReceivedMessageNonce = common.Big1
+	}
+	return errors.Join(append(errs, validateMessage(&message, config)...)...)
+}
+
+// Validate checks a message before it is sent or retried with retrySendCrossChainMessage, and
+// before it is delivered with receiveCrossChainMessage. All the problems found are returned,
+// joined with errors.Join.
+func (m *TeleporterMessage) Validate(config ValidationConfig) error {
+	var errs []error
+	if m.MessageNonce == nil || m.MessageNonce.Sign() == 0 {
+		errs = append(errs, ErrZeroMessageNonce)
+	}
+	if len(m.Receipts) > MaximumReceiptCount {
+		errs = append(errs, fmt.Errorf("%w: %d, at most %d", ErrTooManyReceipts, len(m.Receipts), MaximumReceiptCount))
+	}
+	return errors.Join(append(errs, validateMessage(m, config)...)...)
+}
+
+func validateFeeInfo(feeInfo TeleporterFeeInfo) []error {
+	if feeInfo.Amount == nil || feeInfo.Amount.Sign() == 0 {
+		return nil
+	}
+	if !isUint256(feeInfo.Amount) {
+		return []error{fmt.Errorf("%w: %s", ErrInvalidFeeAmount, feeInfo.Amount)}
+	}
+	if feeInfo.FeeTokenAddress == (common.Address{}) {
+		return []error{ErrZeroFeeAssetContractAddress}
+	}
+	return nil
+}
+
+func validateMessage(m *TeleporterMessage, config ValidationConfig) []error {
+	var errs []error
+	destinationBlockchainID := ids.ID(m.DestinationBlockchainID)
+	switch destinationBlockchainID {
+	case ids.Empty:
+		errs = append(errs, ErrInvalidDestinationChainID)
+	case config.SourceBlockchainID:
+		errs = append(errs, fmt.Errorf("%w: %s", ErrSameBlockchain, destinationBlockchainID))
+	}
+	if m.DestinationAddress == (common.Address{}) {
+		errs = append(errs, ErrZeroDestinationAddress)
+	}
+
+	relayers := set.NewSet[common.Address](len(m.AllowedRelayerAddresses))
+	for _, relayer := range m.AllowedRelayerAddresses {
+		if relayer == (common.Address{}) {
+			errs = append(errs, ErrZeroAllowedRelayer)
+			continue
+		}
+		if relayers.Contains(relayer) {
+			errs = append(errs, fmt.Errorf("%w: %s", ErrDuplicateAllowedRelayer, relayer))
+			continue
+		}
+		relayers.Add(relayer)
+	}
+
+	if m.RequiredGasLimit == nil || !isUint256(m.RequiredGasLimit) {
+		return append(errs, fmt.Errorf("%w: %v", ErrInvalidRequiredGasLimit, m.RequiredGasLimit))
+	}
+	if config.DestinationBlockGasLimit == 0 {
+		return errs
+	}
+	gasLimit, err := receiveGasLimit(m, config)
+	if err != nil {
+		return append(errs, err)
+	}
+	if gasLimit > config.DestinationBlockGasLimit {
+		errs = append(errs, fmt.Errorf(
+			"%w: receiving the message requires %d gas, above the destination block gas limit of %d",
+			ErrInsufficientGas,
+			gasLimit,
+			config.DestinationBlockGasLimit,
+		))
+	}
+	return errs
+}
+
+// receiveGasLimit estimates the gas required to deliver the message with receiveCrossChainMessage,
+// signed by config.NumSigners validators.
+func receiveGasLimit(m *TeleporterMessage, config ValidationConfig) (uint64, error) {
+	if !m.RequiredGasLimit.IsUint64() {
+		return 0, fmt.Errorf("%w: required gas limit %s", ErrInsufficientGas, m.RequiredGasLimit)
+	}
+	// Nonces are fixed size when packed, so missing ones are filled in rather than rejected twice.
+	sized := *m
+	if sized.MessageNonce == nil {
+		sized.MessageNonce = common.Big1
+	}
+	sized.Receipts = make([]TeleporterMessageReceipt, len(m.Receipts))
+	for i, receipt := range m.Receipts {
+		if receipt.ReceivedMessageNonce == nil {
+			receipt.ReceivedMessageNonce = common.Big1
+		}
+		sized.Receipts[i] = receipt
+	}
+	messageBytes, err := sized.Pack()
+	if err != nil {
+		return 0, err
+	}
+	// The Warp message is only built to measure its size, so the network ID, origin sender
+	// address and signature do not matter.
+	addressedCall, err := payload.NewAddressedCall(common.Address{}.Bytes(), messageBytes)
+	if err != nil {
+		return 0, err
+	}
+	unsignedMessage, err := avalancheWarp.NewUnsignedMessage(0, config.SourceBlockchainID, addressedCall.Bytes())
+	if err != nil {
+		return 0, err
+	}
+	signers := set.NewBits()
+	for i := 0; i < config.NumSigners; i++ {
+		signers.Add(i)
+	}
+	signedMessage, err := avalancheWarp.NewMessage(unsignedMessage, &avalancheWarp.BitSetSignature{
+		Signers:   signers.Bytes(),
+		Signature: [bls.SignatureLen]byte{},
+	})
+	if err != nil {
+		return 0, err
+	}
+	return gasUtils.CalculateReceiveMessageGasLimit(
+		config.NumSigners,
+		m.RequiredGasLimit,
+		len(signedMessage.Bytes()),
+		len(unsignedMessage.Payload),
+		len(m.Receipts),
+	)
+}
+
+func isUint256(n *big.Int) bool {
+	return n.Sign() >= 0 && n.Cmp(math.MaxBig256) <= 0
+}
@@ -0,0 +1,182 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package teleportermessenger
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+	"github.com/ava-labs/avalanchego/utils/set"
+	avalancheWarp "github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp/payload"
+	gasUtils "github.com/ava-labs/icm-contracts/utils/gas-utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateMessageInput(t *testing.T) {
+	sourceBlockchainID := ids.ID{5, 6, 7, 8}
+	address := common.HexToAddress("0x0123456789abcdef0123456789abcdef01234567")
+	validInput := func() TeleporterMessageInput {
+		return TeleporterMessageInput{
+			DestinationBlockchainID: ids.ID{1, 2, 3, 4},
+			DestinationAddress:      address,
+			FeeInfo:                 TeleporterFeeInfo{FeeTokenAddress: address, Amount: big.NewInt(1)},
+			RequiredGasLimit:        big.NewInt(100_000),
+			AllowedRelayerAddresses: []common.Address{address},
+			Message:                 []byte{1, 2, 3, 4},
+		}
+	}
+
+	var tests = []struct {
+		name   string
+		modify func(*TeleporterMessageInput)
+		errs   []error
+	}{
+		{
+			name:   "valid",
+			modify: func(*TeleporterMessageInput) {},
+		},
+		{
+			name: "zero fee without fee token",
+			modify: func(i *TeleporterMessageInput) {
+				i.FeeInfo = TeleporterFeeInfo{Amount: big.NewInt(0)}
+			},
+		},
+		{
+			name: "fee without fee token",
+			modify: func(i *TeleporterMessageInput) {
+				i.FeeInfo.FeeTokenAddress = common.Address{}
+			},
+			errs: []error{ErrZeroFeeAssetContractAddress},
+		},
+		{
+			name: "negative fee",
+			modify: func(i *TeleporterMessageInput) {
+				i.FeeInfo.Amount = big.NewInt(-1)
+			},
+			errs: []error{ErrInvalidFeeAmount},
+		},
+		{
+			name: "zero destination blockchain",
+			modify: func(i *TeleporterMessageInput) {
+				i.DestinationBlockchainID = ids.Empty
+			},
+			errs: []error{ErrInvalidDestinationChainID},
+		},
+		{
+			name: "destination is source",
+			modify: func(i *TeleporterMessageInput) {
+				i.DestinationBlockchainID = sourceBlockchainID
+			},
+			errs: []error{ErrSameBlockchain},
+		},
+		{
+			name: "zero destination address",
+			modify: func(i *TeleporterMessageInput) {
+				i.DestinationAddress = common.Address{}
+			},
+			errs: []error{ErrZeroDestinationAddress},
+		},
+		{
+			name: "zero and duplicate allowed relayers",
+			modify: func(i *TeleporterMessageInput) {
+				i.AllowedRelayerAddresses = []common.Address{address, {}, address}
+			},
+			errs: []error{ErrZeroAllowedRelayer, ErrDuplicateAllowedRelayer},
+		},
+		{
+			name: "missing required gas limit",
+			modify: func(i *TeleporterMessageInput) {
+				i.RequiredGasLimit = nil
+			},
+			errs: []error{ErrInvalidRequiredGasLimit},
+		},
+		{
+			name: "required gas limit above block gas limit",
+			modify: func(i *TeleporterMessageInput) {
+				i.RequiredGasLimit = big.NewInt(8_000_000)
+			},
+			errs: []error{ErrInsufficientGas},
+		},
+		{
+			name: "receive overhead above block gas limit",
+			modify: func(i *TeleporterMessageInput) {
+				i.RequiredGasLimit = big.NewInt(7_700_000)
+			},
+			errs: []error{ErrInsufficientGas},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := validInput()
+			tt.modify(&input)
+			err := input.Validate(ValidationConfig{
+				SourceBlockchainID:       sourceBlockchainID,
+				DestinationBlockGasLimit: 8_000_000,
+				NumSigners:               10,
+			})
+			if len(tt.errs) == 0 {
+				require.NoError(t, err)
+				return
+			}
+			for _, expected := range tt.errs {
+				require.ErrorIs(t, err, expected)
+			}
+		})
+	}
+}
+
+func TestValidateMessage(t *testing.T) {
+	config := ValidationConfig{SourceBlockchainID: ids.ID{5, 6, 7, 8}}
+
+	message := createTestTeleporterMessage(big.NewInt(1))
+	require.NoError(t, message.Validate(config))
+
+	message = createTestTeleporterMessage(nil)
+	message.Receipts = make([]TeleporterMessageReceipt, MaximumReceiptCount+1)
+	err := message.Validate(config)
+	require.ErrorIs(t, err, ErrZeroMessageNonce)
+	require.ErrorIs(t, err, ErrTooManyReceipts)
+
+	// Missing nonces must not prevent estimating the gas required to receive the message.
+	config.DestinationBlockGasLimit = 1_000
+	require.ErrorIs(t, message.Validate(config), ErrInsufficientGas)
+}
+
+func TestReceiveGasLimit(t *testing.T) {
+	config := ValidationConfig{SourceBlockchainID: ids.ID{5, 6, 7, 8}, NumSigners: 10}
+	message := createTestTeleporterMessage(big.NewInt(1))
+
+	// The estimate must match the gas limit a relayer requests for the signed Warp message.
+	messageBytes, err := message.Pack()
+	require.NoError(t, err)
+	addressedCall, err := payload.NewAddressedCall(
+		common.HexToAddress("0x253b2784c75e510dD0fF1da844684a1aC0aa5fcf").Bytes(),
+		messageBytes,
+	)
+	require.NoError(t, err)
+	unsignedMessage, err := avalancheWarp.NewUnsignedMessage(1, config.SourceBlockchainID, addressedCall.Bytes())
+	require.NoError(t, err)
+	signedMessage, err := avalancheWarp.NewMessage(unsignedMessage, &avalancheWarp.BitSetSignature{
+		Signers:   set.NewBits(0, 1, 2, 3, 4, 5, 6, 7, 8, 9).Bytes(),
+		Signature: [bls.SignatureLen]byte{},
+	})
+	require.NoError(t, err)
+	expected, err := gasUtils.CalculateReceiveMessageGasLimit(
+		config.NumSigners,
+		message.RequiredGasLimit,
+		len(signedMessage.Bytes()),
+		len(signedMessage.Payload),
+		len(message.Receipts),
+	)
+	require.NoError(t, err)
+
+	gasLimit, err := receiveGasLimit(&message, config)
+	require.NoError(t, err)
+	require.Equal(t, expected, gasLimit)
+}
@@ -6,6 +6,9 @@ package teleporterregistry
 import (
 	"fmt"
 
+	"github.com/ava-labs/avalanchego/ids"
+	avalancheWarp "github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp/payload"
 	"github.com/ava-labs/subnet-evm/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/pkg/errors"
@@ -87,7 +90,6 @@ func UnpackTeleporterRegistryWarpPayload(entryBytes []byte) (ProtocolRegistryEnt
 		},
 	}
 	unpacked, err := args.Unpack(entryBytes)
-	fmt.Println("unpacked: ", unpacked)
 	if err != nil {
 		return ProtocolRegistryEntry{}, common.Address{},
 			fmt.Errorf("failed to unpack to Teleporter registry entry with err: %v", err)
@@ -105,6 +107,27 @@ func UnpackTeleporterRegistryWarpPayload(entryBytes []byte) (ProtocolRegistryEnt
 	return payload.ProtocolRegistryEntry, payload.DestinationAddress, nil
 }
 
+// NewOffChainRegistryMessage creates the unsigned Warp message that registers entry with the
+// TeleporterRegistry at registryAddress on blockchainID. The message is an AddressedCall with an
+// empty source address, which the registry requires, and must be added to the chain's approved
+// off-chain Warp messages so that its validators sign it.
+func NewOffChainRegistryMessage(
+	networkID uint32,
+	blockchainID ids.ID,
+	registryAddress common.Address,
+	entry ProtocolRegistryEntry,
+) (*avalancheWarp.UnsignedMessage, error) {
+	payloadBytes, err := PackTeleporterRegistryWarpPayload(entry, registryAddress)
+	if err != nil {
+		return nil, err
+	}
+	addressedPayload, err := payload.NewAddressedCall([]byte{}, payloadBytes)
+	if err != nil {
+		return nil, err
+	}
+	return avalancheWarp.NewUnsignedMessage(networkID, blockchainID, addressedPayload.Bytes())
+}
+
 // PackAddProtocolVersion packs input to form a call to the addProtocolVersion function
 func PackAddProtocolVersion(messageIndex uint32) ([]byte, error) {
 	abi, err := TeleporterRegistryMetaData.GetAbi()
@@ -7,6 +7,8 @@ import (
 	"math/big"
 	"testing"
 
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp/payload"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/stretchr/testify/require"
 )
@@ -39,3 +41,27 @@ func TestPackUnpackProtocolRegistryEntry(t *testing.T) {
 	require.Equal(t, entry.ProtocolAddress, unpackedEntry.ProtocolAddress)
 	require.Equal(t, destinationAddress, unpackedDestinationAddress)
 }
+
+func TestNewOffChainRegistryMessage(t *testing.T) {
+	entry := ProtocolRegistryEntry{
+		Version:         big.NewInt(2),
+		ProtocolAddress: common.HexToAddress("0x0123456789abcdef0123456789abcdef01234567"),
+	}
+	registryAddress := common.HexToAddress("0x0123456789abcdef0123456789abcdef01234568")
+	blockchainID := ids.ID{1}
+
+	message, err := NewOffChainRegistryMessage(1337, blockchainID, registryAddress, entry)
+	require.NoError(t, err)
+	require.Equal(t, uint32(1337), message.NetworkID)
+	require.Equal(t, blockchainID, message.SourceChainID)
+
+	addressedCall, err := payload.ParseAddressedCall(message.Payload)
+	require.NoError(t, err)
+	require.Empty(t, addressedCall.SourceAddress)
+
+	unpackedEntry, unpackedDestinationAddress, err := UnpackTeleporterRegistryWarpPayload(addressedCall.Payload)
+	require.NoError(t, err)
+	require.Equal(t, entry.Version, unpackedEntry.Version)
+	require.Equal(t, entry.ProtocolAddress, unpackedEntry.ProtocolAddress)
+	require.Equal(t, registryAddress, unpackedDestinationAddress)
+}
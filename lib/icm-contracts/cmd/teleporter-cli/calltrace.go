@@ -0,0 +1,308 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+
+	inativeminter "github.com/ava-labs/icm-contracts/abi-bindings/go/INativeMinter"
+	proxyadmin "github.com/ava-labs/icm-contracts/abi-bindings/go/ProxyAdmin"
+	transparentupgradeableproxy "github.com/ava-labs/icm-contracts/abi-bindings/go/TransparentUpgradeableProxy"
+	validatorsetsig "github.com/ava-labs/icm-contracts/abi-bindings/go/governance/ValidatorSetSig"
+	erc20tokenhome "github.com/ava-labs/icm-contracts/abi-bindings/go/ictt/TokenHome/ERC20TokenHome"
+	erc20tokenhomeupgradeable "github.com/ava-labs/icm-contracts/abi-bindings/go/ictt/TokenHome/ERC20TokenHomeUpgradeable"
+	nativetokenhome "github.com/ava-labs/icm-contracts/abi-bindings/go/ictt/TokenHome/NativeTokenHome"
+	nativetokenhomeupgradeable "github.com/ava-labs/icm-contracts/abi-bindings/go/ictt/TokenHome/NativeTokenHomeUpgradeable"
+	tokenhome "github.com/ava-labs/icm-contracts/abi-bindings/go/ictt/TokenHome/TokenHome"
+	erc20tokenremote "github.com/ava-labs/icm-contracts/abi-bindings/go/ictt/TokenRemote/ERC20TokenRemote"
+	erc20tokenremoteupgradeable "github.com/ava-labs/icm-contracts/abi-bindings/go/ictt/TokenRemote/ERC20TokenRemoteUpgradeable"
+	nativetokenremote "github.com/ava-labs/icm-contracts/abi-bindings/go/ictt/TokenRemote/NativeTokenRemote"
+	nativetokenremoteupgradeable "github.com/ava-labs/icm-contracts/abi-bindings/go/ictt/TokenRemote/NativeTokenRemoteUpgradeable"
+	tokenremote "github.com/ava-labs/icm-contracts/abi-bindings/go/ictt/TokenRemote/TokenRemote"
+	wrappednativetoken "github.com/ava-labs/icm-contracts/abi-bindings/go/ictt/WrappedNativeToken"
+	exampleerc20decimals "github.com/ava-labs/icm-contracts/abi-bindings/go/ictt/mocks/ExampleERC20Decimals"
+	mockerc20sendandcallreceiver "github.com/ava-labs/icm-contracts/abi-bindings/go/ictt/mocks/MockERC20SendAndCallReceiver"
+	mocknativesendandcallreceiver "github.com/ava-labs/icm-contracts/abi-bindings/go/ictt/mocks/MockNativeSendAndCallReceiver"
+	exampleerc20 "github.com/ava-labs/icm-contracts/abi-bindings/go/mocks/ExampleERC20"
+	teleportermessenger "github.com/ava-labs/icm-contracts/abi-bindings/go/teleporter/TeleporterMessenger"
+	teleporterregistry "github.com/ava-labs/icm-contracts/abi-bindings/go/teleporter/registry/TeleporterRegistry"
+	testmessenger "github.com/ava-labs/icm-contracts/abi-bindings/go/teleporter/tests/TestMessenger"
+	erc20tokenstakingmanager "github.com/ava-labs/icm-contracts/abi-bindings/go/validator-manager/ERC20TokenStakingManager"
+	examplerewardcalculator "github.com/ava-labs/icm-contracts/abi-bindings/go/validator-manager/ExampleRewardCalculator"
+	nativetokenstakingmanager "github.com/ava-labs/icm-contracts/abi-bindings/go/validator-manager/NativeTokenStakingManager"
+	poavalidatormanager "github.com/ava-labs/icm-contracts/abi-bindings/go/validator-manager/PoAValidatorManager"
+	iposvalidatormanager "github.com/ava-labs/icm-contracts/abi-bindings/go/validator-manager/interfaces/IPoSValidatorManager"
+	ivalidatormanager "github.com/ava-labs/icm-contracts/abi-bindings/go/validator-manager/interfaces/IValidatorManager"
+	"github.com/ava-labs/subnet-evm/accounts/abi"
+	"github.com/ava-labs/subnet-evm/accounts/abi/bind"
+	"github.com/ava-labs/subnet-evm/precompile/contracts/warp"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// panicSelector is the selector of the Panic(uint256) error raised by failed assertions,
+// arithmetic errors and other Solidity runtime checks.
+var panicSelector = []byte{0x4e, 0x48, 0x7b, 0x71}
+
+// bindingMetaData lists the ABI of every contract in abi-bindings/go, keyed by contract name.
+var bindingMetaData = map[string]*bind.MetaData{
+	"INativeMinter":                 inativeminter.INativeMinterMetaData,
+	"ProxyAdmin":                    proxyadmin.ProxyAdminMetaData,
+	"TransparentUpgradeableProxy":   transparentupgradeableproxy.TransparentUpgradeableProxyMetaData,
+	"ValidatorSetSig":               validatorsetsig.ValidatorSetSigMetaData,
+	"ERC20TokenHome":                erc20tokenhome.ERC20TokenHomeMetaData,
+	"ERC20TokenHomeUpgradeable":     erc20tokenhomeupgradeable.ERC20TokenHomeUpgradeableMetaData,
+	"NativeTokenHome":               nativetokenhome.NativeTokenHomeMetaData,
+	"NativeTokenHomeUpgradeable":    nativetokenhomeupgradeable.NativeTokenHomeUpgradeableMetaData,
+	"TokenHome":                     tokenhome.TokenHomeMetaData,
+	"ERC20TokenRemote":              erc20tokenremote.ERC20TokenRemoteMetaData,
+	"ERC20TokenRemoteUpgradeable":   erc20tokenremoteupgradeable.ERC20TokenRemoteUpgradeableMetaData,
+	"NativeTokenRemote":             nativetokenremote.NativeTokenRemoteMetaData,
+	"NativeTokenRemoteUpgradeable":  nativetokenremoteupgradeable.NativeTokenRemoteUpgradeableMetaData,
+	"TokenRemote":                   tokenremote.TokenRemoteMetaData,
+	"WrappedNativeToken":            wrappednativetoken.WrappedNativeTokenMetaData,
+	"ExampleERC20Decimals":          exampleerc20decimals.ExampleERC20DecimalsMetaData,
+	"MockERC20SendAndCallReceiver":  mockerc20sendandcallreceiver.MockERC20SendAndCallReceiverMetaData,
+	"MockNativeSendAndCallReceiver": mocknativesendandcallreceiver.MockNativeSendAndCallReceiverMetaData,
+	"ExampleERC20":                  exampleerc20.ExampleERC20MetaData,
+	"TeleporterMessenger":           teleportermessenger.TeleporterMessengerMetaData,
+	"TeleporterRegistry":            teleporterregistry.TeleporterRegistryMetaData,
+	"TestMessenger":                 testmessenger.TestMessengerMetaData,
+	"ERC20TokenStakingManager":      erc20tokenstakingmanager.ERC20TokenStakingManagerMetaData,
+	"ExampleRewardCalculator":       examplerewardcalculator.ExampleRewardCalculatorMetaData,
+	"NativeTokenStakingManager":     nativetokenstakingmanager.NativeTokenStakingManagerMetaData,
+	"PoAValidatorManager":           poavalidatormanager.PoAValidatorManagerMetaData,
+	"IPoSValidatorManager":          iposvalidatormanager.IPoSValidatorManagerMetaData,
+	"IValidatorManager":             ivalidatormanager.IValidatorManagerMetaData,
+}
+
+// callFrame is a call frame of the output of the callTracer.
+type callFrame struct {
+	Type    string          `json:"type"`
+	From    common.Address  `json:"from"`
+	To      *common.Address `json:"to,omitempty"`
+	Value   *hexutil.Big    `json:"value,omitempty"`
+	Gas     hexutil.Uint64  `json:"gas"`
+	GasUsed hexutil.Uint64  `json:"gasUsed"`
+	Input   hexutil.Bytes   `json:"input"`
+	Output  hexutil.Bytes   `json:"output,omitempty"`
+	Error   string          `json:"error,omitempty"`
+	Calls   []callFrame     `json:"calls,omitempty"`
+}
+
+// abiRegistry indexes the methods and custom errors of a set of ABIs by selector, and their events
+// by topic. Contracts sharing a selector share its signature, so the first contract in name order
+// is used. Events sharing a topic may differ in which arguments are indexed, so each distinct
+// layout is kept.
+type abiRegistry struct {
+	methods map[[4]byte]abi.Method
+	errors  map[[4]byte]abi.Error
+	events  map[common.Hash][]abi.Event
+}
+
+func newABIRegistry(abis map[string]*abi.ABI) *abiRegistry {
+	r := &abiRegistry{
+		methods: make(map[[4]byte]abi.Method),
+		errors:  make(map[[4]byte]abi.Error),
+		events:  make(map[common.Hash][]abi.Event),
+	}
+	names := make([]string, 0, len(abis))
+	for name := range abis {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, method := range abis[name].Methods {
+			selector := [4]byte(method.ID)
+			if _, ok := r.methods[selector]; !ok {
+				r.methods[selector] = method
+			}
+		}
+		for _, abiError := range abis[name].Errors {
+			selector := [4]byte(abiError.ID[:4])
+			if _, ok := r.errors[selector]; !ok {
+				r.errors[selector] = abiError
+			}
+		}
+		for _, event := range abis[name].Events {
+			if event.Anonymous || r.hasEventLayout(event) {
+				continue
+			}
+			r.events[event.ID] = append(r.events[event.ID], event)
+		}
+	}
+	return r
+}
+
+// hasEventLayout reports whether an event with the same topic and indexed arguments is registered.
+func (r *abiRegistry) hasEventLayout(event abi.Event) bool {
+	for _, registered := range r.events[event.ID] {
+		if len(registered.Inputs) != len(event.Inputs) {
+			continue
+		}
+		same := true
+		for i, input := range registered.Inputs {
+			same = same && input.Indexed == event.Inputs[i].Indexed
+		}
+		if same {
+			return true
+		}
+	}
+	return false
+}
+
+// newBindingABIRegistry returns a registry of every ABI in abi-bindings/go, along with the Warp
+// precompile's ABI.
+func newBindingABIRegistry() (*abiRegistry, error) {
+	abis := map[string]*abi.ABI{"WarpMessenger": &warp.WarpABI}
+	for name, metaData := range bindingMetaData {
+		parsed, err := metaData.GetAbi()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s ABI: %w", name, err)
+		}
+		abis[name] = parsed
+	}
+	return newABIRegistry(abis), nil
+}
+
+// decodeCallTree decodes a callTracer frame and its nested calls into a tree showing each call's
+// method, arguments, gas used and revert reason.
+func (r *abiRegistry) decodeCallTree(frame callFrame) *decodedTree {
+	name := frame.Type
+	if frame.To != nil {
+		name += " " + frame.To.Hex()
+	}
+	var (
+		method *abi.Method
+		args   []any
+	)
+	switch {
+	case len(frame.Input) == 0:
+		name += " (value transfer)"
+	case len(frame.Input) < 4:
+		name += " (fallback)"
+	default:
+		if m, ok := r.methods[[4]byte(frame.Input[:4])]; ok {
+			method = &m
+			name += " " + m.Name
+		} else {
+			name += " " + hexutil.Encode(frame.Input[:4]) + " (unknown method)"
+		}
+	}
+	tree := newDecodedTree(name)
+	tree.addField("From", frame.From.Hex())
+	if frame.Value != nil && frame.Value.ToInt().Sign() != 0 {
+		tree.addField("Value", frame.Value.ToInt())
+	}
+	tree.addField("Gas Used", uint64(frame.GasUsed))
+
+	if method != nil {
+		var err error
+		args, err = method.Inputs.Unpack(frame.Input[4:])
+		if err != nil {
+			tree.addField("Input", hexutil.Encode(frame.Input))
+		} else {
+			for i, arg := range method.Inputs {
+				tree.addField(argumentName(arg, i), formatABIValue(args[i]))
+			}
+		}
+	} else if len(frame.Input) > 0 {
+		tree.addField("Input", hexutil.Encode(frame.Input))
+	}
+
+	if frame.Error != "" {
+		tree.addField("Error", frame.Error)
+		if reason := r.decodeRevert(frame.Output); reason != "" {
+			tree.addField("Revert Reason", reason)
+		}
+	} else if method != nil && len(method.Outputs) > 0 {
+		if outputs, err := method.Outputs.Unpack(frame.Output); err == nil {
+			for i, arg := range method.Outputs {
+				tree.addField("Output "+argumentName(arg, i), formatABIValue(outputs[i]))
+			}
+		}
+	}
+
+	for _, call := range frame.Calls {
+		tree.addChild(r.decodeCallTree(call))
+	}
+	return tree
+}
+
+// decodeRevert decodes revert data as an Error(string) reason, a Panic(uint256) code or a custom error
+// of the registry's ABIs, falling back to hex.
+func (r *abiRegistry) decodeRevert(output []byte) string {
+	if len(output) < 4 {
+		return ""
+	}
+	if reason, err := abi.UnpackRevert(output); err == nil {
+		if bytes.Equal(output[:4], panicSelector) {
+			return "Panic: " + reason
+		}
+		return reason
+	}
+	abiError, ok := r.errors[[4]byte(output[:4])]
+	if !ok {
+		return hexutil.Encode(output)
+	}
+	values, err := abiError.Inputs.Unpack(output[4:])
+	if err != nil {
+		return hexutil.Encode(output)
+	}
+	formatted := make([]string, len(values))
+	for i, value := range values {
+		formatted[i] = formatABIValue(value)
+	}
+	return abiError.Name + "(" + strings.Join(formatted, ", ") + ")"
+}
+
+func argumentName(arg abi.Argument, i int) string {
+	if arg.Name == "" {
+		return fmt.Sprintf("arg%d", i)
+	}
+	return arg.Name
+}
+
+// formatABIValue formats a value unpacked from ABI encoded data, printing byte arrays as hex
+// rather than as lists of numbers.
+func formatABIValue(value any) string {
+	switch v := value.(type) {
+	case common.Address:
+		return v.Hex()
+	case *big.Int:
+		return v.String()
+	case []byte:
+		return hexutil.Encode(v)
+	case string:
+		return fmt.Sprintf("%q", v)
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Array, reflect.Slice:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, rv.Len())
+			reflect.Copy(reflect.ValueOf(b), rv)
+			return hexutil.Encode(b)
+		}
+		elems := make([]string, rv.Len())
+		for i := range elems {
+			elems[i] = formatABIValue(rv.Index(i).Interface())
+		}
+		return "[" + strings.Join(elems, ", ") + "]"
+	case reflect.Struct:
+		fields := make([]string, rv.NumField())
+		for i := range fields {
+			fields[i] = rv.Type().Field(i).Name + ": " + formatABIValue(rv.Field(i).Interface())
+		}
+		return "{" + strings.Join(fields, ", ") + "}"
+	default:
+		return fmt.Sprint(value)
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"math/big"
+	"testing"
+
+	poavalidatormanager "github.com/ava-labs/icm-contracts/abi-bindings/go/validator-manager/PoAValidatorManager"
+	"github.com/ava-labs/subnet-evm/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeCallTree(t *testing.T) {
+	registry, err := newBindingABIRegistry()
+	require.NoError(t, err)
+
+	managerABI, err := poavalidatormanager.PoAValidatorManagerMetaData.GetAbi()
+	require.NoError(t, err)
+	validationID := common.Hash{1}
+	resendInput, err := managerABI.Pack("resendRegisterValidatorMessage", validationID)
+	require.NoError(t, err)
+	statusError := managerABI.Errors["InvalidValidatorStatus"]
+	statusErrorArgs, err := statusError.Inputs.Pack(uint8(2))
+	require.NoError(t, err)
+	revertStringType, err := abi.NewType("string", "", nil)
+	require.NoError(t, err)
+	revertString, err := abi.Arguments{{Type: revertStringType}}.Pack("insufficient balance")
+	require.NoError(t, err)
+
+	manager := common.HexToAddress("0x0123456789abcdef0123456789abcdef01234567")
+	token := common.HexToAddress("0x0123456789abcdef0123456789abcdef01234568")
+	frame := callFrame{
+		Type:    "CALL",
+		From:    common.HexToAddress("0x0123456789abcdef0123456789abcdef01234569"),
+		To:      &manager,
+		GasUsed: 21_000,
+		Input:   resendInput,
+		Output:  append(statusError.ID[:4:4], statusErrorArgs...),
+		Error:   "execution reverted",
+		Calls: []callFrame{
+			{
+				Type:   "CALL",
+				From:   manager,
+				To:     &token,
+				Value:  (*hexutil.Big)(big.NewInt(5)),
+				Input:  []byte{0xde, 0xad, 0xbe, 0xef},
+				Output: append([]byte{0x08, 0xc3, 0x79, 0xa0}, revertString...),
+				Error:  "execution reverted",
+			},
+			{
+				Type: "CALL",
+				From: manager,
+				To:   &token,
+			},
+		},
+	}
+
+	tree := registry.decodeCallTree(frame)
+	require.Equal(t, "CALL "+manager.Hex()+" resendRegisterValidatorMessage", tree.Name)
+	require.Contains(t, tree.Fields, decodedField{Key: "validationID", Value: validationID.Hex()})
+	require.Contains(t, tree.Fields, decodedField{Key: "Gas Used", Value: "21000"})
+	require.Contains(t, tree.Fields, decodedField{Key: "Revert Reason", Value: "InvalidValidatorStatus(2)"})
+
+	require.Len(t, tree.Children, 2)
+	require.Equal(t, "CALL "+token.Hex()+" 0xdeadbeef (unknown method)", tree.Children[0].Name)
+	require.Contains(t, tree.Children[0].Fields, decodedField{Key: "Value", Value: "5"})
+	require.Contains(t, tree.Children[0].Fields, decodedField{Key: "Revert Reason", Value: "insufficient balance"})
+	require.Equal(t, "CALL "+token.Hex()+" (value transfer)", tree.Children[1].Name)
+}
+
+func TestFormatABIValue(t *testing.T) {
+	var tests = []struct {
+		name     string
+		value    any
+		expected string
+	}{
+		{
+			name:     "address",
+			value:    common.HexToAddress("0x0123456789abcdef0123456789abcdef01234567"),
+			expected: "0x0123456789abcDEF0123456789abCDef01234567",
+		},
+		{
+			name:     "bytes32",
+			value:    [4]byte{1, 2, 3, 4},
+			expected: "0x01020304",
+		},
+		{
+			name:     "bytes",
+			value:    []byte{0xab},
+			expected: "0xab",
+		},
+		{
+			name:     "uint256",
+			value:    big.NewInt(42),
+			expected: "42",
+		},
+		{
+			name: "struct",
+			value: struct {
+				Amount  *big.Int
+				Relayer []common.Address
+			}{big.NewInt(1), []common.Address{{}}},
+			expected: "{Amount: 1, Relayer: [0x0000000000000000000000000000000000000000]}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, formatABIValue(tt.value))
+		})
+	}
+}
@@ -0,0 +1,279 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/ava-labs/subnet-evm/accounts/abi"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/precompile/contracts/warp"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/spf13/cobra"
+)
+
+var (
+	errLogNoTopics        = errors.New("log has no topics")
+	errUnknownEvent       = errors.New("no known event matches the log's topic")
+	errEventTopicMismatch = errors.New("log topics do not match the event's indexed arguments")
+	errInvalidReceipt     = errors.New("invalid receipt")
+)
+
+var decodeCmd = &cobra.Command{
+	Use:   "decode",
+	Short: "Decodes archived receipts and logs without an RPC endpoint",
+	Long: `Commands that decode transaction receipts and logs from files, such as receipts archived
+for an incident review, without access to a node. Every log is decoded against the ABIs of
+every contract in abi-bindings/go and the Warp precompile, and logs that cannot be decoded are
+reported rather than aborting the command.`,
+	Args: cobra.NoArgs,
+}
+
+var decodeReceiptCmd = &cobra.Command{
+	Use:   "receipt FILE.json",
+	Short: "Decodes the logs of a transaction receipt file",
+	Long: `Given a file containing a transaction receipt, as returned by eth_getTransactionReceipt
+either on its own or wrapped in the JSON-RPC response, decodes each of its logs into the
+corresponding event of any known contract. The Warp messages of SendWarpMessage logs are
+decoded as well. Logs that cannot be decoded are listed at the end, with the reason.`,
+	Args: cobra.ExactArgs(1),
+	Run:  decodeReceiptRun,
+}
+
+var decodeLogsCmd = &cobra.Command{
+	Use:   "logs FILE.jsonl",
+	Short: "Decodes a file of logs, one JSON object per line",
+	Long: `Given a file with one log per line, in the format returned by eth_getLogs, decodes each log
+into the corresponding event of any known contract. Lines written by scan --output are accepted
+as well, in which case the raw log of each record is decoded. Logs that cannot be decoded,
+including lines that are not valid logs, are listed at the end with their line number.`,
+	Args: cobra.ExactArgs(1),
+	Run:  decodeLogsRun,
+}
+
+// archivedReceipt holds the fields of a transaction receipt needed to decode its logs. The logs
+// are kept raw so that a malformed log is reported without discarding the rest.
+type archivedReceipt struct {
+	TxHash common.Hash       `json:"transactionHash"`
+	Status *hexutil.Uint64   `json:"status"`
+	Logs   []json.RawMessage `json:"logs"`
+}
+
+// logEntry is a JSON encoded log, along with its index in a receipt or line number in a file.
+type logEntry struct {
+	Index int
+	Log   json.RawMessage
+}
+
+// undecodedLog is a log that could not be decoded, and why.
+type undecodedLog struct {
+	Index   int
+	Address *common.Address `json:",omitempty"`
+	Topic   *common.Hash    `json:",omitempty"`
+	Reason  string
+}
+
+func decodeReceiptRun(cmd *cobra.Command, args []string) {
+	b, err := os.ReadFile(args[0])
+	cobra.CheckErr(err)
+	receipt, err := parseArchivedReceipt(b)
+	cobra.CheckErr(err)
+	registry, err := newBindingABIRegistry()
+	cobra.CheckErr(err)
+
+	cmd.Println("Transaction Hash: " + receipt.TxHash.Hex())
+	if receipt.Status != nil {
+		cmd.Printf("Status: %d\n", uint64(*receipt.Status))
+	}
+	entries := make([]logEntry, len(receipt.Logs))
+	for i, log := range receipt.Logs {
+		entries[i] = logEntry{Index: i, Log: log}
+	}
+	printDecodedLogs(cmd, registry, entries)
+	cmd.Println("Decode receipt command ran successfully")
+}
+
+func decodeLogsRun(cmd *cobra.Command, args []string) {
+	b, err := os.ReadFile(args[0])
+	cobra.CheckErr(err)
+	registry, err := newBindingABIRegistry()
+	cobra.CheckErr(err)
+
+	printDecodedLogs(cmd, registry, parseLogLines(b))
+	cmd.Println("Decode logs command ran successfully")
+}
+
+func printDecodedLogs(cmd *cobra.Command, registry *abiRegistry, entries []logEntry) {
+	trees, undecoded := registry.decodeLogEntries(entries)
+	for _, tree := range trees {
+		cmd.Println(tree.String() + "\n")
+	}
+	if len(undecoded) > 0 {
+		undecodedJson, err := json.MarshalIndent(undecoded, "", "  ")
+		cobra.CheckErr(err)
+		cmd.Println("Undecodable Logs:\n" + string(undecodedJson) + "\n")
+	}
+	cmd.Printf("Decoded %d of %d logs\n", len(trees), len(entries))
+}
+
+// parseArchivedReceipt parses a receipt, either on its own or as the result of a JSON-RPC response.
+func parseArchivedReceipt(b []byte) (*archivedReceipt, error) {
+	var response struct {
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.Unmarshal(b, &response); err != nil {
+		return nil, fmt.Errorf("%w: %w", errInvalidReceipt, err)
+	}
+	if len(response.Result) > 0 {
+		if string(response.Result) == "null" {
+			return nil, fmt.Errorf("%w: the response has no result", errInvalidReceipt)
+		}
+		b = response.Result
+	}
+	var receipt archivedReceipt
+	if err := json.Unmarshal(b, &receipt); err != nil {
+		return nil, fmt.Errorf("%w: %w", errInvalidReceipt, err)
+	}
+	if receipt.Logs == nil {
+		return nil, fmt.Errorf("%w: missing logs", errInvalidReceipt)
+	}
+	return &receipt, nil
+}
+
+// parseLogLines splits a JSONL file into log entries indexed by line number, skipping blank lines.
+// Records written by scan --output are replaced by the raw log they were decoded from, which
+// Teleporter events encode as their log field.
+func parseLogLines(b []byte) []logEntry {
+	var entries []logEntry
+	for i, line := range bytes.Split(b, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		var record struct {
+			Decoded struct {
+				Log json.RawMessage
+				Raw json.RawMessage
+			}
+		}
+		if err := json.Unmarshal(line, &record); err == nil {
+			switch {
+			case len(record.Decoded.Log) > 0:
+				line = record.Decoded.Log
+			case len(record.Decoded.Raw) > 0:
+				line = record.Decoded.Raw
+			}
+		}
+		entries = append(entries, logEntry{Index: i + 1, Log: line})
+	}
+	return entries
+}
+
+// decodeLogEntries decodes each entry, returning the decoded logs in order along with the entries
+// that could not be decoded.
+func (r *abiRegistry) decodeLogEntries(entries []logEntry) ([]*decodedTree, []undecodedLog) {
+	var (
+		trees     []*decodedTree
+		undecoded []undecodedLog
+	)
+	for _, entry := range entries {
+		var log types.Log
+		if err := json.Unmarshal(entry.Log, &log); err != nil {
+			undecoded = append(undecoded, undecodedLog{Index: entry.Index, Reason: err.Error()})
+			continue
+		}
+		tree, err := r.decodeLog(&log)
+		if err != nil {
+			u := undecodedLog{Index: entry.Index, Address: &log.Address, Reason: err.Error()}
+			if len(log.Topics) > 0 {
+				u.Topic = &log.Topics[0]
+			}
+			undecoded = append(undecoded, u)
+			continue
+		}
+		trees = append(trees, tree)
+	}
+	return trees, undecoded
+}
+
+// decodeLog decodes a log into the first registered event whose topic and indexed arguments match.
+func (r *abiRegistry) decodeLog(log *types.Log) (*decodedTree, error) {
+	if len(log.Topics) == 0 {
+		return nil, errLogNoTopics
+	}
+	events := r.events[log.Topics[0]]
+	if len(events) == 0 {
+		return nil, fmt.Errorf("%w: %s", errUnknownEvent, log.Topics[0])
+	}
+	var err error
+	for _, event := range events {
+		var tree *decodedTree
+		if tree, err = decodeEventLog(event, log); err == nil {
+			return tree, nil
+		}
+	}
+	return nil, err
+}
+
+// decodeEventLog decodes a log as the given event, with its arguments in declaration order.
+func decodeEventLog(event abi.Event, log *types.Log) (*decodedTree, error) {
+	var indexed abi.Arguments
+	for i, input := range event.Inputs {
+		if input.Indexed {
+			input.Name = argumentName(input, i)
+			indexed = append(indexed, input)
+		}
+	}
+	if len(log.Topics) != len(indexed)+1 {
+		return nil, fmt.Errorf(
+			"%w: %s has %d indexed arguments, the log has %d topics",
+			errEventTopicMismatch,
+			event.Sig,
+			len(indexed),
+			len(log.Topics),
+		)
+	}
+	topicValues := make(map[string]any, len(indexed))
+	if err := abi.ParseTopicsIntoMap(topicValues, indexed, log.Topics[1:]); err != nil {
+		return nil, fmt.Errorf("failed to parse %s topics: %w", event.Name, err)
+	}
+	dataValues, err := event.Inputs.NonIndexed().Unpack(log.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unpack %s data: %w", event.Name, err)
+	}
+
+	tree := newDecodedTree(event.Name + " " + log.Address.Hex())
+	tree.addField("Event", event.Sig)
+	tree.addField("Block Number", log.BlockNumber)
+	tree.addField("Transaction Hash", log.TxHash.Hex())
+	tree.addField("Log Index", log.Index)
+	dataIndex := 0
+	for i, input := range event.Inputs {
+		name := argumentName(input, i)
+		if input.Indexed {
+			tree.addField(name, formatABIValue(topicValues[name]))
+			continue
+		}
+		tree.addField(name, formatABIValue(dataValues[dataIndex]))
+		dataIndex++
+	}
+
+	if event.Name == sendWarpMessageEvent && log.Address == common.HexToAddress(ICMPrecompileAddressHex) {
+		if unsignedMsg, err := warp.UnpackSendWarpEventDataToMessage(log.Data); err == nil {
+			tree.addChild(decodeUnsignedWarpMessage(unsignedMsg))
+		}
+	}
+	return tree, nil
+}
+
+func init() {
+	rootCmd.AddCommand(decodeCmd)
+	decodeCmd.AddCommand(decodeReceiptCmd)
+	decodeCmd.AddCommand(decodeLogsCmd)
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	avalancheWarp "github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/precompile/contracts/warp"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeCmd(t *testing.T) {
+	var tests = []struct {
+		name string
+		args []string
+		err  error
+		out  string
+	}{
+		{
+			name: "receipt no args",
+			args: []string{"decode", "receipt"},
+			err:  fmt.Errorf("accepts 1 arg(s), received 0"),
+		},
+		{
+			name: "logs no args",
+			args: []string{"decode", "logs"},
+			err:  fmt.Errorf("accepts 1 arg(s), received 0"),
+		},
+		{
+			name: "help",
+			args: []string{"decode", "receipt", "--help"},
+			err:  nil,
+			out:  "Given a file containing a transaction receipt, as returned by eth_getTransactionReceipt",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := executeTestCmd(t, rootCmd, tt.args...)
+			if tt.err != nil {
+				require.ErrorContains(t, err, tt.err.Error())
+			} else {
+				require.NoError(t, err)
+				require.Contains(t, out, tt.out)
+			}
+		})
+	}
+}
+
+func TestDecodeLogEntries(t *testing.T) {
+	registry, err := newBindingABIRegistry()
+	require.NoError(t, err)
+
+	from := common.HexToAddress("0x0000000000000000000000000000000000000001")
+	to := common.HexToAddress("0x0000000000000000000000000000000000000002")
+	transferTopic := crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+	transferLog := &types.Log{
+		Address: common.HexToAddress("0x0000000000000000000000000000000000000003"),
+		Topics:  []common.Hash{transferTopic, common.BytesToHash(from.Bytes()), common.BytesToHash(to.Bytes())},
+		Data:    common.BigToHash(big.NewInt(100)).Bytes(),
+	}
+
+	unsignedMsg, err := avalancheWarp.NewUnsignedMessage(1, ids.ID{2}, []byte{1, 2, 3})
+	require.NoError(t, err)
+	topics, data, err := warp.PackSendWarpMessageEvent(from, common.Hash(unsignedMsg.ID()), unsignedMsg.Bytes())
+	require.NoError(t, err)
+	warpLog := &types.Log{
+		Address: common.HexToAddress(ICMPrecompileAddressHex),
+		Topics:  topics,
+		Data:    data,
+	}
+
+	unknownLog := &types.Log{Address: to, Topics: []common.Hash{{1}}}
+	mismatchedLog := &types.Log{Address: to, Topics: []common.Hash{transferTopic}}
+
+	marshal := func(log *types.Log) json.RawMessage {
+		b, err := json.Marshal(log)
+		require.NoError(t, err)
+		return b
+	}
+	entries := []logEntry{
+		{Index: 0, Log: marshal(transferLog)},
+		{Index: 1, Log: marshal(warpLog)},
+		{Index: 2, Log: marshal(unknownLog)},
+		{Index: 3, Log: marshal(mismatchedLog)},
+		{Index: 4, Log: json.RawMessage(`{"address": "0x01"}`)},
+	}
+
+	trees, undecoded := registry.decodeLogEntries(entries)
+	require.Len(t, trees, 2)
+	require.Equal(t, "Transfer "+transferLog.Address.Hex(), trees[0].Name)
+	require.Contains(t, trees[0].Fields, decodedField{Key: "from", Value: from.Hex()})
+	require.Contains(t, trees[0].Fields, decodedField{Key: "value", Value: "100"})
+	require.Equal(t, "SendWarpMessage "+warpLog.Address.Hex(), trees[1].Name)
+	require.Len(t, trees[1].Children, 1)
+
+	require.Len(t, undecoded, 3)
+	require.Equal(t, 2, undecoded[0].Index)
+	require.Contains(t, undecoded[0].Reason, errUnknownEvent.Error())
+	require.Equal(t, 3, undecoded[1].Index)
+	require.Contains(t, undecoded[1].Reason, errEventTopicMismatch.Error())
+	require.Equal(t, 4, undecoded[2].Index)
+	require.Nil(t, undecoded[2].Address)
+}
+
+func TestParseArchivedReceipt(t *testing.T) {
+	receipt := `{"transactionHash": "0x0000000000000000000000000000000000000000000000000000000000000001",` +
+		`"status": "0x1", "logs": [{}]}`
+
+	var tests = []struct {
+		name string
+		file string
+		err  error
+	}{
+		{
+			name: "receipt",
+			file: receipt,
+		},
+		{
+			name: "json-rpc response",
+			file: `{"jsonrpc": "2.0", "id": 1, "result": ` + receipt + `}`,
+		},
+		{
+			name: "null result",
+			file: `{"jsonrpc": "2.0", "id": 1, "result": null}`,
+			err:  errInvalidReceipt,
+		},
+		{
+			name: "missing logs",
+			file: `{"transactionHash": "0x0000000000000000000000000000000000000000000000000000000000000001"}`,
+			err:  errInvalidReceipt,
+		},
+		{
+			name: "invalid json",
+			file: `{`,
+			err:  errInvalidReceipt,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, err := parseArchivedReceipt([]byte(tt.file))
+			require.ErrorIs(t, err, tt.err)
+			if tt.err == nil {
+				require.Equal(t, common.Hash{31: 1}, parsed.TxHash)
+				require.Len(t, parsed.Logs, 1)
+			}
+		})
+	}
+}
+
+func TestParseLogLines(t *testing.T) {
+	file := `{"address": "0x01"}
+
+{"Event": "SendCrossChainMessage", "Decoded": {"messageID": "0x02", "log": {"address": "0x03"}}}
+{"Event": "SendWarpMessage", "Decoded": {"MessageID": "0x04", "Raw": {"address": "0x05"}}}
+`
+	entries := parseLogLines([]byte(file))
+	require.Equal(t, []logEntry{
+		{Index: 1, Log: json.RawMessage(`{"address": "0x01"}`)},
+		{Index: 3, Log: json.RawMessage(`{"address": "0x03"}`)},
+		{Index: 4, Log: json.RawMessage(`{"address": "0x05"}`)},
+	}, entries)
+}
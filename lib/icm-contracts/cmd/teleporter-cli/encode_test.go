@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	teleportermessenger "github.com/ava-labs/icm-contracts/abi-bindings/go/teleporter/TeleporterMessenger"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeCmd(t *testing.T) {
+	var tests = []struct {
+		name string
+		args []string
+		err  error
+		out  string
+	}{
+		{
+			name: "too many args",
+			args: []string{"encode", "message", "a", "b"},
+			err:  fmt.Errorf("accepts at most 1 arg(s), received 2"),
+		},
+		{
+			name: "help",
+			args: []string{"encode", "message", "--help"},
+			err:  nil,
+			out:  "Given a JSON or YAML description of a TeleporterMessage",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := executeTestCmd(t, rootCmd, tt.args...)
+			if tt.err != nil {
+				require.ErrorContains(t, err, tt.err.Error())
+			} else {
+				require.NoError(t, err)
+				require.Contains(t, out, tt.out)
+			}
+		})
+	}
+}
+
+func TestDecodeTeleporterMessageDescription(t *testing.T) {
+	expected := teleportermessenger.TeleporterMessage{
+		MessageNonce:            big.NewInt(5),
+		OriginSenderAddress:     common.HexToAddress("0x0123456789abcdef0123456789abcdef01234567"),
+		DestinationBlockchainID: ids.ID{1, 2, 3, 4},
+		DestinationAddress:      common.HexToAddress("0x0123456789abcdef0123456789abcdef01234567"),
+		RequiredGasLimit:        big.NewInt(100_000),
+		AllowedRelayerAddresses: []common.Address{
+			common.HexToAddress("0x0123456789abcdef0123456789abcdef01234567"),
+		},
+		Receipts: []teleportermessenger.TeleporterMessageReceipt{
+			{
+				ReceivedMessageNonce: big.NewInt(1),
+				RelayerRewardAddress: common.HexToAddress("0x0123456789abcdef0123456789abcdef01234567"),
+			},
+		},
+		Message: []byte{1, 2, 3, 4},
+	}
+	expectedBytes, err := expected.Pack()
+	require.NoError(t, err)
+
+	yamlDescription := fmt.Sprintf(`messageNonce: 5
+originSenderAddress: "0x0123456789abcdef0123456789abcdef01234567"
+destinationBlockchainID: %s
+destinationAddress: "0x0123456789abcdef0123456789abcdef01234567"
+requiredGasLimit: "100000"
+allowedRelayerAddresses:
+  - "0x0123456789abcdef0123456789abcdef01234567"
+receipts:
+  - receivedMessageNonce: 1
+    relayerRewardAddress: "0x0123456789abcdef0123456789abcdef01234567"
+message: "0x01020304"
+`, ids.ID{1, 2, 3, 4})
+
+	var tests = []struct {
+		name        string
+		description string
+	}{
+		{
+			name:        "json",
+			description: expected.String(),
+		},
+		{
+			name:        "yaml",
+			description: yamlDescription,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := decodeTeleporterMessageDescription([]byte(tt.description))
+			require.NoError(t, err)
+			b, err := msg.Pack()
+			require.NoError(t, err)
+			require.Equal(t, expectedBytes, b)
+		})
+	}
+}
@@ -4,7 +4,10 @@
 package main
 
 import (
+	"fmt"
+
 	teleportermessenger "github.com/ava-labs/icm-contracts/abi-bindings/go/teleporter/TeleporterMessenger"
+	"github.com/ava-labs/subnet-evm/core/types"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
@@ -31,13 +34,14 @@ func eventRun(cmd *cobra.Command, args []string) {
 		topics = append(topics, common.HexToHash(topic))
 	}
 
-	event, err := teleporterABI.EventByID(topics[0])
-	cobra.CheckErr(err)
-
-	out, err := teleportermessenger.FilterTeleporterEvents(topics, data, event.Name)
+	event, out, err := teleportermessenger.ParseLog(types.Log{Topics: topics, Data: data})
 	cobra.CheckErr(err)
-	logger.Info("Parsed Teleporter event", zap.String("name", event.Name), zap.String("event", out.String()))
-	cmd.Println("Event command ran successfully for", event.Name)
+	logger.Info(
+		"Parsed Teleporter event",
+		zap.String("name", event.String()),
+		zap.String("event", out.(fmt.Stringer).String()),
+	)
+	cmd.Println("Event command ran successfully for", event.String())
 }
 
 func init() {
@@ -0,0 +1,135 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/ids"
+	teleportermessenger "github.com/ava-labs/icm-contracts/abi-bindings/go/teleporter/TeleporterMessenger"
+	"github.com/ava-labs/subnet-evm/accounts/abi/bind"
+	"github.com/ava-labs/subnet-evm/ethclient"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var (
+	feeRPC     string
+	feeAddress string
+	feeToken   string
+	feeAmount  string
+	feeSigner  signerFlags
+
+	errFeeZeroAmount    = errors.New("--amount must be greater than zero")
+	errFeeNotFound      = errors.New("message not found, it was never sent or its receipt was already received")
+	errFeeTokenMismatch = errors.New("fee token does not match the message's fee token")
+)
+
+var feeCmd = &cobra.Command{
+	Use:   "fee",
+	Short: "Manages the relayer fees of sent Teleporter messages",
+	Long:  `Commands for managing the relayer fees of Teleporter messages sent from a chain.`,
+	Args:  cobra.NoArgs,
+}
+
+var feeAddCmd = &cobra.Command{
+	Use: "add --rpc RPC_URL --teleporter-address CONTRACT_ADDRESS " +
+		"--token TOKEN_ADDRESS --amount AMOUNT MESSAGE_ID",
+	Short: "Adds to the relayer fee of a sent Teleporter message",
+	Long: `Given the ID of a Teleporter message sent from the chain served by --rpc, this command adds
+--amount of the fee token to the message's relayer fee. The fee token must be the same token the
+message was sent with, which the command checks before submitting. The TeleporterMessenger's
+allowance of the fee token is approved first if needed. Once the addFeeAmount transaction is
+accepted, the resulting AddFeeAmount event is printed.`,
+	Args: cobra.ExactArgs(1),
+	Run:  feeAddRun,
+}
+
+func feeAddRun(cmd *cobra.Command, args []string) {
+	messageID, err := parseHexID(args[0])
+	cobra.CheckErr(err)
+	address, err := parseAddress(feeAddress)
+	cobra.CheckErr(err)
+	token, err := parseAddress(feeToken)
+	cobra.CheckErr(err)
+	amount, err := parseAmount(feeAmount)
+	cobra.CheckErr(err)
+	if amount.Sign() == 0 {
+		cobra.CheckErr(errFeeZeroAmount)
+	}
+	key, err := feeSigner.load()
+	cobra.CheckErr(err)
+
+	ctx := context.Background()
+	c, err := ethclient.DialContext(ctx, feeRPC)
+	cobra.CheckErr(err)
+	defer c.Close()
+	messenger, err := teleportermessenger.NewTeleporterMessenger(address, c)
+	cobra.CheckErr(err)
+
+	cobra.CheckErr(checkFeeToken(ctx, messenger, messageID, token))
+
+	feeInfo := teleportermessenger.TeleporterFeeInfo{FeeTokenAddress: token, Amount: amount}
+	cobra.CheckErr(approveFeeToken(ctx, c, key, feeInfo, address))
+
+	opts, err := newTransactor(ctx, c, key)
+	cobra.CheckErr(err)
+	tx, err := messenger.AddFeeAmount(opts, messageID, token, amount)
+	cobra.CheckErr(err)
+	logger.Info(
+		"Adding fee amount",
+		zap.String("messageID", messageID.Hex()),
+		zap.String("token", token.Hex()),
+		zap.String("amount", amount.String()),
+	)
+	receipt, err := waitForSuccess(ctx, c, tx)
+	cobra.CheckErr(err)
+
+	printReceiptTeleporterLogs(cmd, receipt, address)
+	cmd.Println("Fee add command ran successfully")
+}
+
+// checkFeeToken checks that the message is still awaiting its receipt and was sent with token as
+// its fee token, since addFeeAmount reverts otherwise.
+func checkFeeToken(
+	ctx context.Context,
+	messenger *teleportermessenger.TeleporterMessenger,
+	messageID ids.ID,
+	token common.Address,
+) error {
+	callOpts := &bind.CallOpts{Context: ctx}
+	messageHash, err := messenger.GetMessageHash(callOpts, messageID)
+	if err != nil {
+		return fmt.Errorf("failed to get message hash: %w", err)
+	}
+	if messageHash == [32]byte{} {
+		return fmt.Errorf("%w: %s", errFeeNotFound, messageID.Hex())
+	}
+	currentToken, _, err := messenger.GetFeeInfo(callOpts, messageID)
+	if err != nil {
+		return fmt.Errorf("failed to get fee info: %w", err)
+	}
+	if currentToken != token {
+		return fmt.Errorf("%w: message pays its fee in %s", errFeeTokenMismatch, currentToken.Hex())
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(feeCmd)
+	feeCmd.AddCommand(feeAddCmd)
+	feeAddCmd.Flags().StringVar(&feeRPC, "rpc", "", "RPC endpoint of the message's source chain")
+	feeAddCmd.Flags().StringVarP(&feeAddress, "teleporter-address", "t", "", "Teleporter contract address")
+	feeAddCmd.Flags().StringVar(&feeToken, "token", "", "ERC20 token the message's fee is paid in")
+	feeAddCmd.Flags().StringVar(&feeAmount, "amount", "", "Fee amount to add, in the fee token's smallest unit")
+	addSignerFlags(feeAddCmd, &feeSigner)
+
+	for _, flag := range []string{"rpc", "teleporter-address", "token", "amount"} {
+		err := feeAddCmd.MarkFlagRequired(flag)
+		cobra.CheckErr(err)
+	}
+}
@@ -0,0 +1,342 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ava-labs/avalanchego/ids"
+	tokenhome "github.com/ava-labs/icm-contracts/abi-bindings/go/ictt/TokenHome/TokenHome"
+	nativetokenremote "github.com/ava-labs/icm-contracts/abi-bindings/go/ictt/TokenRemote/NativeTokenRemote"
+	tokenremote "github.com/ava-labs/icm-contracts/abi-bindings/go/ictt/TokenRemote/TokenRemote"
+	"github.com/ava-labs/subnet-evm/accounts/abi/bind"
+	"github.com/ava-labs/subnet-evm/ethclient"
+	"github.com/ava-labs/subnet-evm/vmerrs"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var (
+	icttRPC        string
+	icttHome       string
+	icttFromBlock  uint64
+	icttRemoteRPCs map[string]string
+)
+
+var icttCmd = &cobra.Command{
+	Use:   "ictt",
+	Short: "Inspects Interchain Token Transfer deployments",
+	Long:  `Commands for Interchain Token Transfer (ICTT) TokenHome and TokenRemote contracts.`,
+	Args:  cobra.NoArgs,
+}
+
+var icttInspectCmd = &cobra.Command{
+	Use:   "inspect --rpc RPC_URL --home CONTRACT_ADDRESS [--remote-rpc BLOCKCHAIN_ID=RPC_URL,...]",
+	Short: "Reports the remotes registered with a TokenHome and their settings",
+	Long: `Given a TokenHome contract on the chain served by --rpc, this command finds every TokenRemote
+registered with it from its RemoteRegistered events, and prints the settings the TokenHome holds
+for each remote: whether it is registered, the collateral still needed, the token multiplier
+and whether it is applied on the remote, along with the balance transferred to the remote and
+whether the remote is collateralized.
+
+Pass --remote-rpc with the RPC endpoint of a remote's blockchain to also query the TokenRemote
+contracts on it, which prints their initial reserve imbalance and collateralization, the total
+minted and total native asset supply of NativeTokenRemotes, and any setting that does not match
+the TokenHome's, such as a TokenRemote configured with another TokenHome. Remotes on blockchains
+without a --remote-rpc endpoint are queried through the network profile with their blockchain ID,
+if one is configured. --home may also name an ICTT contract of the --network profile.`,
+	Args: cobra.NoArgs,
+	Run:  icttInspectRun,
+}
+
+// icttHomeInfo is the state of a TokenHome and of the remotes registered with it.
+type icttHomeInfo struct {
+	Address              common.Address
+	BlockchainID         ids.ID
+	TokenAddress         common.Address
+	MinTeleporterVersion *big.Int
+	Remotes              []icttRemoteInfo
+}
+
+// icttRemoteInfo is a TokenRemote registered with a TokenHome, along with the settings the
+// TokenHome holds for it and, if its blockchain's RPC endpoint is known, its own state.
+type icttRemoteInfo struct {
+	BlockchainID       ids.ID
+	Address            common.Address
+	Registered         bool
+	CollateralNeeded   *big.Int
+	TokenMultiplier    *big.Int
+	MultiplyOnRemote   bool
+	TransferredBalance *big.Int
+	Collateralized     bool
+	Remote             *icttRemoteState `json:",omitempty"`
+}
+
+// icttRemoteState is the state reported by a TokenRemote contract itself.
+type icttRemoteState struct {
+	TokenHomeBlockchainID   ids.ID
+	TokenHomeAddress        common.Address
+	TokenMultiplier         *big.Int
+	MultiplyOnRemote        bool
+	InitialReserveImbalance *big.Int
+	IsCollateralized        bool
+	// TotalMinted and TotalNativeAssetSupply are only set for NativeTokenRemotes.
+	TotalMinted            *big.Int `json:",omitempty"`
+	TotalNativeAssetSupply *big.Int `json:",omitempty"`
+	Mismatches             []string `json:",omitempty"`
+}
+
+func icttInspectRun(cmd *cobra.Command, args []string) {
+	config, err := loadNetworksConfig()
+	cobra.CheckErr(err)
+	homeAddress, err := resolveICTTAddress(config, icttHome)
+	cobra.CheckErr(err)
+	remoteRPCs := make(map[ids.ID]string, len(icttRemoteRPCs))
+	for blockchainID, rpcURL := range icttRemoteRPCs {
+		id, err := parseBlockchainID(blockchainID)
+		cobra.CheckErr(err)
+		remoteRPCs[id] = rpcURL
+	}
+
+	ctx := context.Background()
+	c, err := ethclient.DialContext(ctx, icttRPC)
+	cobra.CheckErr(err)
+	defer c.Close()
+	home, err := tokenhome.NewTokenHome(homeAddress, c)
+	cobra.CheckErr(err)
+
+	info, err := inspectTokenHome(ctx, home, homeAddress)
+	cobra.CheckErr(err)
+	for i := range info.Remotes {
+		remote := &info.Remotes[i]
+		rpcURL, err := remoteRPC(ctx, config, remoteRPCs, remote.BlockchainID)
+		cobra.CheckErr(err)
+		if rpcURL == "" {
+			continue
+		}
+		remote.Remote, err = inspectTokenRemote(ctx, rpcURL, remote.Address)
+		cobra.CheckErr(err)
+		remote.Remote.Mismatches = checkRemoteWiring(info, *remote, *remote.Remote)
+		for _, mismatch := range remote.Remote.Mismatches {
+			logger.Info(
+				"TokenRemote does not match TokenHome",
+				zap.String("blockchainID", remote.BlockchainID.String()),
+				zap.String("remote", remote.Address.Hex()),
+				zap.String("mismatch", mismatch),
+			)
+		}
+	}
+
+	infoJson, err := json.MarshalIndent(info, "", "  ")
+	cobra.CheckErr(err)
+	cmd.Println("TokenHome:\n" + string(infoJson) + "\n")
+	cmd.Println("ICTT inspect command ran successfully")
+}
+
+// remoteRPC returns the RPC endpoint of a remote's blockchain, passed with --remote-rpc or
+// otherwise configured in the network profile with its blockchain ID. The endpoint is empty if
+// neither configures one.
+func remoteRPC(
+	ctx context.Context,
+	config *networksConfig,
+	remoteRPCs map[ids.ID]string,
+	blockchainID ids.ID,
+) (string, error) {
+	if rpcURL, ok := remoteRPCs[blockchainID]; ok {
+		return rpcURL, nil
+	}
+	_, profile, err := config.networkByBlockchainID(blockchainID)
+	if errors.Is(err, errNoNetworkForBlockchain) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if profile.RPC == "" {
+		return "", nil
+	}
+	if err := profile.checkChainID(ctx); err != nil {
+		return "", err
+	}
+	return profile.RPC, nil
+}
+
+// inspectTokenHome returns the state of the TokenHome and of each remote found in its
+// RemoteRegistered logs from --from-block onwards.
+func inspectTokenHome(
+	ctx context.Context,
+	home *tokenhome.TokenHome,
+	homeAddress common.Address,
+) (*icttHomeInfo, error) {
+	callOpts := &bind.CallOpts{Context: ctx}
+	blockchainID, err := home.GetBlockchainID(callOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blockchain ID: %w", err)
+	}
+	tokenAddress, err := home.GetTokenAddress(callOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token address: %w", err)
+	}
+	minTeleporterVersion, err := home.GetMinTeleporterVersion(callOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get minimum Teleporter version: %w", err)
+	}
+	info := &icttHomeInfo{
+		Address:              homeAddress,
+		BlockchainID:         blockchainID,
+		TokenAddress:         tokenAddress,
+		MinTeleporterVersion: minTeleporterVersion,
+		Remotes:              []icttRemoteInfo{},
+	}
+
+	it, err := home.FilterRemoteRegistered(&bind.FilterOpts{Start: icttFromBlock, Context: ctx}, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to filter RemoteRegistered logs: %w", err)
+	}
+	defer it.Close()
+	for it.Next() {
+		remoteBlockchainID := it.Event.RemoteBlockchainID
+		remoteAddress := it.Event.RemoteTokenTransferrerAddress
+		settings, err := home.GetRemoteTokenTransferrerSettings(callOpts, remoteBlockchainID, remoteAddress)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get remote settings: %w", err)
+		}
+		balance, err := home.GetTransferredBalance(callOpts, remoteBlockchainID, remoteAddress)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get transferred balance: %w", err)
+		}
+		info.Remotes = append(info.Remotes, icttRemoteInfo{
+			BlockchainID:       remoteBlockchainID,
+			Address:            remoteAddress,
+			Registered:         settings.Registered,
+			CollateralNeeded:   settings.CollateralNeeded,
+			TokenMultiplier:    settings.TokenMultiplier,
+			MultiplyOnRemote:   settings.MultiplyOnRemote,
+			TransferredBalance: balance,
+			Collateralized:     settings.Registered && settings.CollateralNeeded.Sign() == 0,
+		})
+	}
+	return info, it.Error()
+}
+
+// inspectTokenRemote returns the state of the TokenRemote at remoteAddress on the chain served by rpcURL.
+func inspectTokenRemote(ctx context.Context, rpcURL string, remoteAddress common.Address) (*icttRemoteState, error) {
+	c, err := ethclient.DialContext(ctx, rpcURL)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+	remote, err := tokenremote.NewTokenRemote(remoteAddress, c)
+	if err != nil {
+		return nil, err
+	}
+
+	callOpts := &bind.CallOpts{Context: ctx}
+	var state icttRemoteState
+	if state.TokenHomeBlockchainID, err = remote.GetTokenHomeBlockchainID(callOpts); err != nil {
+		return nil, fmt.Errorf("failed to get token home blockchain ID: %w", err)
+	}
+	if state.TokenHomeAddress, err = remote.GetTokenHomeAddress(callOpts); err != nil {
+		return nil, fmt.Errorf("failed to get token home address: %w", err)
+	}
+	if state.TokenMultiplier, err = remote.GetTokenMultiplier(callOpts); err != nil {
+		return nil, fmt.Errorf("failed to get token multiplier: %w", err)
+	}
+	if state.MultiplyOnRemote, err = remote.GetMultiplyOnRemote(callOpts); err != nil {
+		return nil, fmt.Errorf("failed to get multiply on remote: %w", err)
+	}
+	if state.InitialReserveImbalance, err = remote.GetInitialReserveImbalance(callOpts); err != nil {
+		return nil, fmt.Errorf("failed to get initial reserve imbalance: %w", err)
+	}
+	if state.IsCollateralized, err = remote.GetIsCollateralized(callOpts); err != nil {
+		return nil, fmt.Errorf("failed to get is collateralized: %w", err)
+	}
+
+	// Only NativeTokenRemotes track the total minted, so the calls revert for other remotes.
+	nativeRemote, err := nativetokenremote.NewNativeTokenRemote(remoteAddress, c)
+	if err != nil {
+		return nil, err
+	}
+	totalMinted, err := nativeRemote.GetTotalMinted(callOpts)
+	switch {
+	case err == nil:
+		state.TotalMinted = totalMinted
+	case isCallReverted(err):
+		logger.Info("TokenRemote is not a NativeTokenRemote", zap.String("remote", remoteAddress.Hex()))
+		return &state, nil
+	default:
+		return nil, fmt.Errorf("failed to get total minted: %w", err)
+	}
+	if state.TotalNativeAssetSupply, err = nativeRemote.TotalNativeAssetSupply(callOpts); err != nil {
+		return nil, fmt.Errorf("failed to get total native asset supply: %w", err)
+	}
+	return &state, nil
+}
+
+// isCallReverted reports whether err is the error of an eth_call that reverted, as opposed to one
+// that could not be made.
+func isCallReverted(err error) bool {
+	var dataErr interface{ ErrorData() interface{} }
+	return errors.As(err, &dataErr) || strings.Contains(err.Error(), vmerrs.ErrExecutionReverted.Error())
+}
+
+// checkRemoteWiring returns the settings of the TokenRemote that do not match those the TokenHome
+// holds for it.
+func checkRemoteWiring(home *icttHomeInfo, remote icttRemoteInfo, state icttRemoteState) []string {
+	var mismatches []string
+	if state.TokenHomeBlockchainID != home.BlockchainID {
+		mismatches = append(mismatches, fmt.Sprintf(
+			"token home blockchain ID is %s, expected %s", state.TokenHomeBlockchainID, home.BlockchainID,
+		))
+	}
+	if state.TokenHomeAddress != home.Address {
+		mismatches = append(mismatches, fmt.Sprintf(
+			"token home address is %s, expected %s", state.TokenHomeAddress.Hex(), home.Address.Hex(),
+		))
+	}
+	if !remote.Registered {
+		return mismatches
+	}
+	if state.TokenMultiplier.Cmp(remote.TokenMultiplier) != 0 {
+		mismatches = append(mismatches, fmt.Sprintf(
+			"token multiplier is %s, expected %s", state.TokenMultiplier, remote.TokenMultiplier,
+		))
+	}
+	if state.MultiplyOnRemote != remote.MultiplyOnRemote {
+		mismatches = append(mismatches, fmt.Sprintf(
+			"multiply on remote is %t, expected %t", state.MultiplyOnRemote, remote.MultiplyOnRemote,
+		))
+	}
+	return mismatches
+}
+
+func init() {
+	rootCmd.AddCommand(icttCmd)
+	icttCmd.AddCommand(icttInspectCmd)
+	icttInspectCmd.Flags().StringVar(&icttRPC, "rpc", "", "RPC endpoint of the TokenHome's chain")
+	icttInspectCmd.Flags().StringVar(
+		&icttHome,
+		"home",
+		"",
+		"TokenHome contract address, or its name in the network profile",
+	)
+	icttInspectCmd.Flags().Uint64Var(&icttFromBlock, "from-block", 0, "First block to scan for RemoteRegistered logs")
+	icttInspectCmd.Flags().StringToStringVar(
+		&icttRemoteRPCs,
+		"remote-rpc",
+		map[string]string{},
+		"RPC endpoints of remote blockchains, keyed by blockchain ID (CB58 or hex)",
+	)
+
+	for _, flag := range []string{"rpc", "home"} {
+		err := icttInspectCmd.MarkFlagRequired(flag)
+		cobra.CheckErr(err)
+	}
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestICTTInspectCmd(t *testing.T) {
+	var tests = []struct {
+		name string
+		args []string
+		err  error
+		out  string
+	}{
+		{
+			name: "no args",
+			args: []string{"ictt", "inspect"},
+			err:  fmt.Errorf(`required flag(s) "home", "rpc" not set`),
+		},
+		{
+			name: "help",
+			args: []string{"ictt", "inspect", "--help"},
+			err:  nil,
+			out:  "finds every TokenRemote",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := executeTestCmd(t, rootCmd, tt.args...)
+			if tt.err != nil {
+				require.ErrorContains(t, err, tt.err.Error())
+			} else {
+				require.NoError(t, err)
+				require.Contains(t, out, tt.out)
+			}
+		})
+	}
+}
+
+func TestRemoteRPC(t *testing.T) {
+	config := withNetworksConfig(t, "")
+	remoteRPCs := map[ids.ID]string{{31: 4}: "http://127.0.0.1:9650/ext/bc/remote/rpc"}
+
+	var tests = []struct {
+		name         string
+		blockchainID ids.ID
+		rpc          string
+		err          error
+	}{
+		{
+			name:         "flag",
+			blockchainID: ids.ID{31: 4},
+			rpc:          "http://127.0.0.1:9650/ext/bc/remote/rpc",
+		},
+		{
+			name:         "profile",
+			blockchainID: ids.ID{31: 2},
+			rpc:          "http://127.0.0.1:9650/ext/bc/destination/rpc",
+		},
+		{
+			name:         "profile without rpc",
+			blockchainID: ids.ID{31: 3},
+		},
+		{
+			name:         "not configured",
+			blockchainID: ids.ID{31: 5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rpc, err := remoteRPC(context.Background(), config, remoteRPCs, tt.blockchainID)
+			require.ErrorIs(t, err, tt.err)
+			require.Equal(t, tt.rpc, rpc)
+		})
+	}
+
+	t.Run("duplicate", func(t *testing.T) {
+		_, err := remoteRPC(context.Background(), config, nil, ids.ID{31: 4})
+		require.ErrorIs(t, err, errDuplicateBlockchainID)
+	})
+}
+
+func TestCheckRemoteWiring(t *testing.T) {
+	home := &icttHomeInfo{
+		Address:      common.HexToAddress("0x0123456789abcdef0123456789abcdef01234567"),
+		BlockchainID: ids.ID{1},
+	}
+	remote := icttRemoteInfo{
+		Registered:       true,
+		TokenMultiplier:  big.NewInt(1_000_000),
+		MultiplyOnRemote: true,
+	}
+	wired := icttRemoteState{
+		TokenHomeBlockchainID: home.BlockchainID,
+		TokenHomeAddress:      home.Address,
+		TokenMultiplier:       big.NewInt(1_000_000),
+		MultiplyOnRemote:      true,
+	}
+
+	var tests = []struct {
+		name       string
+		remote     icttRemoteInfo
+		modify     func(*icttRemoteState)
+		mismatches int
+	}{
+		{
+			name:   "wired",
+			remote: remote,
+			modify: func(*icttRemoteState) {},
+		},
+		{
+			name:   "other home",
+			remote: remote,
+			modify: func(s *icttRemoteState) {
+				s.TokenHomeBlockchainID = ids.ID{2}
+				s.TokenHomeAddress = common.Address{}
+			},
+			mismatches: 2,
+		},
+		{
+			name:   "multiplier mismatch",
+			remote: remote,
+			modify: func(s *icttRemoteState) {
+				s.TokenMultiplier = big.NewInt(1)
+				s.MultiplyOnRemote = false
+			},
+			mismatches: 2,
+		},
+		{
+			name:   "unregistered remote",
+			remote: icttRemoteInfo{TokenMultiplier: big.NewInt(0)},
+			modify: func(s *icttRemoteState) {
+				s.TokenMultiplier = big.NewInt(1)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := wired
+			tt.modify(&state)
+			require.Len(t, checkRemoteWiring(home, tt.remote, state), tt.mismatches)
+		})
+	}
+}
+
+func TestIsCallReverted(t *testing.T) {
+	require.True(t, isCallReverted(testDataError{data: "0x"}))
+	require.True(t, isCallReverted(fmt.Errorf("call failed: %w", errors.New("execution reverted"))))
+	require.False(t, isCallReverted(errors.New("connection refused")))
+}
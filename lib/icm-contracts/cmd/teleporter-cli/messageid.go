@@ -0,0 +1,271 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ava-labs/avalanchego/ids"
+	teleportermessenger "github.com/ava-labs/icm-contracts/abi-bindings/go/teleporter/TeleporterMessenger"
+	teleporterutils "github.com/ava-labs/icm-contracts/utils/teleporter-utils"
+	"github.com/ava-labs/subnet-evm/accounts/abi/bind"
+	"github.com/ava-labs/subnet-evm/ethclient"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+)
+
+var (
+	messageIDAddress     string
+	messageIDRPC         string
+	messageIDSource      string
+	messageIDDestination string
+	messageIDNonce       uint64
+
+	errMessageIDZeroNonce = errors.New("--nonce must be greater than zero")
+	errMessageIDNotFound  = errors.New("no nonce up to the current message nonce yields the message ID")
+)
+
+var messageIDCmd = &cobra.Command{
+	Use:   "message-id",
+	Short: "Computes, predicts and looks up Teleporter message IDs",
+	Long: `Commands for Teleporter message IDs, which are the hash of the TeleporterMessenger address, the
+source and destination blockchain IDs, and the message nonce. The nonce is shared by every
+message the TeleporterMessenger sends, whatever its destination. Blockchain IDs can be passed in
+CB58 or hex, or as the name of a network profile.`,
+	Args: cobra.NoArgs,
+}
+
+var messageIDComputeCmd = &cobra.Command{
+	Use:   "compute --teleporter-address CONTRACT_ADDRESS --source ID --destination ID --nonce N",
+	Short: "Computes the ID of a Teleporter message from its nonce",
+	Long: `Computes the ID of the message with nonce --nonce sent by the TeleporterMessenger on the
+blockchain --source to the blockchain --destination, without an RPC endpoint.`,
+	Args: cobra.NoArgs,
+	Run:  messageIDComputeRun,
+}
+
+var messageIDNextCmd = &cobra.Command{
+	Use:   "next --rpc RPC_URL --teleporter-address CONTRACT_ADDRESS --destination ID",
+	Short: "Predicts the ID of the next Teleporter message sent to a blockchain",
+	Long: `Queries the ID the next message sent by the TeleporterMessenger on the chain served by --rpc
+to the blockchain --destination will have, along with its nonce. Since the nonce is shared by
+every message the TeleporterMessenger sends, the prediction only holds if no other message is
+sent first.`,
+	Args: cobra.NoArgs,
+	Run:  messageIDNextRun,
+}
+
+var messageIDFindCmd = &cobra.Command{
+	Use:   "find --rpc RPC_URL --teleporter-address CONTRACT_ADDRESS [--source ID] --destination ID MESSAGE_ID",
+	Short: "Recovers the nonce of a Teleporter message ID and its sent message info",
+	Long: `Given a message ID sent by the TeleporterMessenger on the chain served by --rpc to the blockchain
+--destination, searches the nonces used so far, from the contract's current message nonce
+downwards, for the one that yields the message ID. The message's hash and fee info are then read
+from the contract. A message whose receipt was already returned no longer has either. --source
+defaults to the blockchain ID of the TeleporterMessenger.`,
+	Args: cobra.ExactArgs(1),
+	Run:  messageIDFindRun,
+}
+
+// messageIDInfo is a Teleporter message ID along with the values it is computed from.
+type messageIDInfo struct {
+	MessageID               ids.ID
+	SourceBlockchainID      ids.ID
+	DestinationBlockchainID ids.ID
+	Nonce                   *big.Int
+}
+
+// sentMessageLookup is a message ID recovered by the find command, with its sent message info.
+type sentMessageLookup struct {
+	messageIDInfo
+	MessageHash     common.Hash
+	FeeInfo         teleportermessenger.TeleporterFeeInfo
+	ReceiptReceived bool
+}
+
+func messageIDComputeRun(cmd *cobra.Command, args []string) {
+	address, err := parseAddress(messageIDAddress)
+	cobra.CheckErr(err)
+	sourceBlockchainID, err := parseBlockchainIDOrNetwork(messageIDSource)
+	cobra.CheckErr(err)
+	destinationBlockchainID, err := parseBlockchainIDOrNetwork(messageIDDestination)
+	cobra.CheckErr(err)
+	if messageIDNonce == 0 {
+		cobra.CheckErr(errMessageIDZeroNonce)
+	}
+
+	nonce := new(big.Int).SetUint64(messageIDNonce)
+	messageID, err := teleporterutils.CalculateMessageID(address, sourceBlockchainID, destinationBlockchainID, nonce)
+	cobra.CheckErr(err)
+	printMessageIDInfo(cmd, messageIDInfo{
+		MessageID:               messageID,
+		SourceBlockchainID:      sourceBlockchainID,
+		DestinationBlockchainID: destinationBlockchainID,
+		Nonce:                   nonce,
+	})
+	cmd.Println("Message-id compute command ran successfully")
+}
+
+func messageIDNextRun(cmd *cobra.Command, args []string) {
+	destinationBlockchainID, err := parseBlockchainIDOrNetwork(messageIDDestination)
+	cobra.CheckErr(err)
+	ctx := context.Background()
+	messenger, err := dialMessageIDMessenger(ctx)
+	cobra.CheckErr(err)
+
+	opts := &bind.CallOpts{Context: ctx}
+	sourceBlockchainID, err := messenger.BlockchainID(opts)
+	cobra.CheckErr(err)
+	messageNonce, err := messenger.MessageNonce(opts)
+	cobra.CheckErr(err)
+	messageID, err := messenger.GetNextMessageID(opts, destinationBlockchainID)
+	cobra.CheckErr(err)
+
+	printMessageIDInfo(cmd, messageIDInfo{
+		MessageID:               messageID,
+		SourceBlockchainID:      sourceBlockchainID,
+		DestinationBlockchainID: destinationBlockchainID,
+		Nonce:                   new(big.Int).Add(messageNonce, big.NewInt(1)),
+	})
+	cmd.Println("Message-id next command ran successfully")
+}
+
+func messageIDFindRun(cmd *cobra.Command, args []string) {
+	messageID, err := parseHexID(args[0])
+	cobra.CheckErr(err)
+	address, err := parseAddress(messageIDAddress)
+	cobra.CheckErr(err)
+	destinationBlockchainID, err := parseBlockchainIDOrNetwork(messageIDDestination)
+	cobra.CheckErr(err)
+	ctx := context.Background()
+	messenger, err := dialMessageIDMessenger(ctx)
+	cobra.CheckErr(err)
+
+	opts := &bind.CallOpts{Context: ctx}
+	var sourceBlockchainID ids.ID
+	if messageIDSource != "" {
+		sourceBlockchainID, err = parseBlockchainIDOrNetwork(messageIDSource)
+	} else {
+		sourceBlockchainID, err = messenger.BlockchainID(opts)
+	}
+	cobra.CheckErr(err)
+	messageNonce, err := messenger.MessageNonce(opts)
+	cobra.CheckErr(err)
+
+	nonce, err := findMessageNonce(address, sourceBlockchainID, destinationBlockchainID, messageID, messageNonce)
+	cobra.CheckErr(err)
+	info, err := messenger.SentMessageInfo(opts, messageID)
+	cobra.CheckErr(err)
+
+	lookup := sentMessageLookup{
+		messageIDInfo: messageIDInfo{
+			MessageID:               messageID,
+			SourceBlockchainID:      sourceBlockchainID,
+			DestinationBlockchainID: destinationBlockchainID,
+			Nonce:                   nonce,
+		},
+		MessageHash:     info.MessageHash,
+		FeeInfo:         info.FeeInfo,
+		ReceiptReceived: info.MessageHash == [32]byte{},
+	}
+	lookupJson, err := json.MarshalIndent(lookup, "", "  ")
+	cobra.CheckErr(err)
+	cmd.Println("Sent Message:\n" + string(lookupJson) + "\n")
+	cmd.Println("Message-id find command ran successfully")
+}
+
+func dialMessageIDMessenger(ctx context.Context) (*teleportermessenger.TeleporterMessenger, error) {
+	address, err := parseAddress(messageIDAddress)
+	if err != nil {
+		return nil, err
+	}
+	c, err := ethclient.DialContext(ctx, messageIDRPC)
+	if err != nil {
+		return nil, err
+	}
+	return teleportermessenger.NewTeleporterMessenger(address, c)
+}
+
+func printMessageIDInfo(cmd *cobra.Command, info messageIDInfo) {
+	infoJson, err := json.MarshalIndent(info, "", "  ")
+	cobra.CheckErr(err)
+	cmd.Println("Message ID:\n" + string(infoJson) + "\n")
+}
+
+// findMessageNonce searches the nonces from maxNonce down to 1 for the one that yields messageID.
+// Recent messages are the most likely to be looked up, so the search starts from the latest nonce.
+func findMessageNonce(
+	teleporterAddress common.Address,
+	sourceBlockchainID ids.ID,
+	destinationBlockchainID ids.ID,
+	messageID ids.ID,
+	maxNonce *big.Int,
+) (*big.Int, error) {
+	one := big.NewInt(1)
+	for nonce := new(big.Int).Set(maxNonce); nonce.Sign() > 0; nonce = new(big.Int).Sub(nonce, one) {
+		id, err := teleporterutils.CalculateMessageID(teleporterAddress, sourceBlockchainID, destinationBlockchainID, nonce)
+		if err != nil {
+			return nil, err
+		}
+		if id == messageID {
+			return nonce, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s (message nonce %s)", errMessageIDNotFound, messageID, maxNonce)
+}
+
+func init() {
+	rootCmd.AddCommand(messageIDCmd)
+	messageIDCmd.AddCommand(messageIDComputeCmd)
+	messageIDCmd.AddCommand(messageIDNextCmd)
+	messageIDCmd.AddCommand(messageIDFindCmd)
+	messageIDCmd.PersistentFlags().StringVarP(
+		&messageIDAddress,
+		"teleporter-address",
+		"t",
+		"",
+		"Teleporter contract address",
+	)
+	messageIDCmd.PersistentFlags().StringVar(
+		&messageIDDestination,
+		"destination",
+		"",
+		"Destination blockchain ID (CB58 or hex) or network name",
+	)
+
+	messageIDComputeCmd.Flags().StringVar(
+		&messageIDSource,
+		"source",
+		"",
+		"Source blockchain ID (CB58 or hex) or network name",
+	)
+	messageIDComputeCmd.Flags().Uint64Var(&messageIDNonce, "nonce", 0, "Message nonce")
+
+	messageIDNextCmd.Flags().StringVar(&messageIDRPC, "rpc", "", "RPC endpoint of the source chain")
+
+	messageIDFindCmd.Flags().StringVar(&messageIDRPC, "rpc", "", "RPC endpoint of the source chain")
+	messageIDFindCmd.Flags().StringVar(
+		&messageIDSource,
+		"source",
+		"",
+		"Source blockchain ID (CB58 or hex) or network name, defaults to the TeleporterMessenger's",
+	)
+
+	for _, flag := range []string{"teleporter-address", "destination"} {
+		err := messageIDCmd.MarkPersistentFlagRequired(flag)
+		cobra.CheckErr(err)
+	}
+	for _, flag := range []string{"source", "nonce"} {
+		err := messageIDComputeCmd.MarkFlagRequired(flag)
+		cobra.CheckErr(err)
+	}
+	err := messageIDNextCmd.MarkFlagRequired("rpc")
+	cobra.CheckErr(err)
+	err = messageIDFindCmd.MarkFlagRequired("rpc")
+	cobra.CheckErr(err)
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageIDComputeCmd(t *testing.T) {
+	var tests = []struct {
+		name string
+		args []string
+		err  error
+		out  string
+	}{
+		{
+			name: "no args",
+			args: []string{"message-id", "compute"},
+			err:  fmt.Errorf(`required flag(s) "destination", "nonce", "source", "teleporter-address" not set`),
+		},
+		{
+			name: "help",
+			args: []string{"message-id", "compute", "--help"},
+			err:  nil,
+			out:  "Computes the ID of the message with nonce --nonce sent by the TeleporterMessenger",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := executeTestCmd(t, rootCmd, tt.args...)
+			if tt.err != nil {
+				require.ErrorContains(t, err, tt.err.Error())
+			} else {
+				require.NoError(t, err)
+				require.Contains(t, out, tt.out)
+			}
+		})
+	}
+}
+
+func TestFindMessageNonce(t *testing.T) {
+	teleporterAddress := common.HexToAddress("0xfeabb3b3f4eeae6b5769507a5e6b808704e5c626")
+	sourceBlockchainID, err := ids.FromString("2D8RG4UpSXbPbvPCAWppNJyqTG2i2CAXSkTgmTBBvs7GKNZjsY")
+	require.NoError(t, err)
+	destinationBlockchainID, err := ids.FromString("yH8D7ThNJkxmtkuv2jgBa4P1Rn3Qpr4pPr7QYNfcdoS6k6HWp")
+	require.NoError(t, err)
+	messageID, err := ids.FromString("inHeK6nYc8uhUVPygnAUPN8Wk7PGPPQXHEd9ch8vyMUPxoaA7")
+	require.NoError(t, err)
+
+	var tests = []struct {
+		name     string
+		maxNonce int64
+		expected *big.Int
+		err      error
+	}{
+		{
+			name:     "found",
+			maxNonce: 10,
+			expected: big.NewInt(2),
+		},
+		{
+			name:     "found at max nonce",
+			maxNonce: 2,
+			expected: big.NewInt(2),
+		},
+		{
+			name:     "beyond max nonce",
+			maxNonce: 1,
+			err:      errMessageIDNotFound,
+		},
+		{
+			name:     "no messages sent",
+			maxNonce: 0,
+			err:      errMessageIDNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nonce, err := findMessageNonce(
+				teleporterAddress,
+				sourceBlockchainID,
+				destinationBlockchainID,
+				messageID,
+				big.NewInt(tt.maxNonce),
+			)
+			require.ErrorIs(t, err, tt.err)
+			require.Equal(t, tt.expected, nonce)
+		})
+	}
+}
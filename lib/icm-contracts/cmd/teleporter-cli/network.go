@@ -0,0 +1,313 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/subnet-evm/ethclient"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+	"sigs.k8s.io/yaml"
+)
+
+var (
+	networkName       string
+	networkConfigPath string
+
+	errUnknownNetwork         = errors.New("unknown network")
+	errNoNetworkForBlockchain = errors.New("no network configured for blockchain")
+	errDuplicateBlockchainID  = errors.New("blockchain ID configured for more than one network")
+	errNetworkMissingRPC      = errors.New("network has no RPC endpoint configured")
+	errUnknownICTTContract    = errors.New("unknown ICTT contract")
+	errChainIDMismatch        = errors.New("RPC endpoint chain ID does not match the network profile")
+)
+
+// networkProfile describes a chain the CLI interacts with, so that its endpoints and contract
+// addresses do not need to be passed on every invocation.
+type networkProfile struct {
+	RPC               string            `json:"rpc,omitempty"`
+	WS                string            `json:"ws,omitempty"`
+	BlockchainID      string            `json:"blockchain-id,omitempty"`
+	ChainID           uint64            `json:"chain-id,omitempty"`
+	TeleporterAddress string            `json:"teleporter-address,omitempty"`
+	RegistryAddress   string            `json:"registry-address,omitempty"`
+	ICTT              map[string]string `json:"ictt,omitempty"`
+}
+
+// networksConfig is the network profiles config file, in YAML or JSON, keyed by network name.
+type networksConfig struct {
+	Networks map[string]networkProfile `json:"networks"`
+}
+
+var networksCmd = &cobra.Command{
+	Use:   "networks",
+	Short: "Lists the configured network profiles",
+	Long: `Lists the network profiles of the config file passed with --config, which defaults to
+~/.teleporter-cli/networks.yaml. Each profile maps a network name to its RPC and WebSocket
+endpoints, blockchain ID, EVM chain ID, TeleporterMessenger and TeleporterRegistry addresses,
+and named ICTT contracts, for example:
+
+  networks:
+    fuji-c:
+      rpc: https://api.avax-test.network/ext/bc/C/rpc
+      blockchain-id: yH8D7ThNJkxmtkuv2jgBa4P1Rn3Qpr4pPr7QYNfcdoS6k6HWp
+      chain-id: 43113
+      teleporter-address: "0x253b2784c75e510dD0fF1da844684a1aC0aa5fcf"
+      ictt:
+        usdc-home: "0x..."
+
+Hex values should be quoted so that they are not read as YAML numbers.
+
+Pass --network NAME to any command to fill its --rpc, --ws, --source-rpc, --teleporter-address,
+--registry-address and --blockchain-id flags from the profile. Flags passed explicitly take precedence.
+Commands that follow a message to its destination chain, such as relay and status, find the
+destination chain's profile from the message's destination blockchain ID. When a profile sets
+chain-id, the chain ID served by its RPC endpoint is checked against it before the endpoint is used.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		config, err := loadNetworksConfig()
+		cobra.CheckErr(err)
+		names := make([]string, 0, len(config.Networks))
+		for name := range config.Networks {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			profileJson, err := json.MarshalIndent(config.Networks[name], "", "  ")
+			cobra.CheckErr(err)
+			cmd.Println(name + ":\n" + string(profileJson) + "\n")
+		}
+		cmd.Println("Networks command ran successfully")
+	},
+}
+
+// loadNetworksConfig reads the network profiles config file. A missing config file at the
+// default path yields an empty config, while a missing file passed with --config is an error.
+func loadNetworksConfig() (*networksConfig, error) {
+	path := networkConfigPath
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return &networksConfig{}, nil
+		}
+		path = filepath.Join(home, ".teleporter-cli", "networks.yaml")
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			return &networksConfig{}, nil
+		}
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var config networksConfig
+	if err := yaml.Unmarshal(b, &config); err != nil {
+		return nil, fmt.Errorf("invalid network config %s: %w", path, err)
+	}
+	return &config, nil
+}
+
+// network returns the profile of the named network.
+func (c *networksConfig) network(name string) (networkProfile, error) {
+	profile, ok := c.Networks[name]
+	if !ok {
+		return networkProfile{}, fmt.Errorf("%w: %s", errUnknownNetwork, name)
+	}
+	return profile, nil
+}
+
+// networkByBlockchainID returns the name and profile of the network with the given blockchain ID.
+func (c *networksConfig) networkByBlockchainID(blockchainID ids.ID) (string, networkProfile, error) {
+	var (
+		found   string
+		profile networkProfile
+	)
+	for name, p := range c.Networks {
+		if p.BlockchainID == "" {
+			continue
+		}
+		id, err := parseBlockchainID(p.BlockchainID)
+		if err != nil {
+			return "", networkProfile{}, fmt.Errorf("invalid blockchain ID of network %s: %w", name, err)
+		}
+		if id != blockchainID {
+			continue
+		}
+		if found != "" {
+			return "", networkProfile{}, fmt.Errorf("%w: %s (%s, %s)", errDuplicateBlockchainID, blockchainID, found, name)
+		}
+		found, profile = name, p
+	}
+	if found == "" {
+		return "", networkProfile{}, fmt.Errorf("%w: %s", errNoNetworkForBlockchain, blockchainID)
+	}
+	return found, profile, nil
+}
+
+// flagValues returns the values the profile provides for the CLI's common flags, keyed by flag name.
+func (p networkProfile) flagValues() map[string]string {
+	return map[string]string{
+		"rpc":                p.RPC,
+		"source-rpc":         p.RPC,
+		"ws":                 p.WS,
+		"teleporter-address": p.TeleporterAddress,
+		"registry-address":   p.RegistryAddress,
+		"blockchain-id":      p.BlockchainID,
+	}
+}
+
+// applyNetworkProfile fills the flags of cmd that were not passed explicitly from the profile of
+// the network passed with --network. It runs before required flags are validated.
+func applyNetworkProfile(cmd *cobra.Command) error {
+	if networkName == "" {
+		return nil
+	}
+	config, err := loadNetworksConfig()
+	if err != nil {
+		return err
+	}
+	profile, err := config.network(networkName)
+	if err != nil {
+		return err
+	}
+	usesRPC := false
+	for name, value := range profile.flagValues() {
+		flag := cmd.Flags().Lookup(name)
+		if flag == nil || flag.Changed || value == "" {
+			continue
+		}
+		if err := cmd.Flags().Set(name, value); err != nil {
+			return err
+		}
+		usesRPC = usesRPC || name == "rpc" || name == "source-rpc"
+	}
+	if !usesRPC {
+		return nil
+	}
+	return profile.checkChainID(cmd.Context())
+}
+
+// checkChainID checks that the profile's RPC endpoint serves the EVM chain ID the profile
+// configures, if it configures one, so that a stale profile does not send transactions to the
+// wrong chain.
+func (p networkProfile) checkChainID(ctx context.Context) error {
+	if p.ChainID == 0 || p.RPC == "" {
+		return nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	c, err := ethclient.DialContext(ctx, p.RPC)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	chainID, err := c.ChainID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get chain ID of %s: %w", p.RPC, err)
+	}
+	if !chainID.IsUint64() || chainID.Uint64() != p.ChainID {
+		return fmt.Errorf("%w: %s serves chain ID %s, expected %d", errChainIDMismatch, p.RPC, chainID, p.ChainID)
+	}
+	return nil
+}
+
+// resolveDestinationNetwork returns the profile of the network with the given blockchain ID, for
+// commands that follow a message to its destination chain when no destination endpoint was passed.
+func resolveDestinationNetwork(blockchainID ids.ID) (networkProfile, error) {
+	config, err := loadNetworksConfig()
+	if err != nil {
+		return networkProfile{}, err
+	}
+	name, profile, err := config.networkByBlockchainID(blockchainID)
+	if err != nil {
+		return networkProfile{}, err
+	}
+	logger.Info("Resolved destination network", zap.String("network", name))
+	return profile, nil
+}
+
+// resolveDestination returns the RPC endpoint and TeleporterMessenger address of a message's
+// destination chain. The endpoint is looked up by destination blockchain ID in the network
+// profiles unless destinationRPC is set, in which case the profile is not used. The address
+// defaults to the resolved profile's Teleporter address, and otherwise to sourceAddress.
+func resolveDestination(
+	destinationRPC string,
+	destinationAddress string,
+	destinationBlockchainID ids.ID,
+	sourceAddress common.Address,
+) (string, common.Address, error) {
+	if destinationRPC == "" {
+		profile, err := resolveDestinationNetwork(destinationBlockchainID)
+		if err != nil {
+			return "", common.Address{}, fmt.Errorf("failed to resolve the destination endpoint, "+
+				"pass --destination-rpc or add a network profile: %w", err)
+		}
+		if profile.RPC == "" {
+			return "", common.Address{}, fmt.Errorf("%w: %s", errNetworkMissingRPC, destinationBlockchainID)
+		}
+		if err := profile.checkChainID(context.Background()); err != nil {
+			return "", common.Address{}, err
+		}
+		destinationRPC = profile.RPC
+		if destinationAddress == "" {
+			destinationAddress = profile.TeleporterAddress
+		}
+	}
+	if destinationAddress == "" {
+		return destinationRPC, sourceAddress, nil
+	}
+	address, err := parseAddress(destinationAddress)
+	if err != nil {
+		return "", common.Address{}, err
+	}
+	return destinationRPC, address, nil
+}
+
+// parseBlockchainIDOrNetwork parses value as a blockchain ID, or otherwise looks up the blockchain ID
+// of the network profile it names.
+func parseBlockchainIDOrNetwork(value string) (ids.ID, error) {
+	blockchainID, err := parseBlockchainID(value)
+	if err == nil {
+		return blockchainID, nil
+	}
+	config, configErr := loadNetworksConfig()
+	if configErr != nil {
+		return ids.ID{}, configErr
+	}
+	profile, ok := config.Networks[value]
+	if !ok || profile.BlockchainID == "" {
+		return ids.ID{}, fmt.Errorf("%s is neither a blockchain ID nor a network with one: %w", value, err)
+	}
+	return parseBlockchainID(profile.BlockchainID)
+}
+
+// resolveICTTAddress parses value as a contract address, or otherwise looks it up by name in the
+// ICTT contracts of the network passed with --network.
+func resolveICTTAddress(config *networksConfig, value string) (common.Address, error) {
+	if common.IsHexAddress(value) || networkName == "" {
+		return parseAddress(value)
+	}
+	profile, err := config.network(networkName)
+	if err != nil {
+		return common.Address{}, err
+	}
+	address, ok := profile.ICTT[value]
+	if !ok {
+		return common.Address{}, fmt.Errorf("%w: %s on network %s", errUnknownICTTContract, value, networkName)
+	}
+	return parseAddress(address)
+}
+
+func init() {
+	rootCmd.AddCommand(networksCmd)
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+const testNetworksConfig = `networks:
+  source:
+    rpc: http://127.0.0.1:9650/ext/bc/source/rpc
+    blockchain-id: "0x0000000000000000000000000000000000000000000000000000000000000001"
+    teleporter-address: "0x253b2784c75e510dD0fF1da844684a1aC0aa5fcf"
+    ictt:
+      usdc-home: "0x0123456789abcDEF0123456789abCDef01234567"
+  destination:
+    rpc: http://127.0.0.1:9650/ext/bc/destination/rpc
+    blockchain-id: "0x0000000000000000000000000000000000000000000000000000000000000002"
+    teleporter-address: "0x0000000000000000000000000000000000000002"
+  no-rpc:
+    blockchain-id: "0x0000000000000000000000000000000000000000000000000000000000000003"
+  duplicate-a:
+    blockchain-id: "0x0000000000000000000000000000000000000000000000000000000000000004"
+  duplicate-b:
+    blockchain-id: "0x0000000000000000000000000000000000000000000000000000000000000004"
+`
+
+// withNetworksConfig points the CLI at a network profiles config file for the duration of the test.
+func withNetworksConfig(t *testing.T, network string) *networksConfig {
+	path := filepath.Join(t.TempDir(), "networks.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(testNetworksConfig), 0o600))
+	networkConfigPath, networkName = path, network
+	t.Cleanup(func() {
+		networkConfigPath, networkName = "", ""
+	})
+	config, err := loadNetworksConfig()
+	require.NoError(t, err)
+	return config
+}
+
+func TestNetworksCmd(t *testing.T) {
+	var tests = []struct {
+		name string
+		args []string
+		err  error
+		out  string
+	}{
+		{
+			name: "args",
+			args: []string{"networks", "source"},
+			err:  fmt.Errorf(`unknown command "source" for "teleporter-cli networks"`),
+		},
+		{
+			name: "help",
+			args: []string{"networks", "--help"},
+			err:  nil,
+			out:  "Lists the network profiles of the config file passed with --config",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := executeTestCmd(t, rootCmd, tt.args...)
+			if tt.err != nil {
+				require.ErrorContains(t, err, tt.err.Error())
+			} else {
+				require.NoError(t, err)
+				require.Contains(t, out, tt.out)
+			}
+		})
+	}
+}
+
+func TestNetworkByBlockchainID(t *testing.T) {
+	config := withNetworksConfig(t, "")
+
+	var tests = []struct {
+		name         string
+		blockchainID ids.ID
+		network      string
+		err          error
+	}{
+		{
+			name:         "found",
+			blockchainID: ids.ID{31: 2},
+			network:      "destination",
+		},
+		{
+			name:         "not configured",
+			blockchainID: ids.ID{31: 5},
+			err:          errNoNetworkForBlockchain,
+		},
+		{
+			name:         "duplicate",
+			blockchainID: ids.ID{31: 4},
+			err:          errDuplicateBlockchainID,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, _, err := config.networkByBlockchainID(tt.blockchainID)
+			require.ErrorIs(t, err, tt.err)
+			require.Equal(t, tt.network, name)
+		})
+	}
+}
+
+func TestApplyNetworkProfile(t *testing.T) {
+	withNetworksConfig(t, "source")
+
+	var rpc, address string
+	cmd := &cobra.Command{}
+	cmd.Flags().StringVar(&rpc, "rpc", "", "")
+	cmd.Flags().StringVar(&address, "teleporter-address", "", "")
+	require.NoError(t, cmd.Flags().Set("rpc", "http://explicit"))
+
+	require.NoError(t, applyNetworkProfile(cmd))
+	require.Equal(t, "http://explicit", rpc)
+	require.Equal(t, "0x253b2784c75e510dD0fF1da844684a1aC0aa5fcf", address)
+
+	networkName = "unknown"
+	require.ErrorIs(t, applyNetworkProfile(cmd), errUnknownNetwork)
+}
+
+func TestResolveDestination(t *testing.T) {
+	withNetworksConfig(t, "")
+	if logger == nil {
+		logger = logging.NoLog{}
+	}
+	sourceAddress := common.HexToAddress("0x0000000000000000000000000000000000000001")
+
+	var tests = []struct {
+		name               string
+		destinationRPC     string
+		destinationAddress string
+		blockchainID       ids.ID
+		expectedRPC        string
+		expectedAddress    common.Address
+		err                error
+	}{
+		{
+			name:            "explicit rpc",
+			destinationRPC:  "http://explicit",
+			blockchainID:    ids.ID{31: 2},
+			expectedRPC:     "http://explicit",
+			expectedAddress: sourceAddress,
+		},
+		{
+			name:            "resolved from profile",
+			blockchainID:    ids.ID{31: 2},
+			expectedRPC:     "http://127.0.0.1:9650/ext/bc/destination/rpc",
+			expectedAddress: common.HexToAddress("0x0000000000000000000000000000000000000002"),
+		},
+		{
+			name:               "explicit address",
+			destinationAddress: "0x0000000000000000000000000000000000000003",
+			blockchainID:       ids.ID{31: 2},
+			expectedRPC:        "http://127.0.0.1:9650/ext/bc/destination/rpc",
+			expectedAddress:    common.HexToAddress("0x0000000000000000000000000000000000000003"),
+		},
+		{
+			name:         "not configured",
+			blockchainID: ids.ID{31: 5},
+			err:          errNoNetworkForBlockchain,
+		},
+		{
+			name:         "no rpc",
+			blockchainID: ids.ID{31: 3},
+			err:          errNetworkMissingRPC,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rpc, address, err := resolveDestination(
+				tt.destinationRPC,
+				tt.destinationAddress,
+				tt.blockchainID,
+				sourceAddress,
+			)
+			require.ErrorIs(t, err, tt.err)
+			require.Equal(t, tt.expectedRPC, rpc)
+			require.Equal(t, tt.expectedAddress, address)
+		})
+	}
+}
+
+func TestResolveICTTAddress(t *testing.T) {
+	config := withNetworksConfig(t, "source")
+
+	address, err := resolveICTTAddress(config, "usdc-home")
+	require.NoError(t, err)
+	require.Equal(t, common.HexToAddress("0x0123456789abcDEF0123456789abCDef01234567"), address)
+
+	address, err = resolveICTTAddress(config, "0x0000000000000000000000000000000000000002")
+	require.NoError(t, err)
+	require.Equal(t, common.HexToAddress("0x0000000000000000000000000000000000000002"), address)
+
+	_, err = resolveICTTAddress(config, "unknown")
+	require.ErrorIs(t, err, errUnknownICTTContract)
+}
+
+func TestParseBlockchainIDOrNetwork(t *testing.T) {
+	withNetworksConfig(t, "")
+
+	blockchainID, err := parseBlockchainIDOrNetwork("destination")
+	require.NoError(t, err)
+	require.Equal(t, ids.ID{31: 2}, blockchainID)
+
+	blockchainID, err = parseBlockchainIDOrNetwork(ids.ID{31: 7}.String())
+	require.NoError(t, err)
+	require.Equal(t, ids.ID{31: 7}, blockchainID)
+
+	_, err = parseBlockchainIDOrNetwork("unknown")
+	require.Error(t, err)
+}
+
+func TestCheckChainID(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.Equal(t, "eth_chainId", req.Method)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"jsonrpc": "2.0", "id": ` + string(req.ID) + `, "result": "0xa869"}`))
+	}))
+	defer server.Close()
+
+	var tests = []struct {
+		name    string
+		profile networkProfile
+		err     error
+	}{
+		{
+			name:    "matching chain ID",
+			profile: networkProfile{RPC: server.URL, ChainID: 43113},
+		},
+		{
+			name:    "no chain ID configured",
+			profile: networkProfile{RPC: server.URL},
+		},
+		{
+			name:    "mismatched chain ID",
+			profile: networkProfile{RPC: server.URL, ChainID: 43114},
+			err:     errChainIDMismatch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorIs(t, tt.profile.checkChainID(context.Background()), tt.err)
+		})
+	}
+}
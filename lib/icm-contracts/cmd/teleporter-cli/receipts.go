@@ -0,0 +1,272 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ava-labs/avalanchego/ids"
+	teleportermessenger "github.com/ava-labs/icm-contracts/abi-bindings/go/teleporter/TeleporterMessenger"
+	teleporterutils "github.com/ava-labs/icm-contracts/utils/teleporter-utils"
+	"github.com/ava-labs/subnet-evm/accounts/abi/bind"
+	"github.com/ava-labs/subnet-evm/ethclient"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var (
+	receiptsRPC             string
+	receiptsAddress         string
+	receiptsDestination     string
+	receiptsBatchSize       int
+	receiptsFeeToken        string
+	receiptsFeeAmount       string
+	receiptsAllowedRelayers []string
+	receiptsSigner          signerFlags
+
+	errReceiptsBatchSize = errors.New("--batch-size must be positive")
+)
+
+var receiptsCmd = &cobra.Command{
+	Use:   "receipts",
+	Short: "Inspects and flushes the receipt queue of a Teleporter lane",
+	Long: `Commands for the queue of receipts a TeleporterMessenger holds for the messages it received from
+another chain. Receipts are returned to that chain attached to the next messages sent to it, and
+relayers are only credited their fees once the receipts are returned.`,
+	Args: cobra.NoArgs,
+}
+
+var receiptsListCmd = &cobra.Command{
+	Use:   "list --rpc RPC_URL --teleporter-address CONTRACT_ADDRESS --destination-blockchain-id ID",
+	Short: "Lists the receipts queued to be returned to a blockchain",
+	Long: `Lists the receipts queued by the TeleporterMessenger on the chain served by --rpc, to be returned
+to the blockchain passed with --destination-blockchain-id, which is the source blockchain of the
+messages the receipts are for. Each receipt is shown with the nonce and ID of the received message
+and the address credited with the relayer reward.`,
+	Args: cobra.NoArgs,
+	Run:  receiptsListRun,
+}
+
+var receiptsFlushCmd = &cobra.Command{
+	Use: "flush --rpc RPC_URL --teleporter-address CONTRACT_ADDRESS " +
+		"--destination-blockchain-id ID [MESSAGE_ID...]",
+	Short: "Returns queued receipts with sendSpecifiedReceipts",
+	Long: `Returns receipts to the blockchain passed with --destination-blockchain-id by sending
+sendSpecifiedReceipts messages. If message IDs are given, their receipts are sent in messages of
+at most --batch-size receipts each. Otherwise, the receipts of every message in the receipt queue
+are sent the same way. sendSpecifiedReceipts does not dequeue receipts, so they stay queued until
+the TeleporterMessenger attaches them, up to 5 at a time, to the next messages it sends, where
+they are ignored once already returned. An optional fee can be paid to the relayer of each message
+with --fee-token and --fee-amount, in which case the fee token is approved first if needed.`,
+	Args: cobra.ArbitraryArgs,
+	Run:  receiptsFlushRun,
+}
+
+// queuedReceipt is a receipt in the receipt queue, along with the ID of the message it is for.
+type queuedReceipt struct {
+	Index                int
+	MessageID            common.Hash
+	ReceivedMessageNonce *big.Int
+	RelayerRewardAddress common.Address
+}
+
+func receiptsListRun(cmd *cobra.Command, args []string) {
+	destinationBlockchainID, err := parseBlockchainID(receiptsDestination)
+	cobra.CheckErr(err)
+	address, err := parseAddress(receiptsAddress)
+	cobra.CheckErr(err)
+
+	ctx := context.Background()
+	c, err := ethclient.DialContext(ctx, receiptsRPC)
+	cobra.CheckErr(err)
+	defer c.Close()
+	messenger, err := teleportermessenger.NewTeleporterMessenger(address, c)
+	cobra.CheckErr(err)
+
+	receipts, err := readReceiptQueue(ctx, messenger, address, destinationBlockchainID)
+	cobra.CheckErr(err)
+	receiptsJson, err := json.MarshalIndent(receipts, "", "  ")
+	cobra.CheckErr(err)
+	cmd.Printf("Receipt Queue Size: %d\n", len(receipts))
+	cmd.Println("Queued Receipts:\n" + string(receiptsJson) + "\n")
+	cmd.Println("Receipts list command ran successfully")
+}
+
+func receiptsFlushRun(cmd *cobra.Command, args []string) {
+	destinationBlockchainID, err := parseBlockchainID(receiptsDestination)
+	cobra.CheckErr(err)
+	address, err := parseAddress(receiptsAddress)
+	cobra.CheckErr(err)
+	if receiptsBatchSize <= 0 {
+		cobra.CheckErr(errReceiptsBatchSize)
+	}
+	messageIDs := make([]ids.ID, len(args))
+	for i, arg := range args {
+		messageIDs[i], err = parseHexID(arg)
+		cobra.CheckErr(err)
+	}
+	feeInfo := teleportermessenger.TeleporterFeeInfo{Amount: new(big.Int)}
+	if receiptsFeeToken != "" {
+		feeInfo.FeeTokenAddress, err = parseAddress(receiptsFeeToken)
+		cobra.CheckErr(err)
+		feeInfo.Amount, err = parseAmount(receiptsFeeAmount)
+		cobra.CheckErr(err)
+	}
+	allowedRelayers := make([]common.Address, len(receiptsAllowedRelayers))
+	for i, relayer := range receiptsAllowedRelayers {
+		allowedRelayers[i], err = parseAddress(relayer)
+		cobra.CheckErr(err)
+	}
+	key, err := receiptsSigner.load()
+	cobra.CheckErr(err)
+
+	ctx := context.Background()
+	c, err := ethclient.DialContext(ctx, receiptsRPC)
+	cobra.CheckErr(err)
+	defer c.Close()
+	messenger, err := teleportermessenger.NewTeleporterMessenger(address, c)
+	cobra.CheckErr(err)
+
+	sendReceipts := func(batch [][32]byte) {
+		cobra.CheckErr(approveFeeToken(ctx, c, key, feeInfo, address))
+		opts, err := newTransactor(ctx, c, key)
+		cobra.CheckErr(err)
+		tx, err := messenger.SendSpecifiedReceipts(opts, destinationBlockchainID, batch, feeInfo, allowedRelayers)
+		cobra.CheckErr(err)
+		logger.Info("Sending receipts", zap.Int("specifiedReceipts", len(batch)))
+		receipt, err := waitForSuccess(ctx, c, tx)
+		cobra.CheckErr(err)
+		printReceiptTeleporterLogs(cmd, receipt, address)
+	}
+
+	if len(messageIDs) == 0 {
+		receipts, err := readReceiptQueue(ctx, messenger, address, destinationBlockchainID)
+		cobra.CheckErr(err)
+		messageIDs = queuedMessageIDs(receipts)
+		logger.Info("Flushing receipt queue", zap.Int("queuedReceipts", len(messageIDs)))
+	}
+	for _, batch := range batchMessageIDs(messageIDs, receiptsBatchSize) {
+		sendReceipts(batch)
+	}
+	cmd.Println("Receipts flush command ran successfully")
+}
+
+// readReceiptQueue returns the receipts queued to be returned to destinationBlockchainID, in queue order.
+func readReceiptQueue(
+	ctx context.Context,
+	messenger *teleportermessenger.TeleporterMessenger,
+	teleporterAddress common.Address,
+	destinationBlockchainID ids.ID,
+) ([]queuedReceipt, error) {
+	callOpts := &bind.CallOpts{Context: ctx}
+	size, err := messenger.GetReceiptQueueSize(callOpts, destinationBlockchainID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get receipt queue size: %w", err)
+	}
+	if size.Sign() == 0 {
+		return []queuedReceipt{}, nil
+	}
+	blockchainID, err := messenger.BlockchainID(callOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blockchain ID: %w", err)
+	}
+
+	receipts := make([]queuedReceipt, 0, size.Int64())
+	for i := int64(0); i < size.Int64(); i++ {
+		receipt, err := messenger.GetReceiptAtIndex(callOpts, destinationBlockchainID, big.NewInt(i))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get receipt at index %d: %w", i, err)
+		}
+		// The queued receipts are for messages sent from destinationBlockchainID to this chain.
+		messageID, err := teleporterutils.CalculateMessageID(
+			teleporterAddress,
+			destinationBlockchainID,
+			blockchainID,
+			receipt.ReceivedMessageNonce,
+		)
+		if err != nil {
+			return nil, err
+		}
+		receipts = append(receipts, queuedReceipt{
+			Index:                int(i),
+			MessageID:            common.Hash(messageID),
+			ReceivedMessageNonce: receipt.ReceivedMessageNonce,
+			RelayerRewardAddress: receipt.RelayerRewardAddress,
+		})
+	}
+	return receipts, nil
+}
+
+// queuedMessageIDs returns the IDs of the messages the queued receipts are for, in queue order.
+func queuedMessageIDs(receipts []queuedReceipt) []ids.ID {
+	messageIDs := make([]ids.ID, len(receipts))
+	for i, receipt := range receipts {
+		messageIDs[i] = ids.ID(receipt.MessageID)
+	}
+	return messageIDs
+}
+
+// batchMessageIDs splits messageIDs into batches of at most size IDs.
+func batchMessageIDs(messageIDs []ids.ID, size int) [][][32]byte {
+	var batches [][][32]byte
+	for start := 0; start < len(messageIDs); start += size {
+		end := min(start+size, len(messageIDs))
+		batch := make([][32]byte, 0, end-start)
+		for _, messageID := range messageIDs[start:end] {
+			batch = append(batch, messageID)
+		}
+		batches = append(batches, batch)
+	}
+	return batches
+}
+
+func init() {
+	rootCmd.AddCommand(receiptsCmd)
+	receiptsCmd.AddCommand(receiptsListCmd)
+	receiptsCmd.AddCommand(receiptsFlushCmd)
+	receiptsCmd.PersistentFlags().StringVar(&receiptsRPC, "rpc", "", "RPC endpoint to connect to the node")
+	receiptsCmd.PersistentFlags().StringVarP(
+		&receiptsAddress,
+		"teleporter-address",
+		"t",
+		"",
+		"Teleporter contract address",
+	)
+	receiptsCmd.PersistentFlags().StringVar(
+		&receiptsDestination,
+		"destination-blockchain-id",
+		"",
+		"Blockchain ID the receipts are returned to (CB58 or hex)",
+	)
+	receiptsFlushCmd.Flags().IntVar(
+		&receiptsBatchSize,
+		"batch-size",
+		teleportermessenger.MaximumReceiptCount,
+		"Number of specified receipts to send per message",
+	)
+	receiptsFlushCmd.Flags().StringVar(&receiptsFeeToken, "fee-token", "", "ERC20 token used to pay the relayer fee")
+	receiptsFlushCmd.Flags().StringVar(
+		&receiptsFeeAmount,
+		"fee-amount",
+		"0",
+		"Relayer fee amount per message, in the fee token's smallest unit",
+	)
+	receiptsFlushCmd.Flags().StringSliceVar(
+		&receiptsAllowedRelayers,
+		"allowed-relayers",
+		[]string{},
+		"Addresses allowed to relay the receipt messages",
+	)
+	addSignerFlags(receiptsFlushCmd, &receiptsSigner)
+
+	for _, flag := range []string{"rpc", "teleporter-address", "destination-blockchain-id"} {
+		err := receiptsCmd.MarkPersistentFlagRequired(flag)
+		cobra.CheckErr(err)
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	teleportermessenger "github.com/ava-labs/icm-contracts/abi-bindings/go/teleporter/TeleporterMessenger"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReceiptsListCmd(t *testing.T) {
+	var tests = []struct {
+		name string
+		args []string
+		err  error
+		out  string
+	}{
+		{
+			name: "no args",
+			args: []string{"receipts", "list"},
+			err: fmt.Errorf(
+				"required flag(s) \"destination-blockchain-id\", \"rpc\", \"teleporter-address\" not set",
+			),
+		},
+		{
+			name: "help",
+			args: []string{"receipts", "list", "--help"},
+			err:  nil,
+			out:  "Lists the receipts queued by the TeleporterMessenger on the chain served by --rpc",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := executeTestCmd(t, rootCmd, tt.args...)
+			if tt.err != nil {
+				require.ErrorContains(t, err, tt.err.Error())
+			} else {
+				require.NoError(t, err)
+				require.Contains(t, out, tt.out)
+			}
+		})
+	}
+}
+
+func TestBatchMessageIDs(t *testing.T) {
+	var tests = []struct {
+		name       string
+		messageIDs []ids.ID
+		size       int
+		expected   [][][32]byte
+	}{
+		{
+			name:       "no message IDs",
+			messageIDs: nil,
+			size:       5,
+			expected:   nil,
+		},
+		{
+			name:       "single batch",
+			messageIDs: []ids.ID{{1}, {2}},
+			size:       5,
+			expected:   [][][32]byte{{{1}, {2}}},
+		},
+		{
+			name:       "partial last batch",
+			messageIDs: []ids.ID{{1}, {2}, {3}},
+			size:       2,
+			expected:   [][][32]byte{{{1}, {2}}, {{3}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, batchMessageIDs(tt.messageIDs, tt.size))
+		})
+	}
+}
+
+func TestFlushQueuedReceipts(t *testing.T) {
+	receipts := make([]queuedReceipt, 7)
+	for i := range receipts {
+		receipts[i] = queuedReceipt{
+			Index:                i,
+			MessageID:            common.Hash{byte(i + 1)},
+			ReceivedMessageNonce: big.NewInt(int64(i + 1)),
+		}
+	}
+
+	// Flushing the queue specifies every queued receipt, in queue order.
+	require.Equal(
+		t,
+		[][][32]byte{{{1}, {2}, {3}, {4}, {5}}, {{6}, {7}}},
+		batchMessageIDs(queuedMessageIDs(receipts), teleportermessenger.MaximumReceiptCount),
+	)
+	// An empty queue sends no messages.
+	require.Nil(t, batchMessageIDs(queuedMessageIDs([]queuedReceipt{}), teleportermessenger.MaximumReceiptCount))
+}
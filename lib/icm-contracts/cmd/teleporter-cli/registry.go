@@ -0,0 +1,270 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	avalancheWarp "github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	teleporterregistry "github.com/ava-labs/icm-contracts/abi-bindings/go/teleporter/registry/TeleporterRegistry"
+	"github.com/ava-labs/subnet-evm/accounts/abi/bind"
+	"github.com/ava-labs/subnet-evm/ethclient"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/spf13/cobra"
+)
+
+// offChainMessagesKey is the chain config key listing the off-chain Warp messages the chain's
+// validators sign.
+const offChainMessagesKey = "warp-off-chain-messages"
+
+var (
+	registryAddress         string
+	registryRPC             string
+	registryFromBlock       uint64
+	registryVersion         uint64
+	registryProtocolAddress string
+	registryNetworkID       uint32
+	registryBlockchainID    string
+	registryChainConfig     string
+
+	errRegistryZeroVersion        = errors.New("--version must be greater than zero")
+	errRegistryZeroAddress        = errors.New("--protocol-address must not be the zero address")
+	errRegistryInvalidChainConfig = errors.New("invalid chain config")
+)
+
+var registryCmd = &cobra.Command{
+	Use:   "registry",
+	Short: "Inspects a TeleporterRegistry and builds its off-chain upgrade messages",
+	Long: `Commands for the TeleporterRegistry contract, which maps protocol versions to
+TeleporterMessenger addresses. New versions are registered with off-chain Warp messages
+signed by the validators of the registry's chain.`,
+	Args: cobra.NoArgs,
+}
+
+var registryVersionsCmd = &cobra.Command{
+	Use:   "versions --rpc RPC_URL --registry-address CONTRACT_ADDRESS [--from-block N]",
+	Short: "Lists the protocol versions registered with a TeleporterRegistry",
+	Long: `Lists every protocol version registered with the TeleporterRegistry on the chain served by
+--rpc, from version 1 up to its latest version, along with the address each version maps to.
+The history of AddProtocolVersion events from --from-block onwards is printed as well, with the
+block and transaction that registered each version.`,
+	Args: cobra.NoArgs,
+	Run:  registryVersionsRun,
+}
+
+var registryBuildOffChainCmd = &cobra.Command{
+	Use: "build-offchain --registry-address CONTRACT_ADDRESS --version N --protocol-address ADDRESS " +
+		"--network-id ID --blockchain-id ID [--chain-config FILE]",
+	Short: "Builds the off-chain Warp message registering a new protocol version",
+	Long: `Builds the unsigned Warp message that registers --protocol-address as protocol version --version
+with the TeleporterRegistry on the blockchain passed with --blockchain-id, and prints it along
+with the chain config adding it to the chain's off-chain Warp messages. Once the chain's
+validators run with that config, the message can be signed and delivered with the registry's
+addProtocolVersion function. Pass --chain-config to add the message to an existing chain
+config file, keeping its other settings and off-chain messages.`,
+	Args: cobra.NoArgs,
+	Run:  registryBuildOffChainRun,
+}
+
+// protocolVersion is a protocol version of a TeleporterRegistry and the address it maps to.
+type protocolVersion struct {
+	Version         *big.Int
+	ProtocolAddress common.Address
+}
+
+// protocolVersionEvent is an AddProtocolVersion event of a TeleporterRegistry.
+type protocolVersionEvent struct {
+	Version         *big.Int
+	ProtocolAddress common.Address
+	BlockNumber     uint64
+	TxHash          common.Hash
+}
+
+func registryVersionsRun(cmd *cobra.Command, args []string) {
+	address, err := parseAddress(registryAddress)
+	cobra.CheckErr(err)
+
+	ctx := context.Background()
+	c, err := ethclient.DialContext(ctx, registryRPC)
+	cobra.CheckErr(err)
+	defer c.Close()
+	registry, err := teleporterregistry.NewTeleporterRegistry(address, c)
+	cobra.CheckErr(err)
+
+	latestVersion, err := registry.LatestVersion(&bind.CallOpts{Context: ctx})
+	cobra.CheckErr(err)
+	versions, err := registryVersions(ctx, registry, latestVersion)
+	cobra.CheckErr(err)
+	events, err := protocolVersionEvents(ctx, registry)
+	cobra.CheckErr(err)
+
+	versionsJson, err := json.MarshalIndent(versions, "", "  ")
+	cobra.CheckErr(err)
+	eventsJson, err := json.MarshalIndent(events, "", "  ")
+	cobra.CheckErr(err)
+	cmd.Printf("Latest Version: %s\n", latestVersion)
+	cmd.Println("Protocol Versions:\n" + string(versionsJson) + "\n")
+	cmd.Println("AddProtocolVersion Events:\n" + string(eventsJson) + "\n")
+	cmd.Println("Registry versions command ran successfully")
+}
+
+func registryBuildOffChainRun(cmd *cobra.Command, args []string) {
+	address, err := parseAddress(registryAddress)
+	cobra.CheckErr(err)
+	blockchainID, err := parseBlockchainID(registryBlockchainID)
+	cobra.CheckErr(err)
+	protocolAddress, err := parseAddress(registryProtocolAddress)
+	cobra.CheckErr(err)
+	if protocolAddress == (common.Address{}) {
+		cobra.CheckErr(errRegistryZeroAddress)
+	}
+	if registryVersion == 0 {
+		cobra.CheckErr(errRegistryZeroVersion)
+	}
+
+	var baseChainConfig []byte
+	if registryChainConfig != "" {
+		baseChainConfig, err = os.ReadFile(registryChainConfig)
+		cobra.CheckErr(err)
+	}
+
+	unsignedMessage, err := teleporterregistry.NewOffChainRegistryMessage(
+		registryNetworkID,
+		blockchainID,
+		address,
+		teleporterregistry.ProtocolRegistryEntry{
+			Version:         new(big.Int).SetUint64(registryVersion),
+			ProtocolAddress: protocolAddress,
+		},
+	)
+	cobra.CheckErr(err)
+	chainConfig, err := addOffChainMessage(baseChainConfig, unsignedMessage)
+	cobra.CheckErr(err)
+
+	cmd.Println("Warp Message ID: " + unsignedMessage.ID().String())
+	cmd.Println("Unsigned Warp Message: " + hexutil.Encode(unsignedMessage.Bytes()))
+	cmd.Println(decodeUnsignedWarpMessage(unsignedMessage).String())
+	cmd.Println("Chain Config:\n" + string(chainConfig) + "\n")
+	cmd.Println("Registry build-offchain command ran successfully")
+}
+
+// registryVersions returns the address of each version from 1 to latestVersion. Versions may be
+// registered out of order, so versions that are not registered are skipped.
+func registryVersions(
+	ctx context.Context,
+	registry *teleporterregistry.TeleporterRegistry,
+	latestVersion *big.Int,
+) ([]protocolVersion, error) {
+	versions := []protocolVersion{}
+	for v := big.NewInt(1); v.Cmp(latestVersion) <= 0; v = new(big.Int).Add(v, big.NewInt(1)) {
+		protocolAddress, err := registry.GetAddressFromVersion(&bind.CallOpts{Context: ctx}, v)
+		if err != nil {
+			if strings.Contains(err.Error(), "TeleporterRegistry: version not found") {
+				continue
+			}
+			return nil, fmt.Errorf("failed to get address of version %s: %w", v, err)
+		}
+		versions = append(versions, protocolVersion{Version: v, ProtocolAddress: protocolAddress})
+	}
+	return versions, nil
+}
+
+// protocolVersionEvents returns the AddProtocolVersion events of the registry from --from-block onwards.
+func protocolVersionEvents(
+	ctx context.Context,
+	registry *teleporterregistry.TeleporterRegistry,
+) ([]protocolVersionEvent, error) {
+	it, err := registry.FilterAddProtocolVersion(&bind.FilterOpts{Start: registryFromBlock, Context: ctx}, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to filter AddProtocolVersion logs: %w", err)
+	}
+	defer it.Close()
+	events := []protocolVersionEvent{}
+	for it.Next() {
+		events = append(events, protocolVersionEvent{
+			Version:         it.Event.Version,
+			ProtocolAddress: it.Event.ProtocolAddress,
+			BlockNumber:     it.Event.Raw.BlockNumber,
+			TxHash:          it.Event.Raw.TxHash,
+		})
+	}
+	return events, it.Error()
+}
+
+// addOffChainMessage adds the hex encoded message to the off-chain Warp messages of the JSON chain
+// config, keeping its other settings and messages. An empty chain config yields a config with only
+// the off-chain Warp messages set.
+func addOffChainMessage(chainConfig []byte, message *avalancheWarp.UnsignedMessage) ([]byte, error) {
+	config := map[string]any{}
+	if len(chainConfig) > 0 {
+		if err := json.Unmarshal(chainConfig, &config); err != nil {
+			return nil, fmt.Errorf("%w: %w", errRegistryInvalidChainConfig, err)
+		}
+	}
+	messages := []any{}
+	if existing, ok := config[offChainMessagesKey]; ok {
+		if messages, ok = existing.([]any); !ok {
+			return nil, fmt.Errorf("%w: %s must be a list", errRegistryInvalidChainConfig, offChainMessagesKey)
+		}
+	}
+	config[offChainMessagesKey] = append(messages, hexutil.Encode(message.Bytes()))
+	return json.MarshalIndent(config, "", "  ")
+}
+
+func init() {
+	rootCmd.AddCommand(registryCmd)
+	registryCmd.AddCommand(registryVersionsCmd)
+	registryCmd.AddCommand(registryBuildOffChainCmd)
+	registryCmd.PersistentFlags().StringVar(
+		&registryAddress,
+		"registry-address",
+		"",
+		"TeleporterRegistry contract address",
+	)
+
+	registryVersionsCmd.Flags().StringVar(&registryRPC, "rpc", "", "RPC endpoint to connect to the node")
+	registryVersionsCmd.Flags().Uint64Var(
+		&registryFromBlock,
+		"from-block",
+		0,
+		"First block to scan for AddProtocolVersion logs",
+	)
+
+	registryBuildOffChainCmd.Flags().Uint64Var(&registryVersion, "version", 0, "Protocol version to register")
+	registryBuildOffChainCmd.Flags().StringVar(
+		&registryProtocolAddress,
+		"protocol-address",
+		"",
+		"TeleporterMessenger address to register the version for",
+	)
+	registryBuildOffChainCmd.Flags().Uint32Var(&registryNetworkID, "network-id", 0, "Avalanche network ID")
+	registryBuildOffChainCmd.Flags().StringVar(
+		&registryBlockchainID,
+		"blockchain-id",
+		"",
+		"Blockchain ID of the registry's chain (CB58 or hex)",
+	)
+	registryBuildOffChainCmd.Flags().StringVar(
+		&registryChainConfig,
+		"chain-config",
+		"",
+		"Existing chain config file to add the message to",
+	)
+
+	err := registryCmd.MarkPersistentFlagRequired("registry-address")
+	cobra.CheckErr(err)
+	err = registryVersionsCmd.MarkFlagRequired("rpc")
+	cobra.CheckErr(err)
+	for _, flag := range []string{"version", "protocol-address", "network-id", "blockchain-id"} {
+		err := registryBuildOffChainCmd.MarkFlagRequired(flag)
+		cobra.CheckErr(err)
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	teleporterregistry "github.com/ava-labs/icm-contracts/abi-bindings/go/teleporter/registry/TeleporterRegistry"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistryVersionsCmd(t *testing.T) {
+	var tests = []struct {
+		name string
+		args []string
+		err  error
+		out  string
+	}{
+		{
+			name: "no args",
+			args: []string{"registry", "versions"},
+			err:  fmt.Errorf(`required flag(s) "registry-address", "rpc" not set`),
+		},
+		{
+			name: "help",
+			args: []string{"registry", "versions", "--help"},
+			err:  nil,
+			out:  "Lists every protocol version registered with the TeleporterRegistry",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := executeTestCmd(t, rootCmd, tt.args...)
+			if tt.err != nil {
+				require.ErrorContains(t, err, tt.err.Error())
+			} else {
+				require.NoError(t, err)
+				require.Contains(t, out, tt.out)
+			}
+		})
+	}
+}
+
+func TestAddOffChainMessage(t *testing.T) {
+	message, err := teleporterregistry.NewOffChainRegistryMessage(
+		1337,
+		ids.ID{1},
+		common.HexToAddress("0x0123456789abcdef0123456789abcdef01234567"),
+		teleporterregistry.ProtocolRegistryEntry{
+			Version:         big.NewInt(2),
+			ProtocolAddress: common.HexToAddress("0x0123456789abcdef0123456789abcdef01234568"),
+		},
+	)
+	require.NoError(t, err)
+	messageHex := hexutil.Encode(message.Bytes())
+
+	var tests = []struct {
+		name        string
+		chainConfig string
+		expected    map[string]any
+		err         error
+	}{
+		{
+			name:     "empty config",
+			expected: map[string]any{offChainMessagesKey: []any{messageHex}},
+		},
+		{
+			name:        "existing settings",
+			chainConfig: `{"warp-api-enabled": true}`,
+			expected: map[string]any{
+				"warp-api-enabled":  true,
+				offChainMessagesKey: []any{messageHex},
+			},
+		},
+		{
+			name:        "existing messages",
+			chainConfig: `{"warp-off-chain-messages": ["0x01"]}`,
+			expected:    map[string]any{offChainMessagesKey: []any{"0x01", messageHex}},
+		},
+		{
+			name:        "malformed messages",
+			chainConfig: `{"warp-off-chain-messages": "0x01"}`,
+			err:         errRegistryInvalidChainConfig,
+		},
+		{
+			name:        "malformed config",
+			chainConfig: `[]`,
+			err:         errRegistryInvalidChainConfig,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chainConfig, err := addOffChainMessage([]byte(tt.chainConfig), message)
+			require.ErrorIs(t, err, tt.err)
+			if tt.err != nil {
+				return
+			}
+			var config map[string]any
+			require.NoError(t, json.Unmarshal(chainConfig, &config))
+			require.Equal(t, tt.expected, config)
+		})
+	}
+}
@@ -0,0 +1,260 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	avalancheWarp "github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	warpPayload "github.com/ava-labs/avalanchego/vms/platformvm/warp/payload"
+	teleportermessenger "github.com/ava-labs/icm-contracts/abi-bindings/go/teleporter/TeleporterMessenger"
+	gasUtils "github.com/ava-labs/icm-contracts/utils/gas-utils"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/ethclient"
+	"github.com/ava-labs/subnet-evm/precompile/contracts/warp"
+	predicateutils "github.com/ava-labs/subnet-evm/predicate"
+	"github.com/ava-labs/subnet-evm/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var (
+	relaySourceRPC          string
+	relayDestinationRPC     string
+	relayAddress            string
+	relayDestinationAddress string
+	relayRewardAddress      string
+	relayDryRun             bool
+	relaySignatures         signatureSource
+	relaySigner             signerFlags
+
+	errNoTeleporterWarpMessage  = errors.New("no Teleporter Warp message found in transaction")
+	errNotTeleporterWarpMessage = errors.New("warp message was not sent by the TeleporterMessenger")
+)
+
+var relayCmd = &cobra.Command{
+	Use: "relay --source-rpc RPC_URL [--destination-rpc RPC_URL] --teleporter-address CONTRACT_ADDRESS " +
+		"(--aggregator-url URL | --signatures FILE) TRANSACTION_HASH",
+	Short: "Manually delivers a Teleporter message to its destination chain",
+	Long: `Given the hash of a source chain transaction that sent a Teleporter message, this command
+extracts the Warp message emitted by the transaction, collects its aggregate signature from a
+signature aggregator service or a file of pre-collected signatures, and submits a
+receiveCrossChainMessage transaction carrying the signed message as a predicate to the
+destination chain. The gas limit is sized the same way the relayer sizes it. Pass --dry-run
+to print the signed transaction without submitting it. If --destination-rpc is not set, the
+destination chain's endpoint and Teleporter address are taken from the network profile with
+the message's destination blockchain ID.`,
+	Args: cobra.ExactArgs(1),
+	Run:  relayRun,
+}
+
+func relayRun(cmd *cobra.Command, args []string) {
+	txHash := common.HexToHash(args[0])
+	sourceAddress, err := parseAddress(relayAddress)
+	cobra.CheckErr(err)
+	key, err := relaySigner.load()
+	cobra.CheckErr(err)
+	rewardAddress := crypto.PubkeyToAddress(key.PublicKey)
+	if relayRewardAddress != "" {
+		rewardAddress, err = parseAddress(relayRewardAddress)
+		cobra.CheckErr(err)
+	}
+
+	ctx := context.Background()
+	sourceClient, err := ethclient.DialContext(ctx, relaySourceRPC)
+	cobra.CheckErr(err)
+	defer sourceClient.Close()
+
+	receipt, err := sourceClient.TransactionReceipt(ctx, txHash)
+	cobra.CheckErr(err)
+	unsignedMsg, teleporterMessage, err := extractTeleporterWarpMessage(receipt, sourceAddress)
+	cobra.CheckErr(err)
+	cmd.Println("ICM Message ID: " + unsignedMsg.ID().Hex())
+	cmd.Println("Teleporter Message:")
+	cmd.Println(teleporterMessage.String() + "\n")
+
+	destinationRPC, destinationAddress, err := resolveDestination(
+		relayDestinationRPC,
+		relayDestinationAddress,
+		teleporterMessage.DestinationBlockchainID,
+		sourceAddress,
+	)
+	cobra.CheckErr(err)
+	destinationClient, err := ethclient.DialContext(ctx, destinationRPC)
+	cobra.CheckErr(err)
+	defer destinationClient.Close()
+
+	signedMsg, err := relaySignatures.signedMessage(ctx, unsignedMsg)
+	cobra.CheckErr(err)
+
+	txData, err := newReceiveCrossChainMessageTx(
+		ctx,
+		destinationClient,
+		crypto.PubkeyToAddress(key.PublicKey),
+		destinationAddress,
+		rewardAddress,
+		signedMsg,
+		teleporterMessage,
+	)
+	cobra.CheckErr(err)
+
+	if relayDryRun {
+		tx, err := types.SignNewTx(key, types.LatestSignerForChainID(txData.ChainID), txData)
+		cobra.CheckErr(err)
+		txJson, err := json.MarshalIndent(tx, "", "  ")
+		cobra.CheckErr(err)
+		txBytes, err := tx.MarshalBinary()
+		cobra.CheckErr(err)
+		cmd.Println("Transaction:\n" + string(txJson) + "\n")
+		cmd.Println("Raw Transaction:\n" + hex.EncodeToString(txBytes) + "\n")
+		cmd.Println("Relay command ran successfully (dry run, transaction not submitted)")
+		return
+	}
+
+	destinationReceipt, err := signAndSend(ctx, destinationClient, key, txData)
+	cobra.CheckErr(err)
+	logger.Info("Delivered Teleporter message", zap.String("txHash", destinationReceipt.TxHash.Hex()))
+	printReceiptTeleporterLogs(cmd, destinationReceipt, destinationAddress)
+	cmd.Println("Relay command ran successfully")
+}
+
+// extractTeleporterWarpMessage returns the unsigned Warp message sent by the TeleporterMessenger at
+// teleporterAddress in the given receipt, along with the Teleporter message it carries.
+func extractTeleporterWarpMessage(
+	receipt *types.Receipt,
+	teleporterAddress common.Address,
+) (*avalancheWarp.UnsignedMessage, *teleportermessenger.TeleporterMessage, error) {
+	icmPrecompileAddress := common.HexToAddress(ICMPrecompileAddressHex)
+	for _, log := range receipt.Logs {
+		if log.Address != icmPrecompileAddress {
+			continue
+		}
+		unsignedMsg, err := warp.UnpackSendWarpEventDataToMessage(log.Data)
+		if err != nil {
+			return nil, nil, err
+		}
+		teleporterMessage, err := parseTeleporterWarpMessage(unsignedMsg, teleporterAddress)
+		if errors.Is(err, errNotTeleporterWarpMessage) {
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		return unsignedMsg, teleporterMessage, nil
+	}
+	return nil, nil, errNoTeleporterWarpMessage
+}
+
+// parseTeleporterWarpMessage returns the Teleporter message carried by unsignedMsg, which must be
+// an addressed call sent by the TeleporterMessenger at teleporterAddress.
+func parseTeleporterWarpMessage(
+	unsignedMsg *avalancheWarp.UnsignedMessage,
+	teleporterAddress common.Address,
+) (*teleportermessenger.TeleporterMessage, error) {
+	addressedCall, err := warpPayload.ParseAddressedCall(unsignedMsg.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errNotTeleporterWarpMessage, err)
+	}
+	if !bytes.Equal(addressedCall.SourceAddress, teleporterAddress.Bytes()) {
+		return nil, fmt.Errorf(
+			"%w: sent by %s",
+			errNotTeleporterWarpMessage,
+			common.BytesToAddress(addressedCall.SourceAddress),
+		)
+	}
+	teleporterMessage := teleportermessenger.TeleporterMessage{}
+	if err := teleporterMessage.Unpack(addressedCall.Payload); err != nil {
+		return nil, err
+	}
+	return &teleporterMessage, nil
+}
+
+// newReceiveCrossChainMessageTx builds an unsigned receiveCrossChainMessage transaction that carries
+// signedMsg as a Warp predicate, with the gas limit sized by gasUtils.CalculateReceiveMessageGasLimit.
+func newReceiveCrossChainMessageTx(
+	ctx context.Context,
+	c ethclient.Client,
+	from common.Address,
+	teleporterAddress common.Address,
+	rewardAddress common.Address,
+	signedMsg *avalancheWarp.Message,
+	teleporterMessage *teleportermessenger.TeleporterMessage,
+) (*types.DynamicFeeTx, error) {
+	gasLimit, err := receiveMessageGasLimit(signedMsg, teleporterMessage)
+	if err != nil {
+		return nil, err
+	}
+	callData, err := teleportermessenger.PackReceiveCrossChainMessage(0, rewardAddress)
+	if err != nil {
+		return nil, err
+	}
+	return newDynamicFeeTx(ctx, c, from, teleporterAddress, callData, gasLimit, predicateAccessList(signedMsg))
+}
+
+func receiveMessageGasLimit(
+	signedMsg *avalancheWarp.Message,
+	teleporterMessage *teleportermessenger.TeleporterMessage,
+) (uint64, error) {
+	numSigners, err := signedMsg.Signature.NumSigners()
+	if err != nil {
+		return 0, err
+	}
+	return gasUtils.CalculateReceiveMessageGasLimit(
+		numSigners,
+		teleporterMessage.RequiredGasLimit,
+		len(signedMsg.Bytes()),
+		len(signedMsg.Payload),
+		len(teleporterMessage.Receipts),
+	)
+}
+
+// predicateAccessList returns the access list that carries signedMsg as a Warp predicate,
+// as built by predicateutils.NewPredicateTx.
+func predicateAccessList(signedMsg *avalancheWarp.Message) types.AccessList {
+	return types.AccessList{
+		{
+			Address:     warp.ContractAddress,
+			StorageKeys: utils.BytesToHashSlice(predicateutils.PackPredicate(signedMsg.Bytes())),
+		},
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(relayCmd)
+	relayCmd.Flags().StringVar(&relaySourceRPC, "source-rpc", "", "RPC endpoint of the source chain")
+	relayCmd.Flags().StringVar(
+		&relayDestinationRPC,
+		"destination-rpc",
+		"",
+		"RPC endpoint of the destination chain, resolved from the network profiles if not set",
+	)
+	relayCmd.Flags().StringVarP(&relayAddress, "teleporter-address", "t", "", "Teleporter contract address")
+	relayCmd.Flags().StringVar(
+		&relayDestinationAddress,
+		"destination-teleporter-address",
+		"",
+		"Teleporter contract address on the destination chain, if different from --teleporter-address",
+	)
+	relayCmd.Flags().StringVar(
+		&relayRewardAddress,
+		"relayer-reward-address",
+		"",
+		"Address credited with the relayer reward, defaults to the signing key's address",
+	)
+	relayCmd.Flags().BoolVar(&relayDryRun, "dry-run", false, "Print the signed transaction without submitting it")
+	addSignatureSourceFlags(relayCmd, &relaySignatures)
+	addSignerFlags(relayCmd, &relaySigner)
+
+	for _, flag := range []string{"source-rpc", "teleporter-address"} {
+		err := relayCmd.MarkFlagRequired(flag)
+		cobra.CheckErr(err)
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	avalancheWarp "github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	warpPayload "github.com/ava-labs/avalanchego/vms/platformvm/warp/payload"
+	teleportermessenger "github.com/ava-labs/icm-contracts/abi-bindings/go/teleporter/TeleporterMessenger"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/precompile/contracts/warp"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelayCmd(t *testing.T) {
+	var tests = []struct {
+		name string
+		args []string
+		err  error
+		out  string
+	}{
+		{
+			name: "no args",
+			args: []string{"relay"},
+			err:  fmt.Errorf("accepts 1 arg(s), received 0"),
+		},
+		{
+			name: "help",
+			args: []string{"relay", "--help"},
+			err:  nil,
+			out:  "Given the hash of a source chain transaction that sent a Teleporter message",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := executeTestCmd(t, rootCmd, tt.args...)
+			if tt.err != nil {
+				require.ErrorContains(t, err, tt.err.Error())
+			} else {
+				require.NoError(t, err)
+				require.Contains(t, out, tt.out)
+			}
+		})
+	}
+}
+
+func TestExtractTeleporterWarpMessage(t *testing.T) {
+	teleporterAddress := common.HexToAddress("0x253b2784c75e510dD0fF1da844684a1aC0aa5fcf")
+	otherAddress := common.HexToAddress("0x0123456789abcdef0123456789abcdef01234567")
+	teleporterMessage := teleportermessenger.TeleporterMessage{
+		MessageNonce:            big.NewInt(1),
+		OriginSenderAddress:     otherAddress,
+		DestinationBlockchainID: ids.ID{1},
+		DestinationAddress:      otherAddress,
+		RequiredGasLimit:        big.NewInt(100_000),
+		AllowedRelayerAddresses: []common.Address{},
+		Receipts:                []teleportermessenger.TeleporterMessageReceipt{},
+		Message:                 []byte{1, 2, 3, 4},
+	}
+	messageBytes, err := teleporterMessage.Pack()
+	require.NoError(t, err)
+
+	newWarpLog := func(sourceAddress common.Address) *types.Log {
+		addressedCall, err := warpPayload.NewAddressedCall(sourceAddress.Bytes(), messageBytes)
+		require.NoError(t, err)
+		unsignedMsg, err := avalancheWarp.NewUnsignedMessage(1, ids.ID{2}, addressedCall.Bytes())
+		require.NoError(t, err)
+		topics, data, err := warp.PackSendWarpMessageEvent(sourceAddress, common.Hash(unsignedMsg.ID()), unsignedMsg.Bytes())
+		require.NoError(t, err)
+		return &types.Log{
+			Address: common.HexToAddress(ICMPrecompileAddressHex),
+			Topics:  topics,
+			Data:    data,
+		}
+	}
+
+	t.Run("teleporter message", func(t *testing.T) {
+		receipt := &types.Receipt{Logs: []*types.Log{newWarpLog(otherAddress), newWarpLog(teleporterAddress)}}
+		unsignedMsg, msg, err := extractTeleporterWarpMessage(receipt, teleporterAddress)
+		require.NoError(t, err)
+		require.Equal(t, ids.ID{2}, unsignedMsg.SourceChainID)
+		require.Equal(t, teleporterMessage, *msg)
+	})
+
+	t.Run("no teleporter message", func(t *testing.T) {
+		receipt := &types.Receipt{Logs: []*types.Log{newWarpLog(otherAddress)}}
+		_, _, err := extractTeleporterWarpMessage(receipt, teleporterAddress)
+		require.ErrorIs(t, err, errNoTeleporterWarpMessage)
+	})
+}
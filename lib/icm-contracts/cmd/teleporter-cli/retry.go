@@ -0,0 +1,255 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/ids"
+	teleportermessenger "github.com/ava-labs/icm-contracts/abi-bindings/go/teleporter/TeleporterMessenger"
+	"github.com/ava-labs/subnet-evm/accounts/abi/bind"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/ethclient"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var (
+	retryRPC                string
+	retryAddress            string
+	retrySourceBlockchainID string
+	retrySend               bool
+	retryFromBlock          uint64
+	retryGasLimit           uint64
+	retrySigner             signerFlags
+
+	errRetryMissingSourceBlockchainID = errors.New("--source-blockchain-id is required unless --send is set")
+	errRetryNoPendingMessage          = errors.New("no message to retry, it was never sent or already succeeded")
+	errRetryEventNotFound             = errors.New("message event not found")
+	errRetryMessageHashMismatch       = errors.New("message hash does not match the hash stored by the contract")
+)
+
+var retryCmd = &cobra.Command{
+	Use: "retry --rpc RPC_URL --teleporter-address CONTRACT_ADDRESS " +
+		"(--source-blockchain-id ID | --send) MESSAGE_ID",
+	Short: "Retries the execution of a failed Teleporter message, or re-emits a sent message",
+	Long: `Given the ID of a Teleporter message whose execution failed on the destination chain,
+this command finds the MessageExecutionFailed event for the message on the chain served by
+--rpc, reconstructs the exact TeleporterMessage from it, and checks its hash against the failed
+message hash stored by the TeleporterMessenger. It then submits a retryMessageExecution
+transaction, with the gas limit estimated unless --gas-limit is set, and reports whether the
+retry emitted MessageExecuted.
+
+Pass --send to instead retry a send on the source chain served by --rpc. The command finds the
+SendCrossChainMessage event for the message, checks its hash against the sent message hash
+stored by the TeleporterMessenger, and submits a retrySendCrossChainMessage transaction, which
+re-emits the message so that relayers can deliver it.`,
+	Args: cobra.ExactArgs(1),
+	Run:  retryRun,
+}
+
+func retryRun(cmd *cobra.Command, args []string) {
+	messageID, err := parseHexID(args[0])
+	cobra.CheckErr(err)
+	address, err := parseAddress(retryAddress)
+	cobra.CheckErr(err)
+
+	var sourceBlockchainID ids.ID
+	if !retrySend {
+		if retrySourceBlockchainID == "" {
+			cobra.CheckErr(errRetryMissingSourceBlockchainID)
+		}
+		sourceBlockchainID, err = parseBlockchainID(retrySourceBlockchainID)
+		cobra.CheckErr(err)
+	}
+
+	key, err := retrySigner.load()
+	cobra.CheckErr(err)
+
+	ctx := context.Background()
+	c, err := ethclient.DialContext(ctx, retryRPC)
+	cobra.CheckErr(err)
+	defer c.Close()
+	messenger, err := teleportermessenger.NewTeleporterMessenger(address, c)
+	cobra.CheckErr(err)
+
+	var (
+		message  *teleportermessenger.TeleporterMessage
+		callData []byte
+	)
+	if retrySend {
+		message, err = findSentMessage(ctx, messenger, messageID)
+		cobra.CheckErr(err)
+		callData, err = teleportermessenger.PackRetrySendCrossChainMessage(*message)
+	} else {
+		message, err = findFailedMessage(ctx, messenger, messageID, sourceBlockchainID)
+		cobra.CheckErr(err)
+		callData, err = teleportermessenger.PackRetryMessageExecution(sourceBlockchainID, *message)
+	}
+	cobra.CheckErr(err)
+	cmd.Println("Teleporter Message:")
+	cmd.Println(message.String() + "\n")
+
+	txData, err := newDynamicFeeTx(ctx, c, crypto.PubkeyToAddress(key.PublicKey), address, callData, retryGasLimit, nil)
+	cobra.CheckErr(err)
+	receipt, err := signAndSend(ctx, c, key, txData)
+	cobra.CheckErr(err)
+	logger.Info("Retry transaction accepted", zap.String("txHash", receipt.TxHash.Hex()))
+
+	if retrySend {
+		err = printRetryLog(cmd, receipt, messageID, "SendCrossChainMessage", func(log types.Log) (fmt.Stringer, error) {
+			return messenger.ParseSendCrossChainMessage(log)
+		})
+	} else {
+		err = printRetryLog(cmd, receipt, messageID, "MessageExecuted", func(log types.Log) (fmt.Stringer, error) {
+			return messenger.ParseMessageExecuted(log)
+		})
+	}
+	cobra.CheckErr(err)
+	cmd.Println("Retry command ran successfully")
+}
+
+// findFailedMessage returns the message of the latest MessageExecutionFailed event for messageID,
+// after checking that its hash matches the failed message hash stored by the contract.
+func findFailedMessage(
+	ctx context.Context,
+	messenger *teleportermessenger.TeleporterMessenger,
+	messageID ids.ID,
+	sourceBlockchainID ids.ID,
+) (*teleportermessenger.TeleporterMessage, error) {
+	failedMessageHash, err := messenger.ReceivedFailedMessageHashes(&bind.CallOpts{Context: ctx}, messageID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get failed message hash: %w", err)
+	}
+
+	it, err := messenger.FilterMessageExecutionFailed(
+		&bind.FilterOpts{Start: retryFromBlock, Context: ctx},
+		[][32]byte{messageID},
+		[][32]byte{sourceBlockchainID},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to filter MessageExecutionFailed logs: %w", err)
+	}
+	defer it.Close()
+	var message *teleportermessenger.TeleporterMessage
+	for it.Next() {
+		message = &it.Event.Message
+	}
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+	if message == nil {
+		return nil, fmt.Errorf("%w: no MessageExecutionFailed log for message %s", errRetryEventNotFound, messageID.Hex())
+	}
+	return message, verifyMessageHash(*message, failedMessageHash)
+}
+
+// findSentMessage returns the message of the latest SendCrossChainMessage event for messageID,
+// after checking that its hash matches the sent message hash stored by the contract.
+func findSentMessage(
+	ctx context.Context,
+	messenger *teleportermessenger.TeleporterMessenger,
+	messageID ids.ID,
+) (*teleportermessenger.TeleporterMessage, error) {
+	messageHash, err := messenger.GetMessageHash(&bind.CallOpts{Context: ctx}, messageID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get message hash: %w", err)
+	}
+
+	it, err := messenger.FilterSendCrossChainMessage(
+		&bind.FilterOpts{Start: retryFromBlock, Context: ctx},
+		[][32]byte{messageID},
+		nil,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to filter SendCrossChainMessage logs: %w", err)
+	}
+	defer it.Close()
+	var message *teleportermessenger.TeleporterMessage
+	for it.Next() {
+		message = &it.Event.Message
+	}
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+	if message == nil {
+		return nil, fmt.Errorf("%w: no SendCrossChainMessage log for message %s", errRetryEventNotFound, messageID.Hex())
+	}
+	return message, verifyMessageHash(*message, messageHash)
+}
+
+// verifyMessageHash checks that the ABI encoded message hashes to expected, as the contract
+// does before retrying. A zero expected hash means there is nothing left to retry.
+func verifyMessageHash(message teleportermessenger.TeleporterMessage, expected [32]byte) error {
+	if expected == [32]byte{} {
+		return errRetryNoPendingMessage
+	}
+	messageBytes, err := message.Pack()
+	if err != nil {
+		return err
+	}
+	if hash := crypto.Keccak256Hash(messageBytes); hash != common.Hash(expected) {
+		return fmt.Errorf("%w: got %s, expected %s", errRetryMessageHashMismatch, hash.Hex(), common.Hash(expected).Hex())
+	}
+	return nil
+}
+
+// printRetryLog prints the first log of the receipt that parse decodes into the named event for messageID.
+func printRetryLog(
+	cmd *cobra.Command,
+	receipt *types.Receipt,
+	messageID ids.ID,
+	eventName string,
+	parse func(types.Log) (fmt.Stringer, error),
+) error {
+	for _, log := range receipt.Logs {
+		if len(log.Topics) < 2 || log.Topics[1] != common.Hash(messageID) {
+			continue
+		}
+		event, err := parse(*log)
+		if err != nil {
+			continue
+		}
+		cmd.Println(eventName + " Log:")
+		cmd.Println(event.String() + "\n")
+		return nil
+	}
+	return fmt.Errorf(
+		"%w: retry transaction %s emitted no %s log for message %s",
+		errRetryEventNotFound,
+		receipt.TxHash.Hex(),
+		eventName,
+		messageID.Hex(),
+	)
+}
+
+func init() {
+	rootCmd.AddCommand(retryCmd)
+	retryCmd.Flags().StringVar(&retryRPC, "rpc", "", "RPC endpoint to connect to the node")
+	retryCmd.Flags().StringVarP(&retryAddress, "teleporter-address", "t", "", "Teleporter contract address")
+	retryCmd.Flags().StringVar(
+		&retrySourceBlockchainID,
+		"source-blockchain-id",
+		"",
+		"Source blockchain ID of the failed message (CB58 or hex)",
+	)
+	retryCmd.Flags().BoolVar(
+		&retrySend,
+		"send",
+		false,
+		"Retry sending the message on its source chain instead of retrying its execution",
+	)
+	retryCmd.Flags().Uint64Var(&retryFromBlock, "from-block", 0, "First block to scan for the message's logs")
+	retryCmd.Flags().Uint64Var(&retryGasLimit, "gas-limit", 0, "Gas limit of the transaction, estimated if not set")
+	addSignerFlags(retryCmd, &retrySigner)
+
+	err := retryCmd.MarkFlagRequired("rpc")
+	cobra.CheckErr(err)
+	err = retryCmd.MarkFlagRequired("teleporter-address")
+	cobra.CheckErr(err)
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	teleportermessenger "github.com/ava-labs/icm-contracts/abi-bindings/go/teleporter/TeleporterMessenger"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryCmd(t *testing.T) {
+	var tests = []struct {
+		name string
+		args []string
+		err  error
+		out  string
+	}{
+		{
+			name: "no args",
+			args: []string{"retry"},
+			err:  fmt.Errorf("accepts 1 arg(s), received 0"),
+		},
+		{
+			name: "help",
+			args: []string{"retry", "--help"},
+			err:  nil,
+			out:  "Given the ID of a Teleporter message whose execution failed on the destination chain",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := executeTestCmd(t, rootCmd, tt.args...)
+			if tt.err != nil {
+				require.ErrorContains(t, err, tt.err.Error())
+			} else {
+				require.NoError(t, err)
+				require.Contains(t, out, tt.out)
+			}
+		})
+	}
+}
+
+func TestVerifyMessageHash(t *testing.T) {
+	address := common.HexToAddress("0x0123456789abcdef0123456789abcdef01234567")
+	message := teleportermessenger.TeleporterMessage{
+		MessageNonce:            big.NewInt(1),
+		OriginSenderAddress:     address,
+		DestinationBlockchainID: ids.ID{1},
+		DestinationAddress:      address,
+		RequiredGasLimit:        big.NewInt(100_000),
+		AllowedRelayerAddresses: []common.Address{},
+		Receipts:                []teleportermessenger.TeleporterMessageReceipt{},
+		Message:                 []byte{1, 2, 3, 4},
+	}
+	messageBytes, err := message.Pack()
+	require.NoError(t, err)
+	messageHash := crypto.Keccak256Hash(messageBytes)
+
+	var tests = []struct {
+		name     string
+		expected [32]byte
+		err      error
+	}{
+		{
+			name:     "matching hash",
+			expected: messageHash,
+		},
+		{
+			name:     "no pending message",
+			expected: [32]byte{},
+			err:      errRetryNoPendingMessage,
+		},
+		{
+			name:     "mismatched hash",
+			expected: common.Hash{1},
+			err:      errRetryMessageHashMismatch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifyMessageHash(message, tt.expected)
+			require.ErrorIs(t, err, tt.err)
+		})
+	}
+}
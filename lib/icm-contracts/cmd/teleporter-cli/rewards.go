@@ -0,0 +1,148 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+
+	teleportermessenger "github.com/ava-labs/icm-contracts/abi-bindings/go/teleporter/TeleporterMessenger"
+	"github.com/ava-labs/subnet-evm/accounts/abi/bind"
+	"github.com/ava-labs/subnet-evm/ethclient"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var (
+	rewardsRPC     string
+	rewardsAddress string
+	rewardsRelayer string
+	rewardsTokens  []string
+	rewardsSigner  signerFlags
+)
+
+var rewardsCmd = &cobra.Command{
+	Use:   "rewards --rpc RPC_URL --teleporter-address CONTRACT_ADDRESS --relayer ADDRESS --tokens TOKEN_ADDRESS,...",
+	Short: "Reports the redeemable relayer rewards of a relayer",
+	Long: `Given a relayer reward address and a list of fee tokens, this command reports the amount of
+each token the relayer can redeem from the TeleporterMessenger on the chain served by --rpc.
+Rewards are credited once the receipts of the messages the relayer delivered are returned.`,
+	Args: cobra.NoArgs,
+	Run:  rewardsRun,
+}
+
+var rewardsRedeemCmd = &cobra.Command{
+	Use:   "redeem --rpc RPC_URL --teleporter-address CONTRACT_ADDRESS --tokens TOKEN_ADDRESS,...",
+	Short: "Redeems the relayer rewards of the signing key's address",
+	Long: `Redeems the relayer rewards credited to the signing key's address for each of the given fee
+tokens, submitting one redeemRelayerRewards transaction per token with a non-zero reward. Tokens
+without a reward to redeem are skipped. The resulting RelayerRewardsRedeemed events are printed.`,
+	Args: cobra.NoArgs,
+	Run:  rewardsRedeemRun,
+}
+
+// relayerReward is the redeemable reward of a relayer in a single fee token.
+type relayerReward struct {
+	FeeTokenAddress common.Address
+	Amount          *big.Int
+}
+
+func rewardsRun(cmd *cobra.Command, args []string) {
+	relayer, err := parseAddress(rewardsRelayer)
+	cobra.CheckErr(err)
+	address, err := parseAddress(rewardsAddress)
+	cobra.CheckErr(err)
+
+	ctx := context.Background()
+	c, err := ethclient.DialContext(ctx, rewardsRPC)
+	cobra.CheckErr(err)
+	defer c.Close()
+	messenger, err := teleportermessenger.NewTeleporterMessenger(address, c)
+	cobra.CheckErr(err)
+
+	rewards, err := relayerRewards(ctx, messenger, relayer)
+	cobra.CheckErr(err)
+	rewardsJson, err := json.MarshalIndent(rewards, "", "  ")
+	cobra.CheckErr(err)
+	cmd.Println("Relayer Rewards:\n" + string(rewardsJson) + "\n")
+	cmd.Println("Rewards command ran successfully")
+}
+
+func rewardsRedeemRun(cmd *cobra.Command, args []string) {
+	key, err := rewardsSigner.load()
+	cobra.CheckErr(err)
+	relayer := crypto.PubkeyToAddress(key.PublicKey)
+	address, err := parseAddress(rewardsAddress)
+	cobra.CheckErr(err)
+
+	ctx := context.Background()
+	c, err := ethclient.DialContext(ctx, rewardsRPC)
+	cobra.CheckErr(err)
+	defer c.Close()
+	messenger, err := teleportermessenger.NewTeleporterMessenger(address, c)
+	cobra.CheckErr(err)
+
+	rewards, err := relayerRewards(ctx, messenger, relayer)
+	cobra.CheckErr(err)
+	for _, reward := range rewards {
+		if reward.Amount.Sign() == 0 {
+			logger.Info("No reward to redeem", zap.String("token", reward.FeeTokenAddress.Hex()))
+			continue
+		}
+		opts, err := newTransactor(ctx, c, key)
+		cobra.CheckErr(err)
+		tx, err := messenger.RedeemRelayerRewards(opts, reward.FeeTokenAddress)
+		cobra.CheckErr(err)
+		logger.Info(
+			"Redeeming relayer rewards",
+			zap.String("token", reward.FeeTokenAddress.Hex()),
+			zap.String("amount", reward.Amount.String()),
+		)
+		receipt, err := waitForSuccess(ctx, c, tx)
+		cobra.CheckErr(err)
+		printReceiptTeleporterLogs(cmd, receipt, address)
+	}
+	cmd.Println("Rewards redeem command ran successfully")
+}
+
+// relayerRewards returns the redeemable reward of relayer in each of the --tokens fee tokens.
+func relayerRewards(
+	ctx context.Context,
+	messenger *teleportermessenger.TeleporterMessenger,
+	relayer common.Address,
+) ([]relayerReward, error) {
+	rewards := make([]relayerReward, 0, len(rewardsTokens))
+	for _, t := range rewardsTokens {
+		token, err := parseAddress(t)
+		if err != nil {
+			return nil, err
+		}
+		amount, err := messenger.CheckRelayerRewardAmount(&bind.CallOpts{Context: ctx}, relayer, token)
+		if err != nil {
+			return nil, err
+		}
+		rewards = append(rewards, relayerReward{FeeTokenAddress: token, Amount: amount})
+	}
+	return rewards, nil
+}
+
+func init() {
+	rootCmd.AddCommand(rewardsCmd)
+	rewardsCmd.AddCommand(rewardsRedeemCmd)
+	rewardsCmd.PersistentFlags().StringVar(&rewardsRPC, "rpc", "", "RPC endpoint to connect to the node")
+	rewardsCmd.PersistentFlags().StringVarP(&rewardsAddress, "teleporter-address", "t", "", "Teleporter contract address")
+	rewardsCmd.PersistentFlags().StringSliceVar(&rewardsTokens, "tokens", []string{}, "Fee token addresses")
+	rewardsCmd.Flags().StringVar(&rewardsRelayer, "relayer", "", "Relayer reward address")
+	addSignerFlags(rewardsRedeemCmd, &rewardsSigner)
+
+	for _, flag := range []string{"rpc", "teleporter-address", "tokens"} {
+		err := rewardsCmd.MarkPersistentFlagRequired(flag)
+		cobra.CheckErr(err)
+	}
+	err := rewardsCmd.MarkFlagRequired("relayer")
+	cobra.CheckErr(err)
+}
@@ -7,15 +7,10 @@ import (
 	"os"
 
 	"github.com/ava-labs/avalanchego/utils/logging"
-	teleportermessenger "github.com/ava-labs/icm-contracts/abi-bindings/go/teleporter/TeleporterMessenger"
-	"github.com/ava-labs/subnet-evm/accounts/abi"
 	"github.com/spf13/cobra"
 )
 
-var (
-	logger        logging.Logger
-	teleporterABI *abi.ABI
-)
+var logger logging.Logger
 
 var rootCmd = &cobra.Command{
 	Use:   "teleporter-cli",
@@ -37,8 +32,18 @@ func Execute() {
 func init() {
 	rootCmd.CompletionOptions.DisableDefaultCmd = true
 	logLevelArg := rootCmd.PersistentFlags().StringP("log", "l", "", "Log level i.e. debug, info...")
+	rootCmd.PersistentFlags().StringVar(&networkName, "network", "", "Name of the network profile to fill flags from")
+	rootCmd.PersistentFlags().StringVar(
+		&networkConfigPath,
+		"config",
+		"",
+		"Network profiles config file (default ~/.teleporter-cli/networks.yaml)",
+	)
 	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
-		return rootPreRunE(logLevelArg)
+		if err := rootPreRunE(logLevelArg); err != nil {
+			return err
+		}
+		return applyNetworkProfile(cmd)
 	}
 }
 
@@ -59,18 +64,13 @@ func rootPreRunE(logLevelArg *string) error {
 			logging.Plain.ConsoleEncoder(),
 		),
 	)
-	abi, err := teleportermessenger.TeleporterMessengerMetaData.GetAbi()
-	if err != nil {
-		return err
-	}
-	teleporterABI = abi
 	return nil
 }
 
 func callPersistentPreRunE(cmd *cobra.Command, args []string) error {
 	if parent := cmd.Parent(); parent != nil {
 		if parent.PersistentPreRunE != nil {
-			return parent.PersistentPreRunE(parent, args)
+			return parent.PersistentPreRunE(cmd, args)
 		}
 	}
 	return nil
@@ -0,0 +1,523 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"sort"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/set"
+	teleportermessenger "github.com/ava-labs/icm-contracts/abi-bindings/go/teleporter/TeleporterMessenger"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/ethclient"
+	"github.com/ava-labs/subnet-evm/interfaces"
+	"github.com/ava-labs/subnet-evm/precompile/contracts/warp"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultScanBatchSize   = 2048
+	defaultScanConcurrency = 4
+	sendWarpMessageEvent   = "SendWarpMessage"
+)
+
+var (
+	scanRPC         string
+	scanAddress     string
+	scanFromBlock   uint64
+	scanToBlock     uint64
+	scanBatchSize   uint64
+	scanConcurrency int
+	scanOutput      string
+
+	errScanInvalidRange = errors.New("--from-block must not be greater than --to-block")
+	errScanBatchSize    = errors.New("--batch-size and --concurrency must be positive")
+)
+
+var scanCmd = &cobra.Command{
+	Use:   "scan --rpc RPC_URL --teleporter-address CONTRACT_ADDRESS --from-block N [--to-block M]",
+	Short: "Summarizes Teleporter traffic over a block range",
+	Long: `Pages through the TeleporterMessenger and Warp precompile logs emitted between
+--from-block and --to-block (the latest block if omitted), decodes each log, and prints a
+summary of the Teleporter traffic in that range: messages sent and receipts received per
+destination blockchain, messages received, executed and failed per source blockchain, relayer
+fees by token, the messages whose executions are still failing, and the sent messages whose
+receipts have not been returned within the range. Relayer fees are reported as escrowed by the
+messages sent in the range, including fees added with addFeeAmount, as still unsettled because
+their receipts were not returned, and as paid by the receipts received. Fees added to messages
+sent before the range are not counted, since AddFeeAmount only logs the updated total. Pass
+--output to also write every decoded event as a line of JSON. Logs are fetched in batches of
+--batch-size blocks, with up to --concurrency requests in flight, so the range limits of
+public RPC endpoints can be respected.`,
+	Args: cobra.NoArgs,
+	Run:  scanRun,
+}
+
+// scanRecord is a decoded log, as written to the JSONL output of the scan command.
+type scanRecord struct {
+	Event   string
+	Decoded json.RawMessage
+}
+
+// scanWarpMessage is the decoded form of a SendWarpMessage log emitted on behalf of the TeleporterMessenger.
+type scanWarpMessage struct {
+	MessageID common.Hash
+	Message   *decodedTree
+	Raw       types.Log
+}
+
+func (m scanWarpMessage) String() string {
+	outJson, _ := json.MarshalIndent(m, "", "  ")
+
+	return string(outJson)
+}
+
+type scanDestinationStats struct {
+	Sent             int
+	ReceiptsReceived int
+}
+
+type scanSourceStats struct {
+	Received int
+	Executed int
+	Failed   int
+}
+
+type scanFeeStats struct {
+	// Escrowed is the fees of the messages sent in the range, including the fees added to them.
+	Escrowed *big.Int
+	// Receipts and Paid are the receipts received in the range and the fees they settled.
+	Receipts int
+	Paid     *big.Int
+}
+
+// scanSummary aggregates the decoded Teleporter events of a block range.
+type scanSummary struct {
+	FromBlock      uint64
+	ToBlock        uint64
+	TeleporterLogs int
+	WarpMessages   int
+	Undecodable    int
+	Destinations   map[ids.ID]*scanDestinationStats
+	Sources        map[ids.ID]*scanSourceStats
+	Fees           map[common.Address]*scanFeeStats
+
+	// Message IDs sent in the range, mapped to their destination blockchain ID.
+	sent map[ids.ID]ids.ID
+	// Message IDs sent in the range, mapped to their current fee.
+	sentFees map[ids.ID]teleportermessenger.TeleporterFeeInfo
+	// Message IDs whose receipts were received in the range.
+	receipts set.Set[ids.ID]
+	// Message IDs whose latest execution attempt in the range failed, mapped to their source blockchain ID.
+	failing map[ids.ID]ids.ID
+}
+
+func newScanSummary(fromBlock, toBlock uint64) *scanSummary {
+	return &scanSummary{
+		FromBlock:    fromBlock,
+		ToBlock:      toBlock,
+		Destinations: make(map[ids.ID]*scanDestinationStats),
+		Sources:      make(map[ids.ID]*scanSourceStats),
+		Fees:         make(map[common.Address]*scanFeeStats),
+		sent:         make(map[ids.ID]ids.ID),
+		sentFees:     make(map[ids.ID]teleportermessenger.TeleporterFeeInfo),
+		receipts:     set.NewSet[ids.ID](0),
+		failing:      make(map[ids.ID]ids.ID),
+	}
+}
+
+func (s *scanSummary) destination(blockchainID ids.ID) *scanDestinationStats {
+	if _, ok := s.Destinations[blockchainID]; !ok {
+		s.Destinations[blockchainID] = &scanDestinationStats{}
+	}
+	return s.Destinations[blockchainID]
+}
+
+func (s *scanSummary) source(blockchainID ids.ID) *scanSourceStats {
+	if _, ok := s.Sources[blockchainID]; !ok {
+		s.Sources[blockchainID] = &scanSourceStats{}
+	}
+	return s.Sources[blockchainID]
+}
+
+func (s *scanSummary) fee(token common.Address) *scanFeeStats {
+	if _, ok := s.Fees[token]; !ok {
+		s.Fees[token] = &scanFeeStats{Escrowed: new(big.Int), Paid: new(big.Int)}
+	}
+	return s.Fees[token]
+}
+
+// record adds a decoded TeleporterMessenger event to the summary. Events must be
+// recorded in the order they were emitted.
+func (s *scanSummary) record(event fmt.Stringer) {
+	s.TeleporterLogs++
+	switch e := event.(type) {
+	case *teleportermessenger.TeleporterMessengerSendCrossChainMessage:
+		s.destination(e.DestinationBlockchainID).Sent++
+		s.sent[e.MessageID] = e.DestinationBlockchainID
+		feeInfo := teleportermessenger.TeleporterFeeInfo{FeeTokenAddress: e.FeeInfo.FeeTokenAddress, Amount: new(big.Int)}
+		if e.FeeInfo.Amount != nil && e.FeeInfo.Amount.Sign() > 0 {
+			feeInfo.Amount.Set(e.FeeInfo.Amount)
+			fee := s.fee(e.FeeInfo.FeeTokenAddress)
+			fee.Escrowed.Add(fee.Escrowed, e.FeeInfo.Amount)
+		}
+		s.sentFees[e.MessageID] = feeInfo
+	case *teleportermessenger.TeleporterMessengerAddFeeAmount:
+		// Only the updated total is logged, so the amount added is only known for messages sent in the range.
+		feeInfo, ok := s.sentFees[e.MessageID]
+		if !ok || e.UpdatedFeeInfo.Amount == nil {
+			break
+		}
+		fee := s.fee(e.UpdatedFeeInfo.FeeTokenAddress)
+		fee.Escrowed.Add(fee.Escrowed, new(big.Int).Sub(e.UpdatedFeeInfo.Amount, feeInfo.Amount))
+		s.sentFees[e.MessageID] = teleportermessenger.TeleporterFeeInfo{
+			FeeTokenAddress: e.UpdatedFeeInfo.FeeTokenAddress,
+			Amount:          new(big.Int).Set(e.UpdatedFeeInfo.Amount),
+		}
+	case *teleportermessenger.TeleporterMessengerReceiptReceived:
+		s.destination(e.DestinationBlockchainID).ReceiptsReceived++
+		s.receipts.Add(e.MessageID)
+		if e.FeeInfo.Amount != nil && e.FeeInfo.Amount.Sign() > 0 {
+			fee := s.fee(e.FeeInfo.FeeTokenAddress)
+			fee.Receipts++
+			fee.Paid.Add(fee.Paid, e.FeeInfo.Amount)
+		}
+	case *teleportermessenger.TeleporterMessengerReceiveCrossChainMessage:
+		s.source(e.SourceBlockchainID).Received++
+	case *teleportermessenger.TeleporterMessengerMessageExecuted:
+		s.source(e.SourceBlockchainID).Executed++
+		delete(s.failing, e.MessageID)
+	case *teleportermessenger.TeleporterMessengerMessageExecutionFailed:
+		s.source(e.SourceBlockchainID).Failed++
+		s.failing[e.MessageID] = e.SourceBlockchainID
+	}
+}
+
+// outstandingReceipts returns the IDs of the messages sent in the range whose receipts
+// were not received in the range, sorted by ID.
+func (s *scanSummary) outstandingReceipts() []ids.ID {
+	var outstanding []ids.ID
+	for messageID := range s.sent {
+		if !s.receipts.Contains(messageID) {
+			outstanding = append(outstanding, messageID)
+		}
+	}
+	sort.Slice(outstanding, func(i, j int) bool { return outstanding[i].Compare(outstanding[j]) < 0 })
+	return outstanding
+}
+
+// unsettledFees returns the fees of the messages sent in the range whose receipts were not
+// received in the range, by fee token.
+func (s *scanSummary) unsettledFees() map[common.Address]*big.Int {
+	unsettled := make(map[common.Address]*big.Int)
+	for _, messageID := range s.outstandingReceipts() {
+		feeInfo := s.sentFees[messageID]
+		if feeInfo.Amount == nil || feeInfo.Amount.Sign() == 0 {
+			continue
+		}
+		if _, ok := unsettled[feeInfo.FeeTokenAddress]; !ok {
+			unsettled[feeInfo.FeeTokenAddress] = new(big.Int)
+		}
+		unsettled[feeInfo.FeeTokenAddress].Add(unsettled[feeInfo.FeeTokenAddress], feeInfo.Amount)
+	}
+	return unsettled
+}
+
+// failingMessages returns the IDs of the messages whose executions are still failing, sorted by ID.
+func (s *scanSummary) failingMessages() []ids.ID {
+	failing := make([]ids.ID, 0, len(s.failing))
+	for messageID := range s.failing {
+		failing = append(failing, messageID)
+	}
+	sort.Slice(failing, func(i, j int) bool { return failing[i].Compare(failing[j]) < 0 })
+	return failing
+}
+
+func (s *scanSummary) write(w io.Writer) error {
+	outstanding := s.outstandingReceipts()
+	outstandingByDestination := make(map[ids.ID]int)
+	for _, messageID := range outstanding {
+		outstandingByDestination[s.sent[messageID]]++
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Blocks:\t%d - %d\n", s.FromBlock, s.ToBlock)
+	fmt.Fprintf(tw, "Teleporter logs:\t%d\n", s.TeleporterLogs)
+	fmt.Fprintf(tw, "Warp messages:\t%d\n", s.WarpMessages)
+	fmt.Fprintf(tw, "Undecodable logs:\t%d\n", s.Undecodable)
+
+	fmt.Fprintln(tw, "\nDESTINATION BLOCKCHAIN ID\tSENT\tRECEIPTS RECEIVED\tOUTSTANDING RECEIPTS")
+	for _, blockchainID := range sortedIDs(s.Destinations) {
+		d := s.Destinations[blockchainID]
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\n", blockchainID, d.Sent, d.ReceiptsReceived, outstandingByDestination[blockchainID])
+	}
+
+	fmt.Fprintln(tw, "\nSOURCE BLOCKCHAIN ID\tRECEIVED\tEXECUTED\tFAILED")
+	for _, blockchainID := range sortedIDs(s.Sources) {
+		src := s.Sources[blockchainID]
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\n", blockchainID, src.Received, src.Executed, src.Failed)
+	}
+
+	fmt.Fprintln(tw, "\nFEE TOKEN\tFEES ESCROWED\tFEES UNSETTLED\tRECEIPTS\tFEES PAID")
+	unsettled := s.unsettledFees()
+	tokens := make([]common.Address, 0, len(s.Fees))
+	for token := range s.Fees {
+		tokens = append(tokens, token)
+	}
+	sort.Slice(tokens, func(i, j int) bool { return tokens[i].Cmp(tokens[j]) < 0 })
+	for _, token := range tokens {
+		fee := s.Fees[token]
+		if unsettled[token] == nil {
+			unsettled[token] = new(big.Int)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\n", token.Hex(), fee.Escrowed, unsettled[token], fee.Receipts, fee.Paid)
+	}
+
+	fmt.Fprintln(tw, "\nFAILED EXECUTION MESSAGE ID\tSOURCE BLOCKCHAIN ID")
+	for _, messageID := range s.failingMessages() {
+		fmt.Fprintf(tw, "%s\t%s\n", messageID.Hex(), s.failing[messageID])
+	}
+
+	fmt.Fprintln(tw, "\nOUTSTANDING RECEIPT MESSAGE ID\tDESTINATION BLOCKCHAIN ID")
+	for _, messageID := range outstanding {
+		fmt.Fprintf(tw, "%s\t%s\n", messageID.Hex(), s.sent[messageID])
+	}
+	return tw.Flush()
+}
+
+func sortedIDs[T any](m map[ids.ID]T) []ids.ID {
+	keys := make([]ids.ID, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Compare(keys[j]) < 0 })
+	return keys
+}
+
+func scanRun(cmd *cobra.Command, args []string) {
+	address, err := parseAddress(scanAddress)
+	cobra.CheckErr(err)
+	if scanBatchSize == 0 || scanConcurrency <= 0 {
+		cobra.CheckErr(errScanBatchSize)
+	}
+
+	ctx := context.Background()
+	c, err := ethclient.DialContext(ctx, scanRPC)
+	cobra.CheckErr(err)
+	defer c.Close()
+
+	toBlock := scanToBlock
+	if !cmd.Flags().Changed("to-block") {
+		toBlock, err = c.BlockNumber(ctx)
+		cobra.CheckErr(err)
+	}
+	if scanFromBlock > toBlock {
+		cobra.CheckErr(errScanInvalidRange)
+	}
+
+	var output *bufio.Writer
+	if scanOutput != "" {
+		f, err := os.Create(scanOutput)
+		cobra.CheckErr(err)
+		defer f.Close()
+		output = bufio.NewWriter(f)
+	}
+
+	icmPrecompileAddress := common.HexToAddress(ICMPrecompileAddressHex)
+	batches, err := fetchLogBatches(
+		ctx,
+		c,
+		[]common.Address{address, icmPrecompileAddress},
+		scanRanges(scanFromBlock, toBlock, scanBatchSize),
+		scanConcurrency,
+	)
+	cobra.CheckErr(err)
+
+	summary := newScanSummary(scanFromBlock, toBlock)
+	for _, logs := range batches {
+		for _, log := range logs {
+			eventName, decoded, ok := decodeScanLog(log, address, icmPrecompileAddress)
+			if !ok {
+				if log.Address == address {
+					summary.TeleporterLogs++
+					summary.Undecodable++
+					logger.Warn(
+						"Failed to decode Teleporter log",
+						zap.String("txHash", log.TxHash.Hex()),
+						zap.Uint("logIndex", log.Index),
+					)
+				}
+				continue
+			}
+			if eventName == sendWarpMessageEvent {
+				summary.WarpMessages++
+			} else {
+				summary.record(decoded)
+			}
+			if output != nil {
+				var compacted bytes.Buffer
+				cobra.CheckErr(json.Compact(&compacted, []byte(decoded.String())))
+				line, err := json.Marshal(scanRecord{Event: eventName, Decoded: compacted.Bytes()})
+				cobra.CheckErr(err)
+				_, err = output.Write(append(line, '\n'))
+				cobra.CheckErr(err)
+			}
+		}
+	}
+	if output != nil {
+		cobra.CheckErr(output.Flush())
+	}
+
+	cobra.CheckErr(summary.write(cmd.OutOrStdout()))
+	cmd.Println("\nScan command ran successfully")
+}
+
+// decodeScanLog decodes a TeleporterMessenger log, or a SendWarpMessage log sent by the
+// TeleporterMessenger, returning the event name and the decoded event. It returns false for
+// logs that are neither, or that fail to decode.
+func decodeScanLog(
+	log types.Log,
+	teleporterAddress common.Address,
+	icmPrecompileAddress common.Address,
+) (string, fmt.Stringer, bool) {
+	switch log.Address {
+	case teleporterAddress:
+		event, out, err := teleportermessenger.ParseLog(log)
+		if err != nil {
+			return "", nil, false
+		}
+		return event.String(), out.(fmt.Stringer), true
+	case icmPrecompileAddress:
+		// SendWarpMessage logs index the sender as the second topic.
+		if len(log.Topics) < 2 || log.Topics[1] != common.BytesToHash(teleporterAddress.Bytes()) {
+			return "", nil, false
+		}
+		unsignedMsg, err := warp.UnpackSendWarpEventDataToMessage(log.Data)
+		if err != nil {
+			return "", nil, false
+		}
+		return sendWarpMessageEvent, scanWarpMessage{
+			MessageID: common.Hash(unsignedMsg.ID()),
+			Message:   decodeUnsignedWarpMessage(unsignedMsg),
+			Raw:       log,
+		}, true
+	default:
+		return "", nil, false
+	}
+}
+
+// scanRanges splits the inclusive block range [from, to] into inclusive ranges of at most batchSize blocks.
+func scanRanges(from, to, batchSize uint64) [][2]uint64 {
+	var ranges [][2]uint64
+	for start := from; start <= to; start += batchSize {
+		end := start + batchSize - 1
+		if end > to || end < start {
+			end = to
+		}
+		ranges = append(ranges, [2]uint64{start, end})
+		if end == to {
+			break
+		}
+	}
+	return ranges
+}
+
+// fetchLogBatches fetches the logs emitted by addresses in each of the block ranges, with up
+// to concurrency requests in flight. The returned batches are in the same order as ranges.
+func fetchLogBatches(
+	ctx context.Context,
+	c ethclient.Client,
+	addresses []common.Address,
+	ranges [][2]uint64,
+	concurrency int,
+) ([][]types.Log, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		batches  = make([][]types.Log, len(ranges))
+		jobs     = make(chan int)
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		fetchErr error
+	)
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				logs, err := c.FilterLogs(ctx, interfaces.FilterQuery{
+					FromBlock: new(big.Int).SetUint64(ranges[i][0]),
+					ToBlock:   new(big.Int).SetUint64(ranges[i][1]),
+					Addresses: addresses,
+				})
+				if err != nil {
+					errOnce.Do(func() {
+						fetchErr = fmt.Errorf(
+							"failed to filter logs in blocks %d - %d: %w",
+							ranges[i][0],
+							ranges[i][1],
+							err,
+						)
+						cancel()
+					})
+					continue
+				}
+				logger.Debug(
+					"Fetched logs",
+					zap.Uint64("fromBlock", ranges[i][0]),
+					zap.Uint64("toBlock", ranges[i][1]),
+					zap.Int("logs", len(logs)),
+				)
+				batches[i] = logs
+			}
+		}()
+	}
+
+	for i := range ranges {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if fetchErr != nil {
+		return nil, fetchErr
+	}
+	return batches, ctx.Err()
+}
+
+func init() {
+	rootCmd.AddCommand(scanCmd)
+	scanCmd.Flags().StringVar(&scanRPC, "rpc", "", "RPC endpoint to connect to the node")
+	scanCmd.Flags().StringVarP(&scanAddress, "teleporter-address", "t", "", "Teleporter contract address")
+	scanCmd.Flags().Uint64Var(&scanFromBlock, "from-block", 0, "First block of the range to scan")
+	scanCmd.Flags().Uint64Var(&scanToBlock, "to-block", 0, "Last block of the range to scan, defaults to the latest block")
+	scanCmd.Flags().Uint64Var(&scanBatchSize, "batch-size", defaultScanBatchSize, "Number of blocks per log request")
+	scanCmd.Flags().IntVar(&scanConcurrency, "concurrency", defaultScanConcurrency, "Number of concurrent log requests")
+	scanCmd.Flags().StringVar(&scanOutput, "output", "", "File to write every decoded event to, one JSON object per line")
+
+	for _, flag := range []string{"rpc", "teleporter-address", "from-block"} {
+		err := scanCmd.MarkFlagRequired(flag)
+		cobra.CheckErr(err)
+	}
+}
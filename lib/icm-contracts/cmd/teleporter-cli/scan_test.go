@@ -0,0 +1,187 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	teleportermessenger "github.com/ava-labs/icm-contracts/abi-bindings/go/teleporter/TeleporterMessenger"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScanCmd(t *testing.T) {
+	var tests = []struct {
+		name string
+		args []string
+		err  error
+		out  string
+	}{
+		{
+			name: "no args",
+			args: []string{"scan"},
+			err:  fmt.Errorf("required flag(s) \"from-block\", \"rpc\", \"teleporter-address\" not set"),
+		},
+		{
+			name: "help",
+			args: []string{"scan", "--help"},
+			err:  nil,
+			out:  "Pages through the TeleporterMessenger and Warp precompile logs",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := executeTestCmd(t, rootCmd, tt.args...)
+			if tt.err != nil {
+				require.ErrorContains(t, err, tt.err.Error())
+			} else {
+				require.NoError(t, err)
+				require.Contains(t, out, tt.out)
+			}
+		})
+	}
+}
+
+func TestScanRanges(t *testing.T) {
+	var tests = []struct {
+		name      string
+		from      uint64
+		to        uint64
+		batchSize uint64
+		expected  [][2]uint64
+	}{
+		{
+			name:      "single block",
+			from:      5,
+			to:        5,
+			batchSize: 10,
+			expected:  [][2]uint64{{5, 5}},
+		},
+		{
+			name:      "exact batches",
+			from:      0,
+			to:        19,
+			batchSize: 10,
+			expected:  [][2]uint64{{0, 9}, {10, 19}},
+		},
+		{
+			name:      "partial last batch",
+			from:      1,
+			to:        25,
+			batchSize: 10,
+			expected:  [][2]uint64{{1, 10}, {11, 20}, {21, 25}},
+		},
+		{
+			name:      "range ending at max block",
+			from:      ^uint64(0) - 2,
+			to:        ^uint64(0),
+			batchSize: 2,
+			expected:  [][2]uint64{{^uint64(0) - 2, ^uint64(0) - 1}, {^uint64(0), ^uint64(0)}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, scanRanges(tt.from, tt.to, tt.batchSize))
+		})
+	}
+}
+
+func TestScanSummary(t *testing.T) {
+	destinationA := ids.ID{1}
+	destinationB := ids.ID{2}
+	source := ids.ID{3}
+	feeToken := common.HexToAddress("0x0123456789abcdef0123456789abcdef01234567")
+
+	feeInfo := func(amount int64) teleportermessenger.TeleporterFeeInfo {
+		return teleportermessenger.TeleporterFeeInfo{FeeTokenAddress: feeToken, Amount: big.NewInt(amount)}
+	}
+
+	summary := newScanSummary(10, 20)
+	events := []fmt.Stringer{
+		&teleportermessenger.TeleporterMessengerSendCrossChainMessage{
+			MessageID:               ids.ID{10},
+			DestinationBlockchainID: destinationA,
+			FeeInfo:                 feeInfo(30),
+		},
+		&teleportermessenger.TeleporterMessengerSendCrossChainMessage{
+			MessageID:               ids.ID{11},
+			DestinationBlockchainID: destinationA,
+			FeeInfo:                 feeInfo(5),
+		},
+		&teleportermessenger.TeleporterMessengerSendCrossChainMessage{
+			MessageID:               ids.ID{12},
+			DestinationBlockchainID: destinationB,
+			FeeInfo:                 feeInfo(12),
+		},
+		&teleportermessenger.TeleporterMessengerAddFeeAmount{
+			MessageID:      ids.ID{11},
+			UpdatedFeeInfo: feeInfo(8),
+		},
+		// The amount added to a message sent before the range is unknown.
+		&teleportermessenger.TeleporterMessengerAddFeeAmount{
+			MessageID:      ids.ID{9},
+			UpdatedFeeInfo: feeInfo(100),
+		},
+		&teleportermessenger.TeleporterMessengerReceiptReceived{
+			MessageID:               ids.ID{10},
+			DestinationBlockchainID: destinationA,
+			FeeInfo: teleportermessenger.TeleporterFeeInfo{
+				FeeTokenAddress: feeToken,
+				Amount:          big.NewInt(30),
+			},
+		},
+		&teleportermessenger.TeleporterMessengerReceiptReceived{
+			MessageID:               ids.ID{12},
+			DestinationBlockchainID: destinationB,
+			FeeInfo: teleportermessenger.TeleporterFeeInfo{
+				FeeTokenAddress: feeToken,
+				Amount:          big.NewInt(12),
+			},
+		},
+		&teleportermessenger.TeleporterMessengerReceiveCrossChainMessage{
+			MessageID:          ids.ID{20},
+			SourceBlockchainID: source,
+		},
+		&teleportermessenger.TeleporterMessengerMessageExecutionFailed{
+			MessageID:          ids.ID{20},
+			SourceBlockchainID: source,
+		},
+		&teleportermessenger.TeleporterMessengerReceiveCrossChainMessage{
+			MessageID:          ids.ID{21},
+			SourceBlockchainID: source,
+		},
+		&teleportermessenger.TeleporterMessengerMessageExecutionFailed{
+			MessageID:          ids.ID{21},
+			SourceBlockchainID: source,
+		},
+		// A successful retry clears the failure.
+		&teleportermessenger.TeleporterMessengerMessageExecuted{
+			MessageID:          ids.ID{21},
+			SourceBlockchainID: source,
+		},
+	}
+	for _, event := range events {
+		summary.record(event)
+	}
+
+	require.Equal(t, len(events), summary.TeleporterLogs)
+	require.Equal(t, &scanDestinationStats{Sent: 2, ReceiptsReceived: 1}, summary.Destinations[destinationA])
+	require.Equal(t, &scanDestinationStats{Sent: 1, ReceiptsReceived: 1}, summary.Destinations[destinationB])
+	require.Equal(t, &scanSourceStats{Received: 2, Executed: 1, Failed: 2}, summary.Sources[source])
+	require.Equal(t, 2, summary.Fees[feeToken].Receipts)
+	require.Equal(t, big.NewInt(42), summary.Fees[feeToken].Paid)
+	require.Equal(t, big.NewInt(50), summary.Fees[feeToken].Escrowed)
+	require.Equal(t, map[common.Address]*big.Int{feeToken: big.NewInt(8)}, summary.unsettledFees())
+	require.Equal(t, []ids.ID{{11}}, summary.outstandingReceipts())
+	require.Equal(t, []ids.ID{{20}}, summary.failingMessages())
+
+	var out bytes.Buffer
+	require.NoError(t, summary.write(&out))
+	require.Contains(t, out.String(), ids.ID{11}.Hex())
+	require.Contains(t, out.String(), ids.ID{20}.Hex())
+	require.NotContains(t, out.String(), ids.ID{21}.Hex())
+}
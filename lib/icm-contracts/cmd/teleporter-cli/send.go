@@ -0,0 +1,294 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/ava-labs/avalanchego/ids"
+	exampleerc20 "github.com/ava-labs/icm-contracts/abi-bindings/go/mocks/ExampleERC20"
+	teleportermessenger "github.com/ava-labs/icm-contracts/abi-bindings/go/teleporter/TeleporterMessenger"
+	"github.com/ava-labs/subnet-evm/accounts/abi/bind"
+	"github.com/ava-labs/subnet-evm/ethclient"
+	"github.com/ava-labs/subnet-evm/interfaces"
+	"github.com/ava-labs/subnet-evm/precompile/contracts/warp"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+	"sigs.k8s.io/yaml"
+)
+
+var (
+	sendRPC              string
+	sendAddress          string
+	sendInputFile        string
+	sendDestination      string
+	sendDestinationAddr  string
+	sendFeeToken         string
+	sendFeeAmount        string
+	sendRequiredGasLimit uint64
+	sendAllowedRelayers  []string
+	sendMessage          []byte
+	sendGasLimit         uint64
+	sendSigner           signerFlags
+
+	errSendMissingDestination = errors.New("a destination blockchain ID is required")
+	errInvalidAmount          = errors.New("invalid amount")
+)
+
+var sendCmd = &cobra.Command{
+	Use:   "send --rpc RPC_URL --teleporter-address CONTRACT_ADDRESS [--input FILE]",
+	Short: "Signs and submits a sendCrossChainMessage transaction",
+	Long: `Builds a TeleporterMessageInput from flags, or from a JSON or YAML file passed with --input,
+signs a sendCrossChainMessage transaction and submits it to the TeleporterMessenger contract.
+The input file uses the JSON encoding of TeleporterMessageInput, with hex bytes, decimal string
+integers and a CB58 destination blockchain ID, and flags override values read from it. The input
+is validated against the contract's rules before anything is sent. If the message pays a fee,
+the fee token allowance is checked and approved first when needed. The signing key is read from
+the encrypted keystore passed with --keystore, or otherwise from a hex encoded private key in
+the environment variable named by --private-key-env. Once the transaction is accepted, the
+resulting SendCrossChainMessage event and message ID are printed.`,
+	Args: cobra.NoArgs,
+	Run:  sendRun,
+}
+
+func sendRun(cmd *cobra.Command, args []string) {
+	input, err := buildSendInput(cmd)
+	cobra.CheckErr(err)
+
+	address, err := parseAddress(sendAddress)
+	cobra.CheckErr(err)
+	key, err := sendSigner.load()
+	cobra.CheckErr(err)
+
+	ctx := context.Background()
+	c, err := ethclient.DialContext(ctx, sendRPC)
+	cobra.CheckErr(err)
+	defer c.Close()
+
+	// Catch inputs the contract would reject, or accept but never deliver, before paying for them.
+	messenger, err := teleportermessenger.NewTeleporterMessenger(address, c)
+	cobra.CheckErr(err)
+	sourceBlockchainID, err := readBlockchainID(ctx, c, address)
+	cobra.CheckErr(err)
+	cobra.CheckErr(input.Validate(teleportermessenger.ValidationConfig{SourceBlockchainID: sourceBlockchainID}))
+
+	err = approveFeeToken(ctx, c, key, input.FeeInfo, address)
+	cobra.CheckErr(err)
+
+	data, err := teleportermessenger.PackSendCrossChainMessage(input)
+	cobra.CheckErr(err)
+	txData, err := newDynamicFeeTx(ctx, c, crypto.PubkeyToAddress(key.PublicKey), address, data, sendGasLimit, nil)
+	cobra.CheckErr(err)
+	receipt, err := signAndSend(ctx, c, key, txData)
+	cobra.CheckErr(err)
+
+	for _, log := range receipt.Logs {
+		event, err := messenger.ParseSendCrossChainMessage(*log)
+		if err != nil {
+			continue
+		}
+		cmd.Println("SendCrossChainMessage Log:")
+		cmd.Println(event.String() + "\n")
+		cmd.Println("Message ID: " + common.Hash(event.MessageID).Hex())
+		cmd.Println("Send command ran successfully")
+		return
+	}
+	cobra.CheckErr(fmt.Errorf("no SendCrossChainMessage log in transaction %s", receipt.TxHash.Hex()))
+}
+
+// buildSendInput reads the input file, if any, and applies the flags that were set on top of it.
+func buildSendInput(cmd *cobra.Command) (teleportermessenger.TeleporterMessageInput, error) {
+	var input teleportermessenger.TeleporterMessageInput
+	if sendInputFile != "" {
+		b, err := os.ReadFile(sendInputFile)
+		if err != nil {
+			return teleportermessenger.TeleporterMessageInput{}, err
+		}
+		if err := yaml.Unmarshal(b, &input); err != nil {
+			return teleportermessenger.TeleporterMessageInput{}, err
+		}
+	}
+
+	flags := cmd.Flags()
+	var err error
+	if flags.Changed("destination-blockchain-id") {
+		if input.DestinationBlockchainID, err = parseBlockchainID(sendDestination); err != nil {
+			return teleportermessenger.TeleporterMessageInput{}, err
+		}
+	}
+	if flags.Changed("destination-address") {
+		if input.DestinationAddress, err = parseAddress(sendDestinationAddr); err != nil {
+			return teleportermessenger.TeleporterMessageInput{}, err
+		}
+	}
+	if flags.Changed("fee-token") {
+		if input.FeeInfo.FeeTokenAddress, err = parseAddress(sendFeeToken); err != nil {
+			return teleportermessenger.TeleporterMessageInput{}, err
+		}
+	}
+	if flags.Changed("fee-amount") {
+		if input.FeeInfo.Amount, err = parseAmount(sendFeeAmount); err != nil {
+			return teleportermessenger.TeleporterMessageInput{}, err
+		}
+	}
+	if flags.Changed("required-gas-limit") {
+		input.RequiredGasLimit = new(big.Int).SetUint64(sendRequiredGasLimit)
+	}
+	if flags.Changed("allowed-relayers") {
+		input.AllowedRelayerAddresses = nil
+		for _, relayer := range sendAllowedRelayers {
+			address, err := parseAddress(relayer)
+			if err != nil {
+				return teleportermessenger.TeleporterMessageInput{}, err
+			}
+			input.AllowedRelayerAddresses = append(input.AllowedRelayerAddresses, address)
+		}
+	}
+	if flags.Changed("message") {
+		input.Message = sendMessage
+	}
+
+	if input.DestinationBlockchainID == ids.Empty {
+		return teleportermessenger.TeleporterMessageInput{}, errSendMissingDestination
+	}
+	if input.FeeInfo.Amount == nil {
+		input.FeeInfo.Amount = new(big.Int)
+	}
+	if input.RequiredGasLimit == nil {
+		input.RequiredGasLimit = new(big.Int)
+	}
+	if input.AllowedRelayerAddresses == nil {
+		input.AllowedRelayerAddresses = []common.Address{}
+	}
+
+	return input, nil
+}
+
+// parseAmount parses a non-negative decimal or 0x prefixed hex integer amount.
+func parseAmount(s string) (*big.Int, error) {
+	amount, ok := new(big.Int).SetString(s, 0)
+	if !ok || amount.Sign() < 0 {
+		return nil, fmt.Errorf("%w: %s", errInvalidAmount, s)
+	}
+	return amount, nil
+}
+
+// readBlockchainID returns the blockchain ID of the chain served by c, as reported by the Warp
+// precompile. The TeleporterMessenger only stores it once it sends or receives its first message,
+// so its value is only used if the precompile cannot be called.
+func readBlockchainID(ctx context.Context, c bind.ContractCaller, teleporterAddress common.Address) (ids.ID, error) {
+	blockchainID, err := readWarpBlockchainID(ctx, c)
+	if err == nil && blockchainID != ids.Empty {
+		return blockchainID, nil
+	}
+	logger.Debug("Failed to get blockchain ID from the Warp precompile", zap.Error(err))
+
+	messenger, err := teleportermessenger.NewTeleporterMessengerCaller(teleporterAddress, c)
+	if err != nil {
+		return ids.Empty, err
+	}
+	blockchainID, err = messenger.BlockchainID(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		return ids.Empty, fmt.Errorf("failed to get blockchain ID: %w", err)
+	}
+	return blockchainID, nil
+}
+
+// readWarpBlockchainID calls getBlockchainID on the Warp precompile.
+func readWarpBlockchainID(ctx context.Context, c bind.ContractCaller) (ids.ID, error) {
+	data, err := warp.WarpABI.Pack("getBlockchainID")
+	if err != nil {
+		return ids.Empty, err
+	}
+	precompileAddress := common.HexToAddress(ICMPrecompileAddressHex)
+	output, err := c.CallContract(ctx, interfaces.CallMsg{To: &precompileAddress, Data: data}, nil)
+	if err != nil {
+		return ids.Empty, err
+	}
+	var blockchainID [32]byte
+	if err := warp.WarpABI.UnpackIntoInterface(&blockchainID, "getBlockchainID", output); err != nil {
+		return ids.Empty, err
+	}
+	return blockchainID, nil
+}
+
+// approveFeeToken approves the TeleporterMessenger to spend the fee amount of the fee token,
+// if the current allowance does not already cover it.
+func approveFeeToken(
+	ctx context.Context,
+	c ethclient.Client,
+	key *ecdsa.PrivateKey,
+	feeInfo teleportermessenger.TeleporterFeeInfo,
+	teleporterAddress common.Address,
+) error {
+	if feeInfo.Amount == nil || feeInfo.Amount.Sign() == 0 || feeInfo.FeeTokenAddress == (common.Address{}) {
+		return nil
+	}
+	feeToken, err := exampleerc20.NewExampleERC20(feeInfo.FeeTokenAddress, c)
+	if err != nil {
+		return err
+	}
+	owner := crypto.PubkeyToAddress(key.PublicKey)
+	allowance, err := feeToken.Allowance(&bind.CallOpts{Context: ctx}, owner, teleporterAddress)
+	if err != nil {
+		return fmt.Errorf("failed to get fee token allowance: %w", err)
+	}
+	if allowance.Cmp(feeInfo.Amount) >= 0 {
+		return nil
+	}
+
+	opts, err := newTransactor(ctx, c, key)
+	if err != nil {
+		return err
+	}
+	tx, err := feeToken.Approve(opts, teleporterAddress, feeInfo.Amount)
+	if err != nil {
+		return fmt.Errorf("failed to approve fee token: %w", err)
+	}
+	logger.Info(
+		"Approving fee token",
+		zap.String("token", feeInfo.FeeTokenAddress.Hex()),
+		zap.String("amount", feeInfo.Amount.String()),
+	)
+	_, err = waitForSuccess(ctx, c, tx)
+	return err
+}
+
+// addSendInputFlags registers the flags that make up a TeleporterMessageInput.
+func addSendInputFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&sendInputFile, "input", "", "JSON or YAML file describing the TeleporterMessageInput")
+	cmd.Flags().StringVar(
+		&sendDestination,
+		"destination-blockchain-id",
+		"",
+		"Destination blockchain ID (CB58 or hex)",
+	)
+	cmd.Flags().StringVar(&sendDestinationAddr, "destination-address", "", "Destination contract address")
+	cmd.Flags().StringVar(&sendFeeToken, "fee-token", "", "ERC20 token used to pay the relayer fee")
+	cmd.Flags().StringVar(&sendFeeAmount, "fee-amount", "0", "Relayer fee amount, in the fee token's smallest unit")
+	cmd.Flags().Uint64Var(&sendRequiredGasLimit, "required-gas-limit", 0, "Gas limit required to execute the message")
+	cmd.Flags().StringSliceVar(&sendAllowedRelayers, "allowed-relayers", []string{}, "Addresses allowed to relay")
+	cmd.Flags().BytesHexVar(&sendMessage, "message", []byte{}, "Hex encoded message payload")
+}
+
+func init() {
+	rootCmd.AddCommand(sendCmd)
+	sendCmd.Flags().StringVar(&sendRPC, "rpc", "", "RPC endpoint to connect to the node")
+	sendCmd.Flags().StringVarP(&sendAddress, "teleporter-address", "t", "", "Teleporter contract address")
+	addSendInputFlags(sendCmd)
+	sendCmd.Flags().Uint64Var(&sendGasLimit, "gas-limit", 0, "Gas limit of the transaction, estimated if not set")
+	addSignerFlags(sendCmd, &sendSigner)
+
+	err := sendCmd.MarkFlagRequired("rpc")
+	cobra.CheckErr(err)
+	err = sendCmd.MarkFlagRequired("teleporter-address")
+	cobra.CheckErr(err)
+}
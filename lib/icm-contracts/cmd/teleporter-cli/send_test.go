@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/subnet-evm/interfaces"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSendCmd(t *testing.T) {
+	var tests = []struct {
+		name string
+		args []string
+		err  error
+		out  string
+	}{
+		{
+			name: "no args",
+			args: []string{"send"},
+			err:  fmt.Errorf("required flag(s) \"rpc\", \"teleporter-address\" not set"),
+		},
+		{
+			name: "help",
+			args: []string{"send", "--help"},
+			err:  nil,
+			out:  "Builds a TeleporterMessageInput from flags",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := executeTestCmd(t, rootCmd, tt.args...)
+			if tt.err != nil {
+				require.ErrorContains(t, err, tt.err.Error())
+			} else {
+				require.NoError(t, err)
+				require.Contains(t, out, tt.out)
+			}
+		})
+	}
+}
+
+func TestBuildSendInput(t *testing.T) {
+	destinationBlockchainID := ids.ID{1, 2, 3, 4}
+	address := common.HexToAddress("0x0123456789abcdef0123456789abcdef01234567")
+	relayer := common.HexToAddress("0x1111111111111111111111111111111111111111")
+
+	inputFile := filepath.Join(t.TempDir(), "input.yaml")
+	require.NoError(t, os.WriteFile(inputFile, []byte(fmt.Sprintf(`destinationBlockchainID: %s
+destinationAddress: "%s"
+feeInfo:
+  feeTokenAddress: "%s"
+  amount: "10"
+requiredGasLimit: 100000
+message: "0x01020304"
+`, destinationBlockchainID, address.Hex(), address.Hex())), 0o600))
+
+	cmd := &cobra.Command{}
+	addSendInputFlags(cmd)
+	require.NoError(t, cmd.Flags().Set("input", inputFile))
+	require.NoError(t, cmd.Flags().Set("fee-amount", "20"))
+	require.NoError(t, cmd.Flags().Set("allowed-relayers", relayer.Hex()))
+	t.Cleanup(func() {
+		sendInputFile = ""
+		sendFeeAmount = "0"
+		sendAllowedRelayers = []string{}
+	})
+
+	input, err := buildSendInput(cmd)
+	require.NoError(t, err)
+	require.Equal(t, [32]byte(destinationBlockchainID), input.DestinationBlockchainID)
+	require.Equal(t, address, input.DestinationAddress)
+	require.Equal(t, address, input.FeeInfo.FeeTokenAddress)
+	require.Equal(t, big.NewInt(20), input.FeeInfo.Amount)
+	require.Equal(t, big.NewInt(100_000), input.RequiredGasLimit)
+	require.Equal(t, []common.Address{relayer}, input.AllowedRelayerAddresses)
+	require.Equal(t, []byte{1, 2, 3, 4}, input.Message)
+}
+
+// fakeCaller answers calls with the output set for the called address, and fails calls to other addresses.
+type fakeCaller map[common.Address][]byte
+
+func (f fakeCaller) CodeAt(_ context.Context, contract common.Address, _ *big.Int) ([]byte, error) {
+	if _, ok := f[contract]; !ok {
+		return nil, nil
+	}
+	return []byte{1}, nil
+}
+
+func (f fakeCaller) CallContract(_ context.Context, call interfaces.CallMsg, _ *big.Int) ([]byte, error) {
+	output, ok := f[*call.To]
+	if !ok {
+		return nil, errors.New("call failed")
+	}
+	return output, nil
+}
+
+func TestReadBlockchainID(t *testing.T) {
+	teleporterAddress := common.HexToAddress("0x0123456789abcdef0123456789abcdef01234567")
+	precompileAddress := common.HexToAddress(ICMPrecompileAddressHex)
+	warpBlockchainID := ids.ID{1}
+	contractBlockchainID := ids.ID{2}
+
+	var tests = []struct {
+		name     string
+		caller   fakeCaller
+		expected ids.ID
+		err      bool
+	}{
+		{
+			name: "from the Warp precompile",
+			caller: fakeCaller{
+				precompileAddress: warpBlockchainID[:],
+				teleporterAddress: contractBlockchainID[:],
+			},
+			expected: warpBlockchainID,
+		},
+		{
+			name: "precompile returns zero",
+			caller: fakeCaller{
+				precompileAddress: ids.Empty[:],
+				teleporterAddress: contractBlockchainID[:],
+			},
+			expected: contractBlockchainID,
+		},
+		{
+			name:     "precompile call fails",
+			caller:   fakeCaller{teleporterAddress: contractBlockchainID[:]},
+			expected: contractBlockchainID,
+		},
+		{
+			name:   "both calls fail",
+			caller: fakeCaller{},
+			err:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blockchainID, err := readBlockchainID(context.Background(), tt.caller, teleporterAddress)
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, blockchainID)
+		})
+	}
+}
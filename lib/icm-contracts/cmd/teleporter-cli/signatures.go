@@ -0,0 +1,240 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+	"github.com/ava-labs/avalanchego/utils/set"
+	avalancheWarp "github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// Mirrors the request defaults of the awm-relayer signature aggregator API.
+	aggregateSignaturesPath = "/aggregate-signatures"
+	defaultQuorumPercentage = 67
+)
+
+var (
+	errNoSignatureSource       = errors.New("one of --aggregator-url or --signatures is required")
+	errMultipleSignatureSource = errors.New("only one of --aggregator-url or --signatures can be set")
+	errNoSignatures            = errors.New("signatures file contains no signatures")
+	errDuplicateSigner         = errors.New("duplicate signer index")
+	errInvalidSignerIndex      = errors.New("invalid signer index")
+	errSignedMessageMismatch   = errors.New("signed message does not match the unsigned message")
+)
+
+// signatureSource collects the aggregate signature of an unsigned Warp message, either from a
+// signature aggregator service or from a file of signatures collected ahead of time.
+type signatureSource struct {
+	aggregatorURL    string
+	signaturesFile   string
+	signingSubnetID  string
+	quorumPercentage uint64
+}
+
+// aggregateSignatureRequest and aggregateSignatureResponse are the request and response bodies
+// of the signature aggregator's aggregate-signatures endpoint.
+type aggregateSignatureRequest struct {
+	Message          string `json:"message"`
+	SigningSubnetID  string `json:"signing-subnet-id,omitempty"`
+	QuorumPercentage uint64 `json:"quorum-percentage"`
+}
+
+type aggregateSignatureResponse struct {
+	SignedMessage string `json:"signed-message"`
+	Error         string `json:"error"`
+}
+
+// collectedSignature is a single validator's BLS signature of a Warp message, along with the
+// index of the validator in the canonical validator set of the signing subnet.
+type collectedSignature struct {
+	Index     int           `json:"index"`
+	Signature hexutil.Bytes `json:"signature"`
+}
+
+// maxSignerIndex bounds the signer indices of a signatures file, which size the signers bit set
+// of the aggregate signature. Canonical validator sets are far smaller.
+const maxSignerIndex = math.MaxUint16
+
+func addSignatureSourceFlags(cmd *cobra.Command, s *signatureSource) {
+	cmd.Flags().StringVar(&s.aggregatorURL, "aggregator-url", "", "URL of a signature aggregator service")
+	cmd.Flags().StringVar(
+		&s.signaturesFile,
+		"signatures",
+		"",
+		"File holding either a hex encoded signed Warp message, or a JSON list of "+
+			`{"index": N, "signature": "0x..."} validator signatures to aggregate`,
+	)
+	cmd.Flags().StringVar(
+		&s.signingSubnetID,
+		"signing-subnet-id",
+		"",
+		"Subnet ID whose validators sign the message, if not the source blockchain's subnet",
+	)
+	cmd.Flags().Uint64Var(
+		&s.quorumPercentage,
+		"quorum-percentage",
+		defaultQuorumPercentage,
+		"Percentage of stake required to sign the message when using --aggregator-url",
+	)
+}
+
+// signedMessage returns the signed version of unsignedMsg.
+func (s *signatureSource) signedMessage(
+	ctx context.Context,
+	unsignedMsg *avalancheWarp.UnsignedMessage,
+) (*avalancheWarp.Message, error) {
+	switch {
+	case s.aggregatorURL != "" && s.signaturesFile != "":
+		return nil, errMultipleSignatureSource
+	case s.aggregatorURL != "":
+		return s.requestAggregateSignature(ctx, unsignedMsg)
+	case s.signaturesFile != "":
+		b, err := os.ReadFile(s.signaturesFile)
+		if err != nil {
+			return nil, err
+		}
+		return parseCollectedSignatures(unsignedMsg, b)
+	default:
+		return nil, errNoSignatureSource
+	}
+}
+
+func (s *signatureSource) requestAggregateSignature(
+	ctx context.Context,
+	unsignedMsg *avalancheWarp.UnsignedMessage,
+) (*avalancheWarp.Message, error) {
+	endpoint, err := url.Parse(s.aggregatorURL)
+	if err != nil {
+		return nil, err
+	}
+	if endpoint.Path == "" || endpoint.Path == "/" {
+		endpoint.Path = aggregateSignaturesPath
+	}
+
+	reqBody, err := json.Marshal(aggregateSignatureRequest{
+		Message:          hex.EncodeToString(unsignedMsg.Bytes()),
+		SigningSubnetID:  s.signingSubnetID,
+		QuorumPercentage: s.quorumPercentage,
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.String(), bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var aggregateResp aggregateSignatureResponse
+	if err := json.Unmarshal(respBody, &aggregateResp); err != nil || resp.StatusCode != http.StatusOK {
+		if aggregateResp.Error == "" {
+			aggregateResp.Error = string(respBody)
+		}
+		return nil, fmt.Errorf("signature aggregator returned %d: %s", resp.StatusCode, aggregateResp.Error)
+	}
+	signedBytes, err := hex.DecodeString(strings.TrimPrefix(aggregateResp.SignedMessage, "0x"))
+	if err != nil {
+		return nil, err
+	}
+	signedMsg, err := avalancheWarp.ParseMessage(signedBytes)
+	if err != nil {
+		return nil, err
+	}
+	return signedMsg, checkSignedMessage(signedMsg, unsignedMsg)
+}
+
+// checkSignedMessage checks that signedMsg is a signed version of unsignedMsg.
+func checkSignedMessage(signedMsg *avalancheWarp.Message, unsignedMsg *avalancheWarp.UnsignedMessage) error {
+	if signedMsg.UnsignedMessage.ID() != unsignedMsg.ID() {
+		return fmt.Errorf(
+			"%w: got %s, expected %s",
+			errSignedMessageMismatch,
+			signedMsg.UnsignedMessage.ID(),
+			unsignedMsg.ID(),
+		)
+	}
+	return nil
+}
+
+// parseCollectedSignatures builds a signed Warp message from the contents of a signatures file.
+// The file holds either a hex encoded signed Warp message, or a JSON list of individual validator
+// signatures of unsignedMsg that are aggregated into a BitSetSignature.
+func parseCollectedSignatures(
+	unsignedMsg *avalancheWarp.UnsignedMessage,
+	b []byte,
+) (*avalancheWarp.Message, error) {
+	b = bytes.TrimSpace(b)
+	if !bytes.HasPrefix(b, []byte("[")) {
+		signedBytes, err := hex.DecodeString(strings.TrimPrefix(string(b), "0x"))
+		if err != nil {
+			return nil, err
+		}
+		signedMsg, err := avalancheWarp.ParseMessage(signedBytes)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkSignedMessage(signedMsg, unsignedMsg); err != nil {
+			return nil, err
+		}
+		return signedMsg, nil
+	}
+
+	var collected []collectedSignature
+	if err := json.Unmarshal(b, &collected); err != nil {
+		return nil, err
+	}
+	if len(collected) == 0 {
+		return nil, errNoSignatures
+	}
+
+	signers := set.NewBits()
+	signatures := make([]*bls.Signature, 0, len(collected))
+	for _, c := range collected {
+		if c.Index < 0 || c.Index > maxSignerIndex {
+			return nil, fmt.Errorf("%w: %d, expected 0 to %d", errInvalidSignerIndex, c.Index, maxSignerIndex)
+		}
+		if signers.Contains(c.Index) {
+			return nil, fmt.Errorf("%w: %d", errDuplicateSigner, c.Index)
+		}
+		signers.Add(c.Index)
+		sig, err := bls.SignatureFromBytes(c.Signature)
+		if err != nil {
+			return nil, fmt.Errorf("invalid signature for signer %d: %w", c.Index, err)
+		}
+		signatures = append(signatures, sig)
+	}
+	aggregate, err := bls.AggregateSignatures(signatures)
+	if err != nil {
+		return nil, err
+	}
+
+	bitSetSignature := &avalancheWarp.BitSetSignature{Signers: signers.Bytes()}
+	copy(bitSetSignature.Signature[:], bls.SignatureToBytes(aggregate))
+	return avalancheWarp.NewMessage(unsignedMsg, bitSetSignature)
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+	"github.com/ava-labs/avalanchego/utils/set"
+	avalancheWarp "github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCollectedSignatures(t *testing.T) {
+	unsignedMsg, err := avalancheWarp.NewUnsignedMessage(1, ids.ID{1}, []byte{1, 2, 3})
+	require.NoError(t, err)
+	otherMsg, err := avalancheWarp.NewUnsignedMessage(1, ids.ID{2}, []byte{1, 2, 3})
+	require.NoError(t, err)
+
+	var (
+		collected  []collectedSignature
+		publicKeys []*bls.PublicKey
+	)
+	for _, index := range []int{0, 2} {
+		sk, err := bls.NewSecretKey()
+		require.NoError(t, err)
+		publicKeys = append(publicKeys, bls.PublicFromSecretKey(sk))
+		collected = append(collected, collectedSignature{
+			Index:     index,
+			Signature: bls.SignatureToBytes(bls.Sign(sk, unsignedMsg.Bytes())),
+		})
+	}
+	collectedJson, err := json.Marshal(collected)
+	require.NoError(t, err)
+	duplicateJson, err := json.Marshal(append(collected, collected[0]))
+	require.NoError(t, err)
+
+	signedMsg, err := parseCollectedSignatures(unsignedMsg, collectedJson)
+	require.NoError(t, err)
+	signature, ok := signedMsg.Signature.(*avalancheWarp.BitSetSignature)
+	require.True(t, ok)
+	require.Equal(t, set.NewBits(0, 2).Bytes(), signature.Signers)
+	aggregatePublicKey, err := bls.AggregatePublicKeys(publicKeys)
+	require.NoError(t, err)
+	aggregateSignature, err := bls.SignatureFromBytes(signature.Signature[:])
+	require.NoError(t, err)
+	require.True(t, bls.Verify(aggregatePublicKey, aggregateSignature, unsignedMsg.Bytes()))
+
+	var tests = []struct {
+		name  string
+		input []byte
+		err   error
+	}{
+		{
+			name:  "signed message hex",
+			input: []byte("0x" + hex.EncodeToString(signedMsg.Bytes()) + "\n"),
+		},
+		{
+			name:  "signed message for another message",
+			input: []byte(hex.EncodeToString(mustSign(t, otherMsg, signature).Bytes())),
+			err:   errSignedMessageMismatch,
+		},
+		{
+			name:  "empty list",
+			input: []byte("[]"),
+			err:   errNoSignatures,
+		},
+		{
+			name:  "duplicate signer",
+			input: duplicateJson,
+			err:   errDuplicateSigner,
+		},
+		{
+			name:  "negative signer index",
+			input: []byte(`[{"index": -1, "signature": "0x00"}]`),
+			err:   errInvalidSignerIndex,
+		},
+		{
+			name:  "oversized signer index",
+			input: []byte(`[{"index": 1000000000000, "signature": "0x00"}]`),
+			err:   errInvalidSignerIndex,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := parseCollectedSignatures(unsignedMsg, tt.input)
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, signedMsg.Bytes(), msg.Bytes())
+		})
+	}
+}
+
+func mustSign(
+	t *testing.T,
+	unsignedMsg *avalancheWarp.UnsignedMessage,
+	signature *avalancheWarp.BitSetSignature,
+) *avalancheWarp.Message {
+	msg, err := avalancheWarp.NewMessage(unsignedMsg, signature)
+	require.NoError(t, err)
+	return msg
+}
+
+func TestRequestAggregateSignature(t *testing.T) {
+	unsignedMsg, err := avalancheWarp.NewUnsignedMessage(1, ids.ID{1}, []byte{1, 2, 3})
+	require.NoError(t, err)
+	signedMsg := mustSign(t, unsignedMsg, &avalancheWarp.BitSetSignature{Signers: set.NewBits(1).Bytes()})
+	otherMsg, err := avalancheWarp.NewUnsignedMessage(1, ids.ID{2}, []byte{1, 2, 3})
+	require.NoError(t, err)
+	responseMsg := signedMsg
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, aggregateSignaturesPath, r.URL.Path)
+		var req aggregateSignatureRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.Equal(t, hex.EncodeToString(unsignedMsg.Bytes()), req.Message)
+		if req.QuorumPercentage > 100 {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error": "invalid quorum percentage"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"signed-message": "` + hex.EncodeToString(responseMsg.Bytes()) + `"}`))
+	}))
+	defer server.Close()
+
+	source := signatureSource{aggregatorURL: server.URL, quorumPercentage: defaultQuorumPercentage}
+	msg, err := source.signedMessage(context.Background(), unsignedMsg)
+	require.NoError(t, err)
+	require.Equal(t, signedMsg.Bytes(), msg.Bytes())
+
+	source.quorumPercentage = 101
+	_, err = source.signedMessage(context.Background(), unsignedMsg)
+	require.ErrorContains(t, err, "invalid quorum percentage")
+
+	source.quorumPercentage = defaultQuorumPercentage
+	responseMsg = mustSign(t, otherMsg, &avalancheWarp.BitSetSignature{Signers: set.NewBits(1).Bytes()})
+	_, err = source.signedMessage(context.Background(), unsignedMsg)
+	require.ErrorIs(t, err, errSignedMessageMismatch)
+}
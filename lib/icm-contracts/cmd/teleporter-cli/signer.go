@@ -0,0 +1,177 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	gasUtils "github.com/ava-labs/icm-contracts/utils/gas-utils"
+	"github.com/ava-labs/subnet-evm/accounts/abi/bind"
+	"github.com/ava-labs/subnet-evm/accounts/keystore"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/ethclient"
+	"github.com/ava-labs/subnet-evm/interfaces"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultPrivateKeyEnv       = "PRIVATE_KEY"
+	defaultKeystorePasswordEnv = "KEYSTORE_PASSWORD"
+)
+
+var errTransactionFailed = errors.New("transaction failed")
+
+// signerFlags are the flags shared by every command that signs transactions.
+type signerFlags struct {
+	keystore    string
+	passwordEnv string
+	keyEnv      string
+}
+
+func addSignerFlags(cmd *cobra.Command, s *signerFlags) {
+	cmd.Flags().StringVar(&s.keystore, "keystore", "", "Encrypted keystore file of the signing key")
+	cmd.Flags().StringVar(
+		&s.passwordEnv,
+		"keystore-password-env",
+		defaultKeystorePasswordEnv,
+		"Environment variable holding the keystore password",
+	)
+	cmd.Flags().StringVar(
+		&s.keyEnv,
+		"private-key-env",
+		defaultPrivateKeyEnv,
+		"Environment variable holding the hex encoded signing key, used if --keystore is not set",
+	)
+}
+
+// load returns the signing key from the keystore file if one was provided, and from
+// the private key environment variable otherwise.
+func (s *signerFlags) load() (*ecdsa.PrivateKey, error) {
+	if s.keystore != "" {
+		keyJson, err := os.ReadFile(s.keystore)
+		if err != nil {
+			return nil, err
+		}
+		key, err := keystore.DecryptKey(keyJson, os.Getenv(s.passwordEnv))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt keystore %s: %w", s.keystore, err)
+		}
+		return key.PrivateKey, nil
+	}
+
+	hexKey, ok := os.LookupEnv(s.keyEnv)
+	if !ok || hexKey == "" {
+		return nil, fmt.Errorf("no signing key: set --keystore or the %s environment variable", s.keyEnv)
+	}
+	return crypto.HexToECDSA(strings.TrimPrefix(hexKey, "0x"))
+}
+
+// newTransactor returns transaction options for the binding transactors that sign with key
+// for the chain served by c.
+func newTransactor(ctx context.Context, c ethclient.Client, key *ecdsa.PrivateKey) (*bind.TransactOpts, error) {
+	chainID, err := c.ChainID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	opts, err := bind.NewKeyedTransactorWithChainID(key, chainID)
+	if err != nil {
+		return nil, err
+	}
+	opts.Context = ctx
+	return opts, nil
+}
+
+// newDynamicFeeTx builds an unsigned transaction from the key's address to the given contract,
+// using the same fee parameters as the e2e tests. If gasLimit is zero, the gas is estimated.
+func newDynamicFeeTx(
+	ctx context.Context,
+	c ethclient.Client,
+	from common.Address,
+	to common.Address,
+	data []byte,
+	gasLimit uint64,
+	accessList types.AccessList,
+) (*types.DynamicFeeTx, error) {
+	chainID, err := c.ChainID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	baseFee, err := c.EstimateBaseFee(ctx)
+	if err != nil {
+		return nil, err
+	}
+	gasTipCap, err := c.SuggestGasTipCap(ctx)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := c.NonceAt(ctx, from, nil)
+	if err != nil {
+		return nil, err
+	}
+	gasFeeCap := new(big.Int).Mul(baseFee, big.NewInt(gasUtils.BaseFeeFactor))
+	gasFeeCap.Add(gasFeeCap, big.NewInt(gasUtils.MaxPriorityFeePerGas))
+
+	if gasLimit == 0 {
+		gasLimit, err = c.EstimateGas(ctx, interfaces.CallMsg{
+			From:       from,
+			To:         &to,
+			Data:       data,
+			AccessList: accessList,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to estimate gas: %w", err)
+		}
+	}
+
+	return &types.DynamicFeeTx{
+		ChainID:    chainID,
+		Nonce:      nonce,
+		To:         &to,
+		Gas:        gasLimit,
+		GasFeeCap:  gasFeeCap,
+		GasTipCap:  gasTipCap,
+		Value:      common.Big0,
+		Data:       data,
+		AccessList: accessList,
+	}, nil
+}
+
+// signAndSend signs the transaction with key, submits it, and waits for a successful receipt.
+func signAndSend(
+	ctx context.Context,
+	c ethclient.Client,
+	key *ecdsa.PrivateKey,
+	txData *types.DynamicFeeTx,
+) (*types.Receipt, error) {
+	tx, err := types.SignNewTx(key, types.LatestSignerForChainID(txData.ChainID), txData)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.SendTransaction(ctx, tx); err != nil {
+		return nil, err
+	}
+	return waitForSuccess(ctx, c, tx)
+}
+
+// waitForSuccess waits for tx to be mined, and returns an error if it reverted.
+func waitForSuccess(ctx context.Context, c ethclient.Client, tx *types.Transaction) (*types.Receipt, error) {
+	logger.Info("Waiting for transaction to be mined", zap.String("txHash", tx.Hash().Hex()))
+	receipt, err := bind.WaitMined(ctx, c, tx)
+	if err != nil {
+		return nil, err
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return receipt, fmt.Errorf("%w: %s", errTransactionFailed, tx.Hash().Hex())
+	}
+	return receipt, nil
+}
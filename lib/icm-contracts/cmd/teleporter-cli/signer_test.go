@@ -0,0 +1,50 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignerFlagsLoad(t *testing.T) {
+	const (
+		hexKey   = "56289e99c94b6912bfc12adc093c9b51124f0dc54ac7a766b2bc5ccf558d8027"
+		expected = "0x8db97C7cEcE249c2b98bDC0226Cc4C2A57BF52FC"
+	)
+
+	var tests = []struct {
+		name  string
+		env   string
+		isErr bool
+	}{
+		{
+			name: "hex key",
+			env:  hexKey,
+		},
+		{
+			name: "0x prefixed hex key",
+			env:  "0x" + hexKey,
+		},
+		{
+			name:  "missing key",
+			env:   "",
+			isErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TELEPORTER_CLI_TEST_KEY", tt.env)
+			s := signerFlags{keyEnv: "TELEPORTER_CLI_TEST_KEY"}
+			key, err := s.load()
+			if tt.isErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, common.HexToAddress(expected), crypto.PubkeyToAddress(key.PublicKey))
+		})
+	}
+}
@@ -0,0 +1,424 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+
+	"github.com/ava-labs/avalanchego/ids"
+	avalancheWarp "github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	teleportermessenger "github.com/ava-labs/icm-contracts/abi-bindings/go/teleporter/TeleporterMessenger"
+	"github.com/ava-labs/subnet-evm/accounts/abi"
+	"github.com/ava-labs/subnet-evm/ethclient"
+	"github.com/ava-labs/subnet-evm/interfaces"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var (
+	simulateSourceRPC          string
+	simulateDestinationRPC     string
+	simulateAddress            string
+	simulateDestinationAddress string
+	simulateSignedMessage      string
+	simulateFrom               string
+	simulateRewardAddress      string
+	simulateSignatures         signatureSource
+
+	errSimulateNoMessage       = errors.New("pass either --signed-message or a source transaction hash")
+	errSimulateMultipleMessage = errors.New("only one of --signed-message or a source transaction hash can be passed")
+	errSimulateNoSourceRPC     = errors.New("--source-rpc is required with a source transaction hash")
+)
+
+// receiveTeleporterMessageSelector is the selector of ITeleporterReceiver.receiveTeleporterMessage,
+// which the TeleporterMessenger calls to execute a message.
+var receiveTeleporterMessageSelector = crypto.Keccak256([]byte("receiveTeleporterMessage(bytes32,address,bytes)"))[:4]
+
+var simulateDeliveryCmd = &cobra.Command{
+	Use: "simulate-delivery --teleporter-address CONTRACT_ADDRESS [--destination-rpc RPC_URL] " +
+		"(--signed-message HEX | --source-rpc RPC_URL (--aggregator-url URL | --signatures FILE) TRANSACTION_HASH)",
+	Short: "Simulates the delivery of a Teleporter message without submitting a transaction",
+	Long: `Given a signed Warp message carrying a Teleporter message, or the hash of the source chain
+transaction that sent it along with a signature source, this command calls receiveCrossChainMessage
+on the destination chain with eth_call, carrying the signed message as a predicate in the access
+list, and with the gas limit the relayer would use. It reports:
+
+  - whether delivery succeeds, or the reason it reverts
+  - the gas delivery uses, and the margin left by the relayer's gas limit
+  - whether executing the message on its destination contract succeeds, or whether
+    MessageExecutionFailed would be emitted, along with the revert reason
+  - the gas execution uses, and the margin left by the message's required gas limit
+
+Execution is simulated by calling receiveTeleporterMessage on the destination contract from the
+TeleporterMessenger's address, so the execution gas figures are approximate. The call is made from
+--from, which defaults to the message's first allowed relayer.
+
+The simulation does not verify the message's Warp signature: eth_call does not verify the
+predicates of the access list, so delivery succeeds in the simulation even if the aggregate
+signature is invalid or below the quorum. SignatureVerified is always false in the output. Use
+"warp verify" to check the signature against the source subnet's validator set.`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  simulateDeliveryRun,
+}
+
+// deliverySimulation is the outcome of simulating the delivery of a Teleporter message. The gas
+// margins are negative when the gas limit is too low. SignatureVerified is always false, since
+// eth_call does not verify Warp predicates, so DeliverySucceeds does not account for the message's
+// signature.
+type deliverySimulation struct {
+	From                 common.Address
+	RelayerRewardAddress common.Address
+	SignatureVerified    bool
+	DeliverySucceeds     bool
+	DeliveryError        string `json:",omitempty"`
+	GasLimit             uint64
+	GasUsed              *uint64 `json:",omitempty"`
+	GasMargin            *int64  `json:",omitempty"`
+	ExecutionSucceeds    bool
+	ExecutionError       string `json:",omitempty"`
+	RequiredGasLimit     *big.Int
+	ExecutionGasUsed     *uint64 `json:",omitempty"`
+	ExecutionGasMargin   *int64  `json:",omitempty"`
+}
+
+func simulateDeliveryRun(cmd *cobra.Command, args []string) {
+	sourceAddress, err := parseAddress(simulateAddress)
+	cobra.CheckErr(err)
+
+	ctx := context.Background()
+	signedMsg, err := simulationSignedMessage(ctx, args, sourceAddress)
+	cobra.CheckErr(err)
+	teleporterMessage, err := parseTeleporterWarpMessage(&signedMsg.UnsignedMessage, sourceAddress)
+	cobra.CheckErr(err)
+	cmd.Println("ICM Message ID: " + signedMsg.ID().Hex())
+	cmd.Println("Teleporter Message:")
+	cmd.Println(teleporterMessage.String() + "\n")
+
+	destinationRPC, destinationAddress, err := resolveDestination(
+		simulateDestinationRPC,
+		simulateDestinationAddress,
+		teleporterMessage.DestinationBlockchainID,
+		sourceAddress,
+	)
+	cobra.CheckErr(err)
+	destinationClient, err := ethclient.DialContext(ctx, destinationRPC)
+	cobra.CheckErr(err)
+	defer destinationClient.Close()
+
+	from := common.Address{}
+	if len(teleporterMessage.AllowedRelayerAddresses) > 0 {
+		from = teleporterMessage.AllowedRelayerAddresses[0]
+	}
+	if simulateFrom != "" {
+		from, err = parseAddress(simulateFrom)
+		cobra.CheckErr(err)
+	}
+	rewardAddress := from
+	if simulateRewardAddress != "" {
+		rewardAddress, err = parseAddress(simulateRewardAddress)
+		cobra.CheckErr(err)
+	}
+
+	registry, err := newBindingABIRegistry()
+	cobra.CheckErr(err)
+	simulation := &deliverySimulation{
+		From:                 from,
+		RelayerRewardAddress: rewardAddress,
+		RequiredGasLimit:     teleporterMessage.RequiredGasLimit,
+	}
+	cobra.CheckErr(simulateReceive(
+		ctx,
+		destinationClient,
+		registry,
+		destinationAddress,
+		signedMsg,
+		teleporterMessage,
+		simulation,
+	))
+	cobra.CheckErr(simulateExecution(
+		ctx,
+		destinationClient,
+		registry,
+		destinationAddress,
+		signedMsg.SourceChainID,
+		teleporterMessage,
+		simulation,
+	))
+	logger.Warn("The Warp signature is not verified by the simulation, check it with warp verify")
+	if simulation.GasMargin != nil && *simulation.GasMargin < 0 {
+		logger.Warn("Relayer gas limit is below the gas delivery uses", zap.Int64("margin", *simulation.GasMargin))
+	}
+	if simulation.ExecutionGasMargin != nil && *simulation.ExecutionGasMargin < 0 {
+		logger.Warn(
+			"Required gas limit is below the gas execution uses",
+			zap.Int64("margin", *simulation.ExecutionGasMargin),
+		)
+	}
+
+	simulationJson, err := json.MarshalIndent(simulation, "", "  ")
+	cobra.CheckErr(err)
+	cmd.Println("Delivery Simulation:\n" + string(simulationJson) + "\n")
+	cmd.Println("Simulate-delivery command ran successfully")
+}
+
+// simulationSignedMessage returns the signed message passed with --signed-message, or otherwise
+// extracts the Teleporter Warp message of the source transaction passed as an argument and
+// collects its signature.
+func simulationSignedMessage(
+	ctx context.Context,
+	args []string,
+	sourceAddress common.Address,
+) (*avalancheWarp.Message, error) {
+	switch {
+	case simulateSignedMessage != "" && len(args) > 0:
+		return nil, errSimulateMultipleMessage
+	case simulateSignedMessage != "":
+		b, err := hex.DecodeString(strings.TrimPrefix(simulateSignedMessage, "0x"))
+		if err != nil {
+			return nil, err
+		}
+		return avalancheWarp.ParseMessage(b)
+	case len(args) == 0:
+		return nil, errSimulateNoMessage
+	case simulateSourceRPC == "":
+		return nil, errSimulateNoSourceRPC
+	}
+
+	sourceClient, err := ethclient.DialContext(ctx, simulateSourceRPC)
+	if err != nil {
+		return nil, err
+	}
+	defer sourceClient.Close()
+	receipt, err := sourceClient.TransactionReceipt(ctx, common.HexToHash(args[0]))
+	if err != nil {
+		return nil, err
+	}
+	unsignedMsg, _, err := extractTeleporterWarpMessage(receipt, sourceAddress)
+	if err != nil {
+		return nil, err
+	}
+	return simulateSignatures.signedMessage(ctx, unsignedMsg)
+}
+
+// simulateReceive calls receiveCrossChainMessage with the gas limit the relayer would use, then
+// estimates the gas delivery actually needs.
+func simulateReceive(
+	ctx context.Context,
+	c ethclient.Client,
+	registry *abiRegistry,
+	teleporterAddress common.Address,
+	signedMsg *avalancheWarp.Message,
+	teleporterMessage *teleportermessenger.TeleporterMessage,
+	simulation *deliverySimulation,
+) error {
+	gasLimit, err := receiveMessageGasLimit(signedMsg, teleporterMessage)
+	if err != nil {
+		return err
+	}
+	callData, err := teleportermessenger.PackReceiveCrossChainMessage(0, simulation.RelayerRewardAddress)
+	if err != nil {
+		return err
+	}
+	simulation.GasLimit = gasLimit
+	callMsg := interfaces.CallMsg{
+		From:       simulation.From,
+		To:         &teleporterAddress,
+		Gas:        gasLimit,
+		Data:       callData,
+		AccessList: predicateAccessList(signedMsg),
+	}
+	if _, err := c.CallContract(ctx, callMsg, nil); err != nil {
+		simulation.DeliveryError = registry.revertReason(err)
+	} else {
+		simulation.DeliverySucceeds = true
+	}
+
+	callMsg.Gas = 0
+	gasUsed, err := c.EstimateGas(ctx, callMsg)
+	if err != nil {
+		logger.Info("Failed to estimate delivery gas", zap.Error(err))
+		return nil
+	}
+	simulation.GasUsed, simulation.GasMargin = gasFigures(gasLimit, gasUsed)
+	return nil
+}
+
+// simulateExecution calls receiveTeleporterMessage on the message's destination contract from the
+// TeleporterMessenger's address, with the message's required gas limit, then estimates the gas
+// execution actually needs. The intrinsic gas of the simulated transaction is not counted.
+func simulateExecution(
+	ctx context.Context,
+	c ethclient.Client,
+	registry *abiRegistry,
+	teleporterAddress common.Address,
+	sourceBlockchainID ids.ID,
+	teleporterMessage *teleportermessenger.TeleporterMessage,
+	simulation *deliverySimulation,
+) error {
+	code, err := c.CodeAt(ctx, teleporterMessage.DestinationAddress, nil)
+	if err != nil {
+		return err
+	}
+	if len(code) == 0 {
+		simulation.ExecutionError = "destination address has no code, the message would be stored as a failed execution"
+		return nil
+	}
+
+	callData, err := packReceiveTeleporterMessage(
+		sourceBlockchainID,
+		teleporterMessage.OriginSenderAddress,
+		teleporterMessage.Message,
+	)
+	if err != nil {
+		return err
+	}
+	requiredGasLimit := teleporterMessage.RequiredGasLimit.Uint64()
+	intrinsicGas := callIntrinsicGas(callData)
+	callMsg := interfaces.CallMsg{
+		From: teleporterAddress,
+		To:   &teleporterMessage.DestinationAddress,
+		Gas:  requiredGasLimit + intrinsicGas,
+		Data: callData,
+	}
+	if _, err := c.CallContract(ctx, callMsg, nil); err != nil {
+		simulation.ExecutionError = registry.revertReason(err)
+	} else {
+		simulation.ExecutionSucceeds = true
+	}
+
+	callMsg.Gas = 0
+	gasUsed, err := c.EstimateGas(ctx, callMsg)
+	if err != nil {
+		logger.Info("Failed to estimate execution gas", zap.Error(err))
+		return nil
+	}
+	simulation.ExecutionGasUsed, simulation.ExecutionGasMargin = gasFigures(
+		requiredGasLimit,
+		gasUsed-min(gasUsed, intrinsicGas),
+	)
+	return nil
+}
+
+// packReceiveTeleporterMessage packs the call the TeleporterMessenger makes to a message's
+// destination contract to execute it.
+func packReceiveTeleporterMessage(
+	sourceBlockchainID ids.ID,
+	originSenderAddress common.Address,
+	message []byte,
+) ([]byte, error) {
+	bytes32Type, err := abi.NewType("bytes32", "", nil)
+	if err != nil {
+		return nil, err
+	}
+	addressType, err := abi.NewType("address", "", nil)
+	if err != nil {
+		return nil, err
+	}
+	bytesType, err := abi.NewType("bytes", "", nil)
+	if err != nil {
+		return nil, err
+	}
+	args := abi.Arguments{{Type: bytes32Type}, {Type: addressType}, {Type: bytesType}}
+	packed, err := args.Pack(sourceBlockchainID, originSenderAddress, message)
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]byte{}, receiveTeleporterMessageSelector...), packed...), nil
+}
+
+// callIntrinsicGas returns the gas charged for a call transaction with the given data before
+// any code is executed.
+func callIntrinsicGas(data []byte) uint64 {
+	gas := params.TxGas
+	for _, b := range data {
+		if b == 0 {
+			gas += params.TxDataZeroGas
+		} else {
+			gas += params.TxDataNonZeroGasEIP2028
+		}
+	}
+	return gas
+}
+
+// gasFigures returns the gas used and the margin left by gasLimit.
+func gasFigures(gasLimit uint64, gasUsed uint64) (*uint64, *int64) {
+	margin := int64(gasLimit) - int64(gasUsed)
+	return &gasUsed, &margin
+}
+
+// revertReason decodes the revert data of an eth_call error against the registry's ABIs, falling
+// back to the error message.
+func (r *abiRegistry) revertReason(err error) string {
+	var dataErr interface{ ErrorData() interface{} }
+	if !errors.As(err, &dataErr) {
+		return err.Error()
+	}
+	data, ok := dataErr.ErrorData().(string)
+	if !ok {
+		return err.Error()
+	}
+	b, decodeErr := hexutil.Decode(data)
+	if decodeErr != nil {
+		return err.Error()
+	}
+	if reason := r.decodeRevert(b); reason != "" {
+		return reason
+	}
+	return err.Error()
+}
+
+func init() {
+	rootCmd.AddCommand(simulateDeliveryCmd)
+	simulateDeliveryCmd.Flags().StringVar(&simulateSourceRPC, "source-rpc", "", "RPC endpoint of the source chain")
+	simulateDeliveryCmd.Flags().StringVar(
+		&simulateDestinationRPC,
+		"destination-rpc",
+		"",
+		"RPC endpoint of the destination chain, resolved from the network profiles if not set",
+	)
+	simulateDeliveryCmd.Flags().StringVarP(
+		&simulateAddress,
+		"teleporter-address",
+		"t",
+		"",
+		"Teleporter contract address",
+	)
+	simulateDeliveryCmd.Flags().StringVar(
+		&simulateDestinationAddress,
+		"destination-teleporter-address",
+		"",
+		"Teleporter contract address on the destination chain, if different from --teleporter-address",
+	)
+	simulateDeliveryCmd.Flags().StringVar(
+		&simulateSignedMessage,
+		"signed-message",
+		"",
+		"Hex encoded signed Warp message to simulate the delivery of",
+	)
+	simulateDeliveryCmd.Flags().StringVar(
+		&simulateFrom,
+		"from",
+		"",
+		"Address to simulate the delivery from, defaults to the message's first allowed relayer",
+	)
+	simulateDeliveryCmd.Flags().StringVar(
+		&simulateRewardAddress,
+		"relayer-reward-address",
+		"",
+		"Address credited with the relayer reward, defaults to --from",
+	)
+	addSignatureSourceFlags(simulateDeliveryCmd, &simulateSignatures)
+
+	err := simulateDeliveryCmd.MarkFlagRequired("teleporter-address")
+	cobra.CheckErr(err)
+}
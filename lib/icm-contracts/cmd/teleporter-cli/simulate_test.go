@@ -0,0 +1,122 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	testmessenger "github.com/ava-labs/icm-contracts/abi-bindings/go/teleporter/tests/TestMessenger"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimulateDeliveryCmd(t *testing.T) {
+	var tests = []struct {
+		name string
+		args []string
+		err  error
+		out  string
+	}{
+		{
+			name: "no args",
+			args: []string{"simulate-delivery"},
+			err:  fmt.Errorf(`required flag(s) "teleporter-address" not set`),
+		},
+		{
+			name: "help",
+			args: []string{"simulate-delivery", "--help"},
+			err:  nil,
+			out:  "Given a signed Warp message carrying a Teleporter message",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := executeTestCmd(t, rootCmd, tt.args...)
+			if tt.err != nil {
+				require.ErrorContains(t, err, tt.err.Error())
+			} else {
+				require.NoError(t, err)
+				require.Contains(t, out, tt.out)
+			}
+		})
+	}
+}
+
+func TestPackReceiveTeleporterMessage(t *testing.T) {
+	sourceBlockchainID := ids.ID{1, 2, 3}
+	originSenderAddress := common.HexToAddress("0x0123456789abcdef0123456789abcdef01234567")
+	message := []byte{1, 2, 3, 4}
+
+	receiverABI, err := testmessenger.TestMessengerMetaData.GetAbi()
+	require.NoError(t, err)
+	expected, err := receiverABI.Pack("receiveTeleporterMessage", sourceBlockchainID, originSenderAddress, message)
+	require.NoError(t, err)
+
+	packed, err := packReceiveTeleporterMessage(sourceBlockchainID, originSenderAddress, message)
+	require.NoError(t, err)
+	require.Equal(t, expected, packed)
+}
+
+func TestCallIntrinsicGas(t *testing.T) {
+	require.Equal(t, uint64(21_000), callIntrinsicGas(nil))
+	require.Equal(t, uint64(21_000+4+16), callIntrinsicGas([]byte{0, 1}))
+}
+
+func TestGasFigures(t *testing.T) {
+	used, margin := gasFigures(100_000, 80_000)
+	require.Equal(t, uint64(80_000), *used)
+	require.Equal(t, int64(20_000), *margin)
+
+	_, margin = gasFigures(100_000, 120_000)
+	require.Equal(t, int64(-20_000), *margin)
+}
+
+type testDataError struct {
+	data interface{}
+}
+
+func (e testDataError) Error() string          { return "execution reverted" }
+func (e testDataError) ErrorData() interface{} { return e.data }
+
+func TestRevertReason(t *testing.T) {
+	registry, err := newBindingABIRegistry()
+	require.NoError(t, err)
+
+	var tests = []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{
+			name: "error string",
+			err: testDataError{data: "0x08c379a0" +
+				"0000000000000000000000000000000000000000000000000000000000000020" +
+				"0000000000000000000000000000000000000000000000000000000000000004" +
+				"6f6f707300000000000000000000000000000000000000000000000000000000"},
+			expected: "oops",
+		},
+		{
+			name:     "no data",
+			err:      errors.New("connection refused"),
+			expected: "connection refused",
+		},
+		{
+			name:     "invalid data",
+			err:      testDataError{data: "not hex"},
+			expected: "execution reverted",
+		},
+		{
+			name:     "wrapped",
+			err:      fmt.Errorf("call failed: %w", testDataError{data: "0x"}),
+			expected: "call failed: execution reverted",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, registry.revertReason(tt.err))
+		})
+	}
+}
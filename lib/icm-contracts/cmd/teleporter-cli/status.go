@@ -0,0 +1,316 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/ids"
+	teleportermessenger "github.com/ava-labs/icm-contracts/abi-bindings/go/teleporter/TeleporterMessenger"
+	"github.com/ava-labs/subnet-evm/accounts/abi/bind"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/ethclient"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+)
+
+// messageState is the lifecycle state of a Teleporter message as reported by the status command.
+type messageState string
+
+const (
+	messageStateSent            messageState = "sent"
+	messageStateFeeAdded        messageState = "fee-added"
+	messageStateDelivered       messageState = "delivered"
+	messageStateExecuted        messageState = "executed"
+	messageStateExecutionFailed messageState = "execution-failed"
+	messageStateReceiptReturned messageState = "receipt-returned"
+
+	sourceChain      = "source"
+	destinationChain = "destination"
+)
+
+var (
+	statusSourceRPC          string
+	statusDestinationRPC     string
+	statusAddress            string
+	statusDestinationAddress string
+	statusFromBlock          uint64
+
+	errStatusMessageNotFound    = errors.New("message not found on the source or destination chain")
+	errStatusDestinationUnknown = errors.New("message not found on the source chain, so its destination is unknown")
+)
+
+var statusCmd = &cobra.Command{
+	Use: "status --source-rpc RPC_URL [--destination-rpc RPC_URL] " +
+		"--teleporter-address CONTRACT_ADDRESS MESSAGE_ID",
+	Short: "Reports the lifecycle state of a Teleporter message across its source and destination chains",
+	Long: `Given a Teleporter message ID, this command queries the TeleporterMessenger contract
+on both the source and destination chains and reports the current lifecycle state of the
+message: sent, fee-added, delivered, executed, execution-failed, or receipt-returned.
+The report includes the block and transaction in which each transition happened. The
+TeleporterMessenger is assumed to be deployed at the same address on both chains unless
+--destination-teleporter-address is provided. If --destination-rpc is not set, the destination
+chain's endpoint and Teleporter address are taken from the network profile with the message's
+destination blockchain ID, as found on the source chain.`,
+	Args: cobra.ExactArgs(1),
+	Run:  statusRun,
+}
+
+type statusTransition struct {
+	State           messageState
+	Chain           string
+	BlockNumber     uint64
+	TransactionHash common.Hash
+}
+
+type messageStatus struct {
+	MessageID               common.Hash
+	DestinationBlockchainID ids.ID `json:",omitempty"`
+	State                   messageState
+	MessageHash             common.Hash
+	FeeInfo                 teleportermessenger.TeleporterFeeInfo
+	Received                bool
+	RelayerRewardAddress    common.Address
+	FailedMessageHash       common.Hash
+	Transitions             []statusTransition
+}
+
+func (s *messageStatus) addTransition(state messageState, chain string, log types.Log) {
+	s.Transitions = append(s.Transitions, statusTransition{
+		State:           state,
+		Chain:           chain,
+		BlockNumber:     log.BlockNumber,
+		TransactionHash: log.TxHash,
+	})
+}
+
+func (s *messageStatus) hasTransition(state messageState) bool {
+	for _, t := range s.Transitions {
+		if t.State == state {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveState picks the single lifecycle state to report. A pending failed execution
+// takes precedence over a returned receipt, since receipts are returned regardless of
+// whether execution succeeded and the failure is what the operator needs to act on.
+func (s *messageStatus) resolveState() error {
+	switch {
+	case s.FailedMessageHash != (common.Hash{}):
+		s.State = messageStateExecutionFailed
+	case s.hasTransition(messageStateReceiptReturned):
+		s.State = messageStateReceiptReturned
+	case s.hasTransition(messageStateExecuted):
+		s.State = messageStateExecuted
+	case s.hasTransition(messageStateExecutionFailed):
+		s.State = messageStateExecutionFailed
+	case s.Received || s.hasTransition(messageStateDelivered):
+		s.State = messageStateDelivered
+	case s.hasTransition(messageStateFeeAdded):
+		s.State = messageStateFeeAdded
+	case s.MessageHash != (common.Hash{}) || s.hasTransition(messageStateSent):
+		s.State = messageStateSent
+	default:
+		return errStatusMessageNotFound
+	}
+	return nil
+}
+
+func statusRun(cmd *cobra.Command, args []string) {
+	messageID, err := parseHexID(args[0])
+	cobra.CheckErr(err)
+
+	sourceAddress, err := parseAddress(statusAddress)
+	cobra.CheckErr(err)
+
+	ctx := context.Background()
+	sourceClient, err := ethclient.DialContext(ctx, statusSourceRPC)
+	cobra.CheckErr(err)
+	defer sourceClient.Close()
+	sourceMessenger, err := teleportermessenger.NewTeleporterMessenger(sourceAddress, sourceClient)
+	cobra.CheckErr(err)
+
+	status := &messageStatus{MessageID: common.Hash(messageID)}
+	cobra.CheckErr(querySourceStatus(ctx, sourceMessenger, messageID, status))
+
+	if statusDestinationRPC == "" && status.DestinationBlockchainID == (ids.ID{}) {
+		cobra.CheckErr(fmt.Errorf("%w: pass --destination-rpc", errStatusDestinationUnknown))
+	}
+	destinationRPC, destinationAddress, err := resolveDestination(
+		statusDestinationRPC,
+		statusDestinationAddress,
+		status.DestinationBlockchainID,
+		sourceAddress,
+	)
+	cobra.CheckErr(err)
+	destinationClient, err := ethclient.DialContext(ctx, destinationRPC)
+	cobra.CheckErr(err)
+	defer destinationClient.Close()
+	destinationMessenger, err := teleportermessenger.NewTeleporterMessenger(destinationAddress, destinationClient)
+	cobra.CheckErr(err)
+
+	cobra.CheckErr(queryDestinationStatus(ctx, destinationMessenger, messageID, status))
+	cobra.CheckErr(status.resolveState())
+
+	statusJson, err := json.MarshalIndent(status, "", "  ")
+	cobra.CheckErr(err)
+	cmd.Println("Message Status:\n" + string(statusJson) + "\n")
+	cmd.Println("Status command ran successfully")
+}
+
+func querySourceStatus(
+	ctx context.Context,
+	messenger *teleportermessenger.TeleporterMessenger,
+	messageID ids.ID,
+	status *messageStatus,
+) error {
+	callOpts := &bind.CallOpts{Context: ctx}
+	info, err := messenger.SentMessageInfo(callOpts, messageID)
+	if err != nil {
+		return fmt.Errorf("failed to get sent message info: %w", err)
+	}
+	status.MessageHash = info.MessageHash
+	feeTokenAddress, feeAmount, err := messenger.GetFeeInfo(callOpts, messageID)
+	if err != nil {
+		return fmt.Errorf("failed to get fee info: %w", err)
+	}
+	status.FeeInfo = teleportermessenger.TeleporterFeeInfo{
+		FeeTokenAddress: feeTokenAddress,
+		Amount:          feeAmount,
+	}
+
+	filterOpts := &bind.FilterOpts{Start: statusFromBlock, Context: ctx}
+	messageIDs := [][32]byte{messageID}
+
+	sendIt, err := messenger.FilterSendCrossChainMessage(filterOpts, messageIDs, nil)
+	if err != nil {
+		return fmt.Errorf("failed to filter SendCrossChainMessage logs: %w", err)
+	}
+	defer sendIt.Close()
+	for sendIt.Next() {
+		status.addTransition(messageStateSent, sourceChain, sendIt.Event.Raw)
+		status.DestinationBlockchainID = sendIt.Event.DestinationBlockchainID
+		// The fee info is deleted once the receipt is returned, so fall back to the
+		// fee info the message was sent with.
+		if status.FeeInfo.Amount == nil || status.FeeInfo.Amount.Sign() == 0 {
+			status.FeeInfo = sendIt.Event.FeeInfo
+		}
+	}
+	if err := sendIt.Error(); err != nil {
+		return err
+	}
+
+	feeIt, err := messenger.FilterAddFeeAmount(filterOpts, messageIDs)
+	if err != nil {
+		return fmt.Errorf("failed to filter AddFeeAmount logs: %w", err)
+	}
+	defer feeIt.Close()
+	for feeIt.Next() {
+		status.addTransition(messageStateFeeAdded, sourceChain, feeIt.Event.Raw)
+	}
+	if err := feeIt.Error(); err != nil {
+		return err
+	}
+
+	receiptIt, err := messenger.FilterReceiptReceived(filterOpts, messageIDs, nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to filter ReceiptReceived logs: %w", err)
+	}
+	defer receiptIt.Close()
+	for receiptIt.Next() {
+		status.addTransition(messageStateReceiptReturned, sourceChain, receiptIt.Event.Raw)
+	}
+	return receiptIt.Error()
+}
+
+func queryDestinationStatus(
+	ctx context.Context,
+	messenger *teleportermessenger.TeleporterMessenger,
+	messageID ids.ID,
+	status *messageStatus,
+) error {
+	callOpts := &bind.CallOpts{Context: ctx}
+	received, err := messenger.MessageReceived(callOpts, messageID)
+	if err != nil {
+		return fmt.Errorf("failed to check if message was received: %w", err)
+	}
+	status.Received = received
+	failedMessageHash, err := messenger.ReceivedFailedMessageHashes(callOpts, messageID)
+	if err != nil {
+		return fmt.Errorf("failed to get failed message hash: %w", err)
+	}
+	status.FailedMessageHash = failedMessageHash
+	if received {
+		status.RelayerRewardAddress, err = messenger.GetRelayerRewardAddress(callOpts, messageID)
+		if err != nil {
+			return fmt.Errorf("failed to get relayer reward address: %w", err)
+		}
+	}
+
+	filterOpts := &bind.FilterOpts{Start: statusFromBlock, Context: ctx}
+	messageIDs := [][32]byte{messageID}
+
+	receiveIt, err := messenger.FilterReceiveCrossChainMessage(filterOpts, messageIDs, nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to filter ReceiveCrossChainMessage logs: %w", err)
+	}
+	defer receiveIt.Close()
+	for receiveIt.Next() {
+		status.addTransition(messageStateDelivered, destinationChain, receiveIt.Event.Raw)
+	}
+	if err := receiveIt.Error(); err != nil {
+		return err
+	}
+
+	failedIt, err := messenger.FilterMessageExecutionFailed(filterOpts, messageIDs, nil)
+	if err != nil {
+		return fmt.Errorf("failed to filter MessageExecutionFailed logs: %w", err)
+	}
+	defer failedIt.Close()
+	for failedIt.Next() {
+		status.addTransition(messageStateExecutionFailed, destinationChain, failedIt.Event.Raw)
+	}
+	if err := failedIt.Error(); err != nil {
+		return err
+	}
+
+	executedIt, err := messenger.FilterMessageExecuted(filterOpts, messageIDs, nil)
+	if err != nil {
+		return fmt.Errorf("failed to filter MessageExecuted logs: %w", err)
+	}
+	defer executedIt.Close()
+	for executedIt.Next() {
+		status.addTransition(messageStateExecuted, destinationChain, executedIt.Event.Raw)
+	}
+	return executedIt.Error()
+}
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+	statusCmd.Flags().StringVar(&statusSourceRPC, "source-rpc", "", "RPC endpoint of the source chain")
+	statusCmd.Flags().StringVar(
+		&statusDestinationRPC,
+		"destination-rpc",
+		"",
+		"RPC endpoint of the destination chain, resolved from the network profiles if not set",
+	)
+	statusCmd.Flags().StringVarP(&statusAddress, "teleporter-address", "t", "", "Teleporter contract address")
+	statusCmd.Flags().StringVar(
+		&statusDestinationAddress,
+		"destination-teleporter-address",
+		"",
+		"Teleporter contract address on the destination chain, if different from --teleporter-address",
+	)
+	statusCmd.Flags().Uint64Var(&statusFromBlock, "from-block", 0, "First block to scan for Teleporter logs")
+
+	for _, flag := range []string{"source-rpc", "teleporter-address"} {
+		err := statusCmd.MarkFlagRequired(flag)
+		cobra.CheckErr(err)
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatusCmd(t *testing.T) {
+	var tests = []struct {
+		name string
+		args []string
+		err  error
+		out  string
+	}{
+		{
+			name: "no args",
+			args: []string{"status"},
+			err:  fmt.Errorf("accepts 1 arg(s), received 0"),
+		},
+		{
+			name: "help",
+			args: []string{"status", "--help"},
+			err:  nil,
+			out:  "Given a Teleporter message ID, this command queries the TeleporterMessenger contract",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := executeTestCmd(t, rootCmd, tt.args...)
+			if tt.err != nil {
+				require.ErrorContains(t, err, tt.err.Error())
+			} else {
+				require.NoError(t, err)
+				require.Contains(t, out, tt.out)
+			}
+		})
+	}
+}
+
+func TestMessageStatusResolveState(t *testing.T) {
+	var tests = []struct {
+		name        string
+		status      messageStatus
+		transitions []messageState
+		expected    messageState
+		err         error
+	}{
+		{
+			name:     "not found",
+			status:   messageStatus{},
+			expected: "",
+			err:      errStatusMessageNotFound,
+		},
+		{
+			name:     "sent without logs",
+			status:   messageStatus{MessageHash: common.Hash{1}},
+			expected: messageStateSent,
+		},
+		{
+			name:        "fee added",
+			transitions: []messageState{messageStateSent, messageStateFeeAdded},
+			expected:    messageStateFeeAdded,
+		},
+		{
+			name:     "delivered",
+			status:   messageStatus{MessageHash: common.Hash{1}, Received: true},
+			expected: messageStateDelivered,
+		},
+		{
+			name:        "executed",
+			transitions: []messageState{messageStateSent, messageStateDelivered, messageStateExecuted},
+			expected:    messageStateExecuted,
+		},
+		{
+			name:        "receipt returned",
+			transitions: []messageState{messageStateSent, messageStateDelivered, messageStateReceiptReturned},
+			expected:    messageStateReceiptReturned,
+		},
+		{
+			name:   "pending failure takes precedence over receipt",
+			status: messageStatus{FailedMessageHash: common.Hash{1}},
+			transitions: []messageState{
+				messageStateSent,
+				messageStateDelivered,
+				messageStateExecutionFailed,
+				messageStateReceiptReturned,
+			},
+			expected: messageStateExecutionFailed,
+		},
+		{
+			name: "retried failure",
+			transitions: []messageState{
+				messageStateSent,
+				messageStateDelivered,
+				messageStateExecutionFailed,
+				messageStateExecuted,
+			},
+			expected: messageStateExecuted,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := tt.status
+			for _, state := range tt.transitions {
+				status.addTransition(state, sourceChain, types.Log{})
+			}
+			err := status.resolveState()
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tt.expected, status.State)
+		})
+	}
+}
@@ -6,121 +6,290 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
 
-	warpPayload "github.com/ava-labs/avalanchego/vms/platformvm/warp/payload"
 	teleportermessenger "github.com/ava-labs/icm-contracts/abi-bindings/go/teleporter/TeleporterMessenger"
 	"github.com/ava-labs/subnet-evm/core/types"
 	"github.com/ava-labs/subnet-evm/eth/tracers"
 	"github.com/ava-labs/subnet-evm/ethclient"
 	"github.com/ava-labs/subnet-evm/precompile/contracts/warp"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/spf13/cobra"
+	"go.uber.org/zap"
 )
 
 const (
 	ICMPrecompileAddressHex = "0x0200000000000000000000000000000000000005"
 )
 
+const defaultTransactionConcurrency = 4
+
 var (
-	debug             bool
-	rpcEndpoint       string
-	teleporterAddress common.Address
-	client            ethclient.Client
+	debug                  bool
+	rpcEndpoint            string
+	teleporterAddress      common.Address
+	client                 ethclient.Client
+	transactionConcurrency int
+	transactionFailOnError bool
+
+	errInvalidTransactionHash   = errors.New("invalid transaction hash")
+	errTransactionConcurrency   = errors.New("--concurrency must be positive")
+	errNoTransactionHashes      = errors.New("no transaction hashes given")
+	errTransactionDecodeFailure = errors.New("failed to decode transactions")
 )
 
 var transactionCmd = &cobra.Command{
-	Use:   "transaction --rpc RPC_URL --teleporter-address CONTRACT_ADDRESS TRANSACTION_HASH",
-	Short: "Parses relevant Teleporter logs from a transaction",
+	Use:   "transaction --rpc RPC_URL --teleporter-address CONTRACT_ADDRESS [TRANSACTION_HASH...]",
+	Short: "Parses relevant Teleporter logs from transactions",
 	Long: `Given a transaction this command looks through the transaction's receipt
 for TeleporterMessenger and ICM log events. When corresponding log events are found,
 the command parses to log event fields to a more human readable format. Optionally pass -d 
-or --debug for extra transaction output. This may require enabling debug enpoints on your RPC node`,
-	Args: cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		txHash := common.HexToHash(args[0])
-		if debug {
-			printTransaction(cmd, txHash)
-			traceTransaction(cmd, txHash)
+or --debug for extra transaction output, including the transaction's call tree with each call's
+method, arguments, gas used and revert reason decoded against the ABIs of every contract binding.
+This may require enabling debug enpoints on your RPC node
+
+Any number of transaction hashes can be passed, or read from stdin, separated by whitespace, if
+none are passed or the only one is "-". Receipts are fetched with up to --concurrency requests in
+flight, and the transactions are printed in the order given. A receipt that cannot be fetched or
+a log that cannot be decoded does not stop the others from being printed. Such failures are
+listed at the end, and only make the command exit with an error if --fail-on-error is passed.`,
+	Args: transactionArgs,
+	Run:  transactionRun,
+}
+
+// fetchedTransaction is a transaction's receipt, along with the transaction and its call trace
+// in debug mode, or the error that prevented fetching them.
+type fetchedTransaction struct {
+	TxHash   common.Hash
+	Receipt  *types.Receipt
+	Tx       *types.Transaction
+	Trace    *callFrame
+	TraceErr error
+	Err      error
+}
+
+// transactionError is a transaction whose receipt could not be fetched, or a log of its receipt
+// that could not be decoded, and why.
+type transactionError struct {
+	TxHash   common.Hash
+	LogIndex *uint           `json:",omitempty"`
+	Address  *common.Address `json:",omitempty"`
+	Topic    *common.Hash    `json:",omitempty"`
+	Reason   string
+}
+
+// transactionArgs checks that every argument is a transaction hash, or that the only argument
+// is "-", before the RPC endpoint is dialed.
+func transactionArgs(cmd *cobra.Command, args []string) error {
+	if len(args) == 1 && args[0] == "-" {
+		return nil
+	}
+	for _, arg := range args {
+		if _, err := parseTransactionHash(arg); err != nil {
+			return err
 		}
-		checkReceipt(cmd, txHash)
-		cmd.Println("Transaction command ran successfully")
-	},
+	}
+	return nil
 }
 
-func checkReceipt(cmd *cobra.Command, txHash common.Hash) {
-	receipt, err := client.TransactionReceipt(context.Background(), txHash)
-	cobra.CheckErr(err)
+func transactionRun(cmd *cobra.Command, args []string) {
+	if transactionConcurrency <= 0 {
+		cobra.CheckErr(errTransactionConcurrency)
+	}
+	if len(args) == 0 || (len(args) == 1 && args[0] == "-") {
+		b, err := io.ReadAll(cmd.InOrStdin())
+		cobra.CheckErr(err)
+		args = strings.Fields(string(b))
+	}
+	if len(args) == 0 {
+		cobra.CheckErr(errNoTransactionHashes)
+	}
+	txHashes := make([]common.Hash, len(args))
+	for i, arg := range args {
+		txHash, err := parseTransactionHash(arg)
+		cobra.CheckErr(err)
+		txHashes[i] = txHash
+	}
 
+	// Call traces are only fetched, and decoded, in debug mode.
+	var registry *abiRegistry
+	if debug {
+		var err error
+		registry, err = newBindingABIRegistry()
+		cobra.CheckErr(err)
+	}
+	fetched := fetchTransactions(context.Background(), txHashes, transactionConcurrency)
+	var txErrs []transactionError
+	for _, f := range fetched {
+		txErrs = append(txErrs, printFetchedTransaction(cmd, registry, f)...)
+	}
+
+	if len(txErrs) != 0 {
+		txErrsJson, err := json.MarshalIndent(txErrs, "", "  ")
+		cobra.CheckErr(err)
+		cmd.Println("Transaction Errors:\n" + string(txErrsJson) + "\n")
+	}
+	cmd.Printf("Printed %d transactions with %d errors\n", len(fetched), len(txErrs))
+	if transactionFailOnError && len(txErrs) != 0 {
+		cobra.CheckErr(fmt.Errorf("%w: %d errors", errTransactionDecodeFailure, len(txErrs)))
+	}
+	cmd.Println("Transaction command ran successfully")
+}
+
+func parseTransactionHash(s string) (common.Hash, error) {
+	b, err := hexutil.Decode(s)
+	if err != nil || len(b) != common.HashLength {
+		return common.Hash{}, fmt.Errorf("%w: %s", errInvalidTransactionHash, s)
+	}
+	return common.BytesToHash(b), nil
+}
+
+// fetchTransactions fetches the receipts of txHashes, along with the transactions and their call
+// traces in debug mode, with up to concurrency transactions in flight. The results are in the same
+// order as txHashes, and a failure to fetch one transaction does not affect the others.
+func fetchTransactions(ctx context.Context, txHashes []common.Hash, concurrency int) []fetchedTransaction {
+	var (
+		fetched = make([]fetchedTransaction, len(txHashes))
+		jobs    = make(chan int)
+		wg      sync.WaitGroup
+	)
+	for i := 0; i < min(concurrency, len(txHashes)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				fetched[i] = fetchTransaction(ctx, txHashes[i])
+			}
+		}()
+	}
+	for i := range txHashes {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return fetched
+}
+
+func fetchTransaction(ctx context.Context, txHash common.Hash) fetchedTransaction {
+	f := fetchedTransaction{TxHash: txHash}
+	if debug {
+		f.Tx, _, f.Err = client.TransactionByHash(ctx, txHash)
+		if f.Err != nil {
+			f.Err = fmt.Errorf("failed to get transaction: %w", f.Err)
+			return f
+		}
+		f.Trace, f.TraceErr = traceTransaction(ctx, txHash)
+	}
+	f.Receipt, f.Err = client.TransactionReceipt(ctx, txHash)
+	if f.Err != nil {
+		f.Err = fmt.Errorf("failed to get transaction receipt: %w", f.Err)
+		return f
+	}
+	logger.Debug("Fetched transaction receipt", zap.String("txHash", txHash.Hex()), zap.Int("logs", len(f.Receipt.Logs)))
+	return f
+}
+
+// printFetchedTransaction prints the TeleporterMessenger and ICM logs of a fetched transaction,
+// and returns the errors of the transaction and of each log that could not be decoded. In debug
+// mode, the call trace is decoded with registry.
+func printFetchedTransaction(cmd *cobra.Command, registry *abiRegistry, f fetchedTransaction) []transactionError {
+	cmd.Println("Transaction Hash: " + f.TxHash.Hex() + "\n")
+	if f.Err != nil {
+		return []transactionError{{TxHash: f.TxHash, Reason: f.Err.Error()}}
+	}
+	if debug {
+		printTransaction(cmd, f.Tx)
+		if f.TraceErr != nil {
+			cmd.PrintErrln("Error calling debug_traceTransaction: " + f.TraceErr.Error())
+		} else {
+			cmd.Println("Transaction Trace:\n" + registry.decodeCallTree(*f.Trace).String() + "\n")
+		}
+	}
+
+	var txErrs []transactionError
 	ICMPrecompileAddress := common.HexToAddress(ICMPrecompileAddressHex)
-	for _, log := range receipt.Logs {
+	for _, log := range f.Receipt.Logs {
+		var err error
 		switch log.Address {
 		case teleporterAddress:
-			printTeleporterLogs(cmd, log)
+			err = printTeleporterLogs(cmd, log)
 		case ICMPrecompileAddress:
-			printICMLogs(cmd, log)
+			err = printICMLogs(cmd, log)
+		}
+		if err != nil {
+			logIndex, address := log.Index, log.Address
+			txErr := transactionError{TxHash: f.TxHash, LogIndex: &logIndex, Address: &address, Reason: err.Error()}
+			if len(log.Topics) != 0 {
+				txErr.Topic = &log.Topics[0]
+			}
+			txErrs = append(txErrs, txErr)
 		}
 	}
+	return txErrs
 }
 
-func printTeleporterLogs(cmd *cobra.Command, log *types.Log) {
+// printTeleporterLogs prints a TeleporterMessenger log and its decoded event, or returns the
+// reason it could not be decoded.
+func printTeleporterLogs(cmd *cobra.Command, log *types.Log) error {
 	logJson, err := json.MarshalIndent(log, "", "  ")
 	cobra.CheckErr(err)
 
 	cmd.Println("Teleporter Log:\n" + string(logJson) + "\n")
 
-	event, err := teleporterABI.EventByID(log.Topics[0])
-	cobra.CheckErr(err)
+	event, out, err := teleportermessenger.ParseLog(*log)
+	if err != nil {
+		return err
+	}
 
-	out, err := teleportermessenger.FilterTeleporterEvents(log.Topics, log.Data, event.Name)
-	cobra.CheckErr(err)
+	cmd.Println(event.String() + " Log:")
+	cmd.Println(out.(fmt.Stringer).String() + "\n")
+	return nil
+}
 
-	cmd.Println(event.Name + " Log:")
-	cmd.Println(out.String() + "\n")
+// printReceiptTeleporterLogs prints every TeleporterMessenger log in the receipt.
+func printReceiptTeleporterLogs(cmd *cobra.Command, receipt *types.Receipt, teleporterAddress common.Address) {
+	for _, log := range receipt.Logs {
+		if log.Address == teleporterAddress {
+			cobra.CheckErr(printTeleporterLogs(cmd, log))
+		}
+	}
 }
 
-func printICMLogs(cmd *cobra.Command, log *types.Log) {
+// printICMLogs prints a Warp precompile log and its decoded Warp message, or returns the reason
+// it could not be decoded.
+func printICMLogs(cmd *cobra.Command, log *types.Log) error {
 	logJson, err := json.MarshalIndent(log, "", "  ")
 	cobra.CheckErr(err)
 
 	cmd.Println("ICM Log:\n" + string(logJson) + "\n")
 
 	unsignedMsg, err := warp.UnpackSendWarpEventDataToMessage(log.Data)
-	cobra.CheckErr(err)
+	if err != nil {
+		return err
+	}
 	cmd.Println("ICM Message ID: " + unsignedMsg.ID().Hex())
 
-	icmPayload, err := warpPayload.ParseAddressedCall(unsignedMsg.Payload)
-	cobra.CheckErr(err)
-
-	icmPayloadJson, err := json.MarshalIndent(icmPayload, "", "  ")
-	cobra.CheckErr(err)
 	cmd.Println("ICM Payload:")
-	cmd.Println(string(icmPayloadJson))
-
-	teleporterMessage := teleportermessenger.TeleporterMessage{}
-	err = teleporterMessage.Unpack(icmPayload.Payload)
-	cobra.CheckErr(err)
-
-	cmd.Println("Teleporter Message:")
-	cmd.Println(teleporterMessage.String())
+	cmd.Println(decodeUnsignedWarpMessage(unsignedMsg).String())
+	return nil
 }
 
-func traceTransaction(cmd *cobra.Command, txHash common.Hash) {
-	var result interface{}
+func traceTransaction(ctx context.Context, txHash common.Hash) (*callFrame, error) {
+	var result callFrame
 	ct := "callTracer"
-	err := client.Client().Call(&result, "debug_traceTransaction", txHash.String(), tracers.TraceConfig{Tracer: &ct})
-	if err != nil {
-		cmd.PrintErr("Error calling debug_traceTransaction: " + err.Error())
-		return
+	config := tracers.TraceConfig{Tracer: &ct}
+	if err := client.Client().CallContext(ctx, &result, "debug_traceTransaction", txHash.String(), config); err != nil {
+		return nil, err
 	}
-	json, err := json.MarshalIndent(result, "", "  ")
-	cobra.CheckErr(err)
-
-	cmd.Println("Transaction Trace:\n" + string(json) + "\n")
+	return &result, nil
 }
 
-func printTransaction(cmd *cobra.Command, txHash common.Hash) {
-	tx, _, err := client.TransactionByHash(context.Background(), txHash)
-	cobra.CheckErr(err)
+func printTransaction(cmd *cobra.Command, tx *types.Transaction) {
 	json, err := json.MarshalIndent(tx, "", "  ")
 	cobra.CheckErr(err)
 
@@ -136,6 +305,18 @@ func init() {
 	err = transactionCmd.MarkPersistentFlagRequired("teleporter-address")
 	cobra.CheckErr(err)
 	transactionCmd.Flags().BoolVarP(&debug, "debug", "d", false, "default: false.")
+	transactionCmd.Flags().IntVar(
+		&transactionConcurrency,
+		"concurrency",
+		defaultTransactionConcurrency,
+		"Number of transactions fetched concurrently",
+	)
+	transactionCmd.Flags().BoolVar(
+		&transactionFailOnError,
+		"fail-on-error",
+		false,
+		"Exit with an error if any receipt cannot be fetched or any log cannot be decoded",
+	)
 	transactionCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
 		return transactionPreRunE(cmd, args, address)
 	}
@@ -1,9 +1,14 @@
 package main
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"testing"
 
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/require"
 )
 
@@ -15,9 +20,9 @@ func TestTransactionCmd(t *testing.T) {
 		out  string
 	}{
 		{
-			name: "no args",
-			args: []string{"transaction"},
-			err:  fmt.Errorf("accepts 1 arg(s), received 0"),
+			name: "invalid hash",
+			args: []string{"transaction", "0x1234"},
+			err:  fmt.Errorf("invalid transaction hash: 0x1234"),
 		},
 		{
 			name: "help",
@@ -39,3 +44,54 @@ func TestTransactionCmd(t *testing.T) {
 		})
 	}
 }
+
+func TestPrintFetchedTransaction(t *testing.T) {
+	previousTeleporterAddress := teleporterAddress
+	t.Cleanup(func() { teleporterAddress = previousTeleporterAddress })
+	teleporterAddress = common.HexToAddress("0xfeabb3b3f4eeae6b5769507a5e6b808704e5c626")
+	icmPrecompileAddress := common.HexToAddress(ICMPrecompileAddressHex)
+	txHash := common.HexToHash("0x01")
+	unknownTopic := common.HexToHash("0x02")
+
+	var tests = []struct {
+		name      string
+		fetched   fetchedTransaction
+		logErrors []uint
+		txError   bool
+	}{
+		{
+			name:    "receipt not fetched",
+			fetched: fetchedTransaction{TxHash: txHash, Err: errors.New("not found")},
+			txError: true,
+		},
+		{
+			name: "undecodable logs",
+			fetched: fetchedTransaction{TxHash: txHash, Receipt: &types.Receipt{Logs: []*types.Log{
+				{Address: teleporterAddress, Topics: []common.Hash{unknownTopic}, Index: 0},
+				{Address: common.HexToAddress("0x03"), Topics: []common.Hash{unknownTopic}, Index: 1},
+				{Address: icmPrecompileAddress, Data: []byte{1, 2, 3}, Index: 2},
+				{Address: teleporterAddress, Index: 3},
+			}}},
+			logErrors: []uint{0, 2, 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &cobra.Command{}
+			cmd.SetOut(new(bytes.Buffer))
+			txErrs := printFetchedTransaction(cmd, nil, tt.fetched)
+			if tt.txError {
+				require.Len(t, txErrs, 1)
+				require.Nil(t, txErrs[0].LogIndex)
+				return
+			}
+			require.Len(t, txErrs, len(tt.logErrors))
+			for i, txErr := range txErrs {
+				require.Equal(t, txHash, txErr.TxHash)
+				require.Equal(t, tt.logErrors[i], *txErr.LogIndex)
+				require.NotEmpty(t, txErr.Reason)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// decodedTree is a readable description of a decoded structure, such as a Warp message or a
+// call trace. Each node has a name, an ordered list of fields, and the nested structures it carries.
+type decodedTree struct {
+	Name     string
+	Fields   []decodedField
+	Children []*decodedTree
+}
+
+type decodedField struct {
+	Key   string
+	Value string
+}
+
+func newDecodedTree(name string) *decodedTree {
+	return &decodedTree{Name: name}
+}
+
+func (t *decodedTree) addField(key string, value any) {
+	t.Fields = append(t.Fields, decodedField{Key: key, Value: fmt.Sprint(value)})
+}
+
+func (t *decodedTree) addChild(child *decodedTree) {
+	t.Children = append(t.Children, child)
+}
+
+func (t *decodedTree) String() string {
+	var sb strings.Builder
+	t.write(&sb, 0)
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+func (t *decodedTree) write(sb *strings.Builder, depth int) {
+	indent := strings.Repeat("  ", depth)
+	sb.WriteString(indent + t.Name + "\n")
+	for _, f := range t.Fields {
+		sb.WriteString(indent + "  " + f.Key + ": " + f.Value + "\n")
+	}
+	for _, child := range t.Children {
+		child.write(sb, depth+1)
+	}
+}
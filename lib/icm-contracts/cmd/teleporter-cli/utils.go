@@ -0,0 +1,40 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// parseBlockchainID parses a blockchain ID given either in its CB58 form, as printed
+// by the readable Teleporter types, or as a 0x prefixed hex string.
+func parseBlockchainID(s string) (ids.ID, error) {
+	if strings.HasPrefix(s, "0x") {
+		return parseHexID(s)
+	}
+	return ids.FromString(s)
+}
+
+// parseHexID parses a 32 byte 0x prefixed hex string, such as a Teleporter message ID.
+func parseHexID(s string) (ids.ID, error) {
+	b, err := hexutil.Decode(s)
+	if err != nil {
+		return ids.ID{}, fmt.Errorf("invalid hex %s: %w", s, err)
+	}
+	return ids.ToID(b)
+}
+
+// parseAddress parses a hex encoded address, rejecting malformed input rather than
+// silently truncating it as common.HexToAddress does.
+func parseAddress(s string) (common.Address, error) {
+	if !common.IsHexAddress(s) {
+		return common.Address{}, fmt.Errorf("invalid address %s", s)
+	}
+	return common.HexToAddress(s), nil
+}
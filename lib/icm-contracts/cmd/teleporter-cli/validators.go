@@ -0,0 +1,496 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ava-labs/avalanchego/ids"
+	avalancheWarp "github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	poavalidatormanager "github.com/ava-labs/icm-contracts/abi-bindings/go/validator-manager/PoAValidatorManager"
+	iposvalidatormanager "github.com/ava-labs/icm-contracts/abi-bindings/go/validator-manager/interfaces/IPoSValidatorManager"
+	"github.com/ava-labs/subnet-evm/accounts/abi"
+	"github.com/ava-labs/subnet-evm/accounts/abi/bind"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/ethclient"
+	"github.com/ava-labs/subnet-evm/interfaces"
+	"github.com/ava-labs/subnet-evm/precompile/contracts/warp"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+// validatorStatuses are the names of the ValidatorStatus values defined in IValidatorManager.sol.
+var validatorStatuses = []string{"Unknown", "PendingAdded", "Active", "PendingRemoved", "Completed", "Invalidated"}
+
+const (
+	validatorStatusPendingAdded   uint8 = 1
+	validatorStatusActive         uint8 = 2
+	validatorStatusPendingRemoved uint8 = 3
+)
+
+var (
+	validatorsRPC       string
+	validatorsAddress   string
+	validatorsFromBlock uint64
+	validatorsSigner    signerFlags
+
+	validatorManagerABI *abi.ABI
+
+	errValidatorsNotPending    = errors.New("validation is not waiting on a P-Chain acknowledgement")
+	errValidatorsWarpNotFound  = errors.New("no Warp message found")
+	errValidatorsNotInitialSet = errors.New("transaction is not an initializeValidatorSet call")
+)
+
+var validatorsCmd = &cobra.Command{
+	Use:   "validators",
+	Short: "Inspects a validator manager and re-sends its P-Chain messages",
+	Long: `Commands for the validator manager contracts of an L1: PoAValidatorManager,
+ERC20TokenStakingManager and NativeTokenStakingManager. Every validator manager emits the same
+events and exposes the same validator functions, so the commands work with any of them.`,
+	Args: cobra.NoArgs,
+}
+
+var validatorsListCmd = &cobra.Command{
+	Use:   "list --rpc RPC_URL --validator-manager-address CONTRACT_ADDRESS [--from-block N]",
+	Short: "Lists the validations of a validator manager",
+	Long: `Lists every validation of the validator manager on the chain served by --rpc, reconstructed
+from its ValidationPeriodCreated, ValidationPeriodRegistered, ValidationPeriodEnded,
+ValidatorRemovalInitialized, ValidatorWeightUpdate, DelegatorAdded and InitialValidatorCreated
+events from --from-block onwards, along with the validator's current status and weight.
+
+For each validation waiting on a P-Chain acknowledgement, the pending RegisterL1Validator or
+L1ValidatorWeight Warp message is decoded from the transaction that sent it. The initial
+validator set is decoded from the initializeValidatorSet transaction that created it.`,
+	Args: cobra.NoArgs,
+	Run:  validatorsListRun,
+}
+
+var validatorsResendCmd = &cobra.Command{
+	Use:   "resend --rpc RPC_URL --validator-manager-address CONTRACT_ADDRESS VALIDATION_ID",
+	Short: "Re-emits the pending Warp message of a validation",
+	Long: `Re-emits the Warp message a validation is waiting on, so that its signatures can be
+collected again. A validation pending registration re-sends its RegisterL1Validator message
+with resendRegisterValidatorMessage, and a validation pending removal re-sends its
+L1ValidatorWeight message with resendEndValidatorMessage. The re-emitted Warp message is
+printed.`,
+	Args: cobra.ExactArgs(1),
+	Run:  validatorsResendRun,
+}
+
+// validation is a validation period of a validator manager, reconstructed from its events.
+type validation struct {
+	ValidationID       ids.ID
+	NodeID             string
+	Status             string
+	Initial            bool
+	StartingWeight     uint64
+	Weight             uint64
+	RegistrationExpiry uint64   `json:",omitempty"`
+	RegisteredAt       *big.Int `json:",omitempty"`
+	EndTime            *big.Int `json:",omitempty"`
+	WeightUpdates      []validatorWeightUpdate
+	Delegations        []validatorDelegation
+	PendingMessageID   *common.Hash `json:",omitempty"`
+
+	// registration and removal are the Warp messages sent to register and to end the validation.
+	registration *sentWarpMessage
+	removal      *sentWarpMessage
+	// createdTx is the transaction that created the validation.
+	createdTx common.Hash
+}
+
+// validatorWeightUpdate is a weight change of a validator, sent to the P-Chain as an L1ValidatorWeight message.
+type validatorWeightUpdate struct {
+	Nonce              uint64
+	Weight             uint64
+	SetWeightMessageID common.Hash
+	TxHash             common.Hash
+}
+
+// validatorDelegation is a delegation added to a validator of a staking manager.
+type validatorDelegation struct {
+	DelegationID       common.Hash
+	DelegatorAddress   common.Address
+	Nonce              uint64
+	DelegatorWeight    uint64
+	ValidatorWeight    uint64
+	SetWeightMessageID common.Hash
+	TxHash             common.Hash
+}
+
+// sentWarpMessage identifies a Warp message by its ID and the transaction that sent it.
+type sentWarpMessage struct {
+	ID     common.Hash
+	TxHash common.Hash
+}
+
+// validationHistory reconstructs the validations of a validator manager from its events.
+type validationHistory struct {
+	validations map[ids.ID]*validation
+	// order holds the validation IDs in the order the validations were created.
+	order []ids.ID
+}
+
+func newValidationHistory() *validationHistory {
+	return &validationHistory{validations: make(map[ids.ID]*validation)}
+}
+
+func (h *validationHistory) get(validationID ids.ID) *validation {
+	v, ok := h.validations[validationID]
+	if !ok {
+		v = &validation{
+			ValidationID:  validationID,
+			Status:        validatorStatuses[0],
+			WeightUpdates: []validatorWeightUpdate{},
+			Delegations:   []validatorDelegation{},
+		}
+		h.validations[validationID] = v
+		h.order = append(h.order, validationID)
+	}
+	return v
+}
+
+// record updates the validations with a validator manager event decoded by decodeValidatorManagerLog.
+func (h *validationHistory) record(event any) {
+	switch e := event.(type) {
+	case *iposvalidatormanager.IPoSValidatorManagerInitialValidatorCreated:
+		v := h.get(e.ValidationID)
+		v.Initial = true
+		v.StartingWeight = e.Weight
+		v.Weight = e.Weight
+		v.Status = validatorStatuses[validatorStatusActive]
+		v.createdTx = e.Raw.TxHash
+	case *iposvalidatormanager.IPoSValidatorManagerValidationPeriodCreated:
+		v := h.get(e.ValidationID)
+		v.StartingWeight = e.Weight
+		v.Weight = e.Weight
+		v.RegistrationExpiry = e.RegistrationExpiry
+		v.Status = validatorStatuses[validatorStatusPendingAdded]
+		v.registration = &sentWarpMessage{ID: e.RegisterValidationMessageID, TxHash: e.Raw.TxHash}
+		v.createdTx = e.Raw.TxHash
+	case *iposvalidatormanager.IPoSValidatorManagerValidationPeriodRegistered:
+		v := h.get(e.ValidationID)
+		v.RegisteredAt = e.Timestamp
+		v.Status = validatorStatuses[validatorStatusActive]
+	case *iposvalidatormanager.IPoSValidatorManagerValidatorWeightUpdate:
+		v := h.get(e.ValidationID)
+		v.Weight = e.Weight
+		v.WeightUpdates = append(v.WeightUpdates, validatorWeightUpdate{
+			Nonce:              e.Nonce,
+			Weight:             e.Weight,
+			SetWeightMessageID: e.SetWeightMessageID,
+			TxHash:             e.Raw.TxHash,
+		})
+	case *iposvalidatormanager.IPoSValidatorManagerDelegatorAdded:
+		v := h.get(e.ValidationID)
+		v.Weight = e.ValidatorWeight
+		v.Delegations = append(v.Delegations, validatorDelegation{
+			DelegationID:       e.DelegationID,
+			DelegatorAddress:   e.DelegatorAddress,
+			Nonce:              e.Nonce,
+			DelegatorWeight:    e.DelegatorWeight,
+			ValidatorWeight:    e.ValidatorWeight,
+			SetWeightMessageID: e.SetWeightMessageID,
+			TxHash:             e.Raw.TxHash,
+		})
+	case *iposvalidatormanager.IPoSValidatorManagerValidatorRemovalInitialized:
+		v := h.get(e.ValidationID)
+		v.EndTime = e.EndTime
+		v.Status = validatorStatuses[validatorStatusPendingRemoved]
+		v.removal = &sentWarpMessage{ID: e.SetWeightMessageID, TxHash: e.Raw.TxHash}
+	case *iposvalidatormanager.IPoSValidatorManagerValidationPeriodEnded:
+		v := h.get(e.ValidationID)
+		v.Status = validatorStatusName(e.Status)
+	}
+}
+
+// list returns the validations in the order they were created.
+func (h *validationHistory) list() []*validation {
+	validations := make([]*validation, 0, len(h.order))
+	for _, validationID := range h.order {
+		validations = append(validations, h.validations[validationID])
+	}
+	return validations
+}
+
+// pendingMessage returns the Warp message the validation is waiting on the P-Chain to acknowledge,
+// if its status is pending.
+func (v *validation) pendingMessage() *sentWarpMessage {
+	switch v.Status {
+	case validatorStatuses[validatorStatusPendingAdded]:
+		return v.registration
+	case validatorStatuses[validatorStatusPendingRemoved]:
+		return v.removal
+	default:
+		return nil
+	}
+}
+
+func validatorStatusName(status uint8) string {
+	if int(status) >= len(validatorStatuses) {
+		return fmt.Sprintf("Status(%d)", status)
+	}
+	return validatorStatuses[status]
+}
+
+func validatorsListRun(cmd *cobra.Command, args []string) {
+	address, err := parseAddress(validatorsAddress)
+	cobra.CheckErr(err)
+
+	ctx := context.Background()
+	c, err := ethclient.DialContext(ctx, validatorsRPC)
+	cobra.CheckErr(err)
+	defer c.Close()
+	filterer, err := iposvalidatormanager.NewIPoSValidatorManagerFilterer(address, c)
+	cobra.CheckErr(err)
+	// getValidator is defined by ValidatorManager rather than its interfaces, so the
+	// PoAValidatorManager binding is used to call it on any validator manager.
+	caller, err := poavalidatormanager.NewPoAValidatorManagerCaller(address, c)
+	cobra.CheckErr(err)
+
+	logs, err := c.FilterLogs(ctx, interfaces.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(validatorsFromBlock),
+		Addresses: []common.Address{address},
+	})
+	cobra.CheckErr(err)
+	history := newValidationHistory()
+	for _, log := range logs {
+		event, err := decodeValidatorManagerLog(filterer, log)
+		if err != nil {
+			logger.Info("Skipping undecodable log", zap.String("txHash", log.TxHash.Hex()), zap.Error(err))
+			continue
+		}
+		history.record(event)
+	}
+
+	validations := history.list()
+	var initialSetTx common.Hash
+	for _, v := range validations {
+		validator, err := caller.GetValidator(&bind.CallOpts{Context: ctx}, v.ValidationID)
+		cobra.CheckErr(err)
+		v.NodeID = nodeIDString(validator.NodeID)
+		v.Status = validatorStatusName(validator.Status)
+		v.Weight = validator.Weight
+		if pending := v.pendingMessage(); pending != nil {
+			v.PendingMessageID = &pending.ID
+		}
+		if v.Initial {
+			initialSetTx = v.createdTx
+		}
+	}
+
+	validationsJson, err := json.MarshalIndent(validations, "", "  ")
+	cobra.CheckErr(err)
+	cmd.Println("Validations:\n" + string(validationsJson) + "\n")
+
+	if initialSetTx != (common.Hash{}) {
+		tx, _, err := c.TransactionByHash(ctx, initialSetTx)
+		cobra.CheckErr(err)
+		initialSet, err := decodeInitialValidatorSet(tx.Data())
+		if err != nil {
+			logger.Info("Could not decode initial validator set", zap.Error(err))
+		} else {
+			initialSetJson, err := json.MarshalIndent(initialSet, "", "  ")
+			cobra.CheckErr(err)
+			cmd.Println("Initial Validator Set:\n" + string(initialSetJson) + "\n")
+		}
+	}
+
+	for _, v := range validations {
+		pending := v.pendingMessage()
+		if pending == nil {
+			continue
+		}
+		receipt, err := c.TransactionReceipt(ctx, pending.TxHash)
+		cobra.CheckErr(err)
+		unsignedMsg, err := findWarpMessage(receipt, pending.ID)
+		cobra.CheckErr(err)
+		cmd.Printf("Pending Warp Message for validation %s:\n", v.ValidationID)
+		cmd.Println("Unsigned Warp Message: " + hexutil.Encode(unsignedMsg.Bytes()))
+		cmd.Println(decodeUnsignedWarpMessage(unsignedMsg).String())
+	}
+	cmd.Println("Validators list command ran successfully")
+}
+
+func validatorsResendRun(cmd *cobra.Command, args []string) {
+	validationID, err := parseBlockchainID(args[0])
+	cobra.CheckErr(err)
+	address, err := parseAddress(validatorsAddress)
+	cobra.CheckErr(err)
+	key, err := validatorsSigner.load()
+	cobra.CheckErr(err)
+
+	ctx := context.Background()
+	c, err := ethclient.DialContext(ctx, validatorsRPC)
+	cobra.CheckErr(err)
+	defer c.Close()
+	caller, err := poavalidatormanager.NewPoAValidatorManagerCaller(address, c)
+	cobra.CheckErr(err)
+	manager, err := iposvalidatormanager.NewIPoSValidatorManagerTransactor(address, c)
+	cobra.CheckErr(err)
+
+	validator, err := caller.GetValidator(&bind.CallOpts{Context: ctx}, validationID)
+	cobra.CheckErr(err)
+	opts, err := newTransactor(ctx, c, key)
+	cobra.CheckErr(err)
+	var tx *types.Transaction
+	switch validator.Status {
+	case validatorStatusPendingAdded:
+		tx, err = manager.ResendRegisterValidatorMessage(opts, validationID)
+	case validatorStatusPendingRemoved:
+		tx, err = manager.ResendEndValidatorMessage(opts, validationID)
+	default:
+		err = fmt.Errorf(
+			"%w: validation %s is %s",
+			errValidatorsNotPending,
+			validationID,
+			validatorStatusName(validator.Status),
+		)
+	}
+	cobra.CheckErr(err)
+	logger.Info(
+		"Re-sending validator message",
+		zap.String("validationID", validationID.String()),
+		zap.String("status", validatorStatusName(validator.Status)),
+	)
+	receipt, err := waitForSuccess(ctx, c, tx)
+	cobra.CheckErr(err)
+
+	icmPrecompileAddress := common.HexToAddress(ICMPrecompileAddressHex)
+	for _, log := range receipt.Logs {
+		if log.Address == icmPrecompileAddress {
+			cobra.CheckErr(printICMLogs(cmd, log))
+		}
+	}
+	cmd.Println("Validators resend command ran successfully")
+}
+
+// decodeValidatorManagerLog decodes a log emitted by a validator manager. The IPoSValidatorManager
+// binding is used for every manager, since its events are a superset of the PoA manager's.
+func decodeValidatorManagerLog(
+	filterer *iposvalidatormanager.IPoSValidatorManagerFilterer,
+	log types.Log,
+) (any, error) {
+	if len(log.Topics) == 0 {
+		return nil, errors.New("log has no topics")
+	}
+	event, err := validatorManagerABI.EventByID(log.Topics[0])
+	if err != nil {
+		return nil, err
+	}
+	switch event.Name {
+	case "InitialValidatorCreated":
+		return filterer.ParseInitialValidatorCreated(log)
+	case "ValidationPeriodCreated":
+		return filterer.ParseValidationPeriodCreated(log)
+	case "ValidationPeriodRegistered":
+		return filterer.ParseValidationPeriodRegistered(log)
+	case "ValidatorWeightUpdate":
+		return filterer.ParseValidatorWeightUpdate(log)
+	case "DelegatorAdded":
+		return filterer.ParseDelegatorAdded(log)
+	case "ValidatorRemovalInitialized":
+		return filterer.ParseValidatorRemovalInitialized(log)
+	case "ValidationPeriodEnded":
+		return filterer.ParseValidationPeriodEnded(log)
+	default:
+		// Other events, such as delegation and uptime events, do not change the validations.
+		return nil, nil
+	}
+}
+
+// findWarpMessage returns the unsigned Warp message with the given ID sent in the receipt.
+func findWarpMessage(receipt *types.Receipt, messageID common.Hash) (*avalancheWarp.UnsignedMessage, error) {
+	icmPrecompileAddress := common.HexToAddress(ICMPrecompileAddressHex)
+	for _, log := range receipt.Logs {
+		// SendWarpMessage logs index the message ID as the third topic.
+		if log.Address != icmPrecompileAddress || len(log.Topics) < 3 || log.Topics[2] != messageID {
+			continue
+		}
+		return warp.UnpackSendWarpEventDataToMessage(log.Data)
+	}
+	return nil, fmt.Errorf("%w: %s in transaction %s", errValidatorsWarpNotFound, messageID.Hex(), receipt.TxHash.Hex())
+}
+
+// initialValidatorSet is the conversion data passed to initializeValidatorSet, in a readable format.
+type initialValidatorSet struct {
+	L1ID                         ids.ID
+	ValidatorManagerBlockchainID ids.ID
+	ValidatorManagerAddress      common.Address
+	InitialValidators            []initialValidator
+}
+
+type initialValidator struct {
+	NodeID       string
+	BLSPublicKey hexutil.Bytes
+	Weight       uint64
+}
+
+// decodeInitialValidatorSet decodes the conversion data from the input of an initializeValidatorSet call,
+// which carries the node IDs and BLS public keys that InitialValidatorCreated events only hash.
+func decodeInitialValidatorSet(input []byte) (*initialValidatorSet, error) {
+	method := validatorManagerABI.Methods["initializeValidatorSet"]
+	if len(input) < 4 || !bytes.Equal(input[:4], method.ID) {
+		return nil, errValidatorsNotInitialSet
+	}
+	args, err := method.Inputs.Unpack(input[4:])
+	if err != nil {
+		return nil, err
+	}
+	conversionData := *abi.ConvertType(
+		args[0],
+		new(iposvalidatormanager.ConversionData),
+	).(*iposvalidatormanager.ConversionData)
+
+	initialSet := &initialValidatorSet{
+		L1ID:                         conversionData.L1ID,
+		ValidatorManagerBlockchainID: conversionData.ValidatorManagerBlockchainID,
+		ValidatorManagerAddress:      conversionData.ValidatorManagerAddress,
+		InitialValidators:            make([]initialValidator, 0, len(conversionData.InitialValidators)),
+	}
+	for _, v := range conversionData.InitialValidators {
+		initialSet.InitialValidators = append(initialSet.InitialValidators, initialValidator{
+			NodeID:       nodeIDString(v.NodeID),
+			BLSPublicKey: v.BlsPublicKey,
+			Weight:       v.Weight,
+		})
+	}
+	return initialSet, nil
+}
+
+func init() {
+	var err error
+	validatorManagerABI, err = iposvalidatormanager.IPoSValidatorManagerMetaData.GetAbi()
+	cobra.CheckErr(err)
+
+	rootCmd.AddCommand(validatorsCmd)
+	validatorsCmd.AddCommand(validatorsListCmd)
+	validatorsCmd.AddCommand(validatorsResendCmd)
+	validatorsCmd.PersistentFlags().StringVar(&validatorsRPC, "rpc", "", "RPC endpoint to connect to the node")
+	validatorsCmd.PersistentFlags().StringVar(
+		&validatorsAddress,
+		"validator-manager-address",
+		"",
+		"Validator manager contract address",
+	)
+	validatorsListCmd.Flags().Uint64Var(
+		&validatorsFromBlock,
+		"from-block",
+		0,
+		"First block to scan for validator manager logs",
+	)
+	addSignerFlags(validatorsResendCmd, &validatorsSigner)
+
+	for _, flag := range []string{"rpc", "validator-manager-address"} {
+		err := validatorsCmd.MarkPersistentFlagRequired(flag)
+		cobra.CheckErr(err)
+	}
+}
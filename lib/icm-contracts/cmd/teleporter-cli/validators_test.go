@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	iposvalidatormanager "github.com/ava-labs/icm-contracts/abi-bindings/go/validator-manager/interfaces/IPoSValidatorManager"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidatorsListCmd(t *testing.T) {
+	var tests = []struct {
+		name string
+		args []string
+		err  error
+		out  string
+	}{
+		{
+			name: "no args",
+			args: []string{"validators", "list"},
+			err:  fmt.Errorf(`required flag(s) "rpc", "validator-manager-address" not set`),
+		},
+		{
+			name: "help",
+			args: []string{"validators", "list", "--help"},
+			err:  nil,
+			out:  "Lists every validation of the validator manager",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := executeTestCmd(t, rootCmd, tt.args...)
+			if tt.err != nil {
+				require.ErrorContains(t, err, tt.err.Error())
+			} else {
+				require.NoError(t, err)
+				require.Contains(t, out, tt.out)
+			}
+		})
+	}
+}
+
+func TestValidationHistory(t *testing.T) {
+	initialID, pendingID, removedID := ids.ID{1}, ids.ID{2}, ids.ID{3}
+	registerTx, removeTx := common.Hash{4}, common.Hash{5}
+	registerMessageID, removeMessageID := common.Hash{6}, common.Hash{7}
+
+	history := newValidationHistory()
+	for _, event := range []any{
+		&iposvalidatormanager.IPoSValidatorManagerInitialValidatorCreated{ValidationID: initialID, Weight: 100},
+		&iposvalidatormanager.IPoSValidatorManagerValidationPeriodCreated{
+			ValidationID:                pendingID,
+			RegisterValidationMessageID: registerMessageID,
+			Weight:                      20,
+			RegistrationExpiry:          1000,
+			Raw:                         types.Log{TxHash: registerTx},
+		},
+		&iposvalidatormanager.IPoSValidatorManagerValidationPeriodCreated{ValidationID: removedID, Weight: 30},
+		&iposvalidatormanager.IPoSValidatorManagerValidationPeriodRegistered{
+			ValidationID: removedID,
+			Weight:       30,
+			Timestamp:    big.NewInt(2000),
+		},
+		&iposvalidatormanager.IPoSValidatorManagerDelegatorAdded{
+			ValidationID:    removedID,
+			ValidatorWeight: 40,
+			DelegatorWeight: 10,
+		},
+		&iposvalidatormanager.IPoSValidatorManagerValidatorWeightUpdate{ValidationID: removedID, Nonce: 1, Weight: 40},
+		&iposvalidatormanager.IPoSValidatorManagerValidatorRemovalInitialized{
+			ValidationID:       removedID,
+			SetWeightMessageID: removeMessageID,
+			EndTime:            big.NewInt(3000),
+			Raw:                types.Log{TxHash: removeTx},
+		},
+	} {
+		history.record(event)
+	}
+
+	validations := history.list()
+	require.Len(t, validations, 3)
+	require.Equal(t, []ids.ID{initialID, pendingID, removedID}, []ids.ID{
+		validations[0].ValidationID,
+		validations[1].ValidationID,
+		validations[2].ValidationID,
+	})
+
+	initial := validations[0]
+	require.True(t, initial.Initial)
+	require.Equal(t, "Active", initial.Status)
+	require.Nil(t, initial.pendingMessage())
+
+	pending := validations[1]
+	require.Equal(t, "PendingAdded", pending.Status)
+	require.Equal(t, &sentWarpMessage{ID: registerMessageID, TxHash: registerTx}, pending.pendingMessage())
+
+	removed := validations[2]
+	require.Equal(t, "PendingRemoved", removed.Status)
+	require.Equal(t, uint64(30), removed.StartingWeight)
+	require.Equal(t, uint64(40), removed.Weight)
+	require.Len(t, removed.Delegations, 1)
+	require.Len(t, removed.WeightUpdates, 1)
+	require.Equal(t, &sentWarpMessage{ID: removeMessageID, TxHash: removeTx}, removed.pendingMessage())
+
+	history.record(&iposvalidatormanager.IPoSValidatorManagerValidationPeriodEnded{ValidationID: removedID, Status: 4})
+	require.Equal(t, "Completed", removed.Status)
+	require.Nil(t, removed.pendingMessage())
+}
+
+func TestDecodeInitialValidatorSet(t *testing.T) {
+	conversionData := iposvalidatormanager.ConversionData{
+		L1ID:                         ids.ID{1},
+		ValidatorManagerBlockchainID: ids.ID{2},
+		ValidatorManagerAddress:      common.HexToAddress("0x0123456789abcdef0123456789abcdef01234567"),
+		InitialValidators: []iposvalidatormanager.InitialValidator{
+			{
+				NodeID:       ids.GenerateTestNodeID().Bytes(),
+				BlsPublicKey: make([]byte, 48),
+				Weight:       100,
+			},
+		},
+	}
+	input, err := validatorManagerABI.Pack("initializeValidatorSet", conversionData, uint32(0))
+	require.NoError(t, err)
+
+	initialSet, err := decodeInitialValidatorSet(input)
+	require.NoError(t, err)
+	require.Equal(t, ids.ID{1}, initialSet.L1ID)
+	require.Equal(t, ids.ID{2}, initialSet.ValidatorManagerBlockchainID)
+	require.Equal(t, conversionData.ValidatorManagerAddress, initialSet.ValidatorManagerAddress)
+	require.Len(t, initialSet.InitialValidators, 1)
+	require.Equal(t, nodeIDString(conversionData.InitialValidators[0].NodeID), initialSet.InitialValidators[0].NodeID)
+	require.Equal(t, uint64(100), initialSet.InitialValidators[0].Weight)
+
+	_, err = decodeInitialValidatorSet([]byte{1, 2, 3, 4})
+	require.ErrorIs(t, err, errValidatorsNotInitialSet)
+}
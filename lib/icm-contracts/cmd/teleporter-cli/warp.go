@@ -0,0 +1,265 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/set"
+	avalancheWarp "github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	warpMessage "github.com/ava-labs/avalanchego/vms/platformvm/warp/message"
+	warpPayload "github.com/ava-labs/avalanchego/vms/platformvm/warp/payload"
+	validatorsetsig "github.com/ava-labs/icm-contracts/abi-bindings/go/governance/ValidatorSetSig"
+	teleportermessenger "github.com/ava-labs/icm-contracts/abi-bindings/go/teleporter/TeleporterMessenger"
+	teleporterregistry "github.com/ava-labs/icm-contracts/abi-bindings/go/teleporter/registry/TeleporterRegistry"
+	"github.com/spf13/cobra"
+)
+
+var errUnknownWarpPayload = errors.New("bytes are not a Warp message or a known Warp payload")
+
+var warpCmd = &cobra.Command{
+	Use:   "warp",
+	Short: "Inspects Warp messages and payloads",
+	Long:  `Commands for inspecting Warp messages and the payloads they carry.`,
+	Args:  cobra.NoArgs,
+}
+
+var warpDecodeCmd = &cobra.Command{
+	Use:   "decode WARP_BYTES",
+	Short: "Decodes hex encoded Warp message or payload bytes into a readable tree",
+	Long: `Given the hex encoded bytes of a signed Warp message, an unsigned Warp message, or a
+Warp payload, this command detects the encoding and prints a readable tree of its contents.
+Signed messages include their BitSetSignature. AddressedCall payloads are further decoded
+as P-Chain messages (RegisterL1Validator, L1ValidatorRegistration, L1ValidatorWeight and
+SubnetToL1Conversion), TeleporterMessages, ValidatorSetSigMessages or TeleporterRegistry
+protocol entries. Payloads that match none of these are printed as raw bytes.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		b, err := hex.DecodeString(strings.TrimPrefix(args[0], "0x"))
+		cobra.CheckErr(err)
+
+		tree, err := decodeWarpBytes(b)
+		cobra.CheckErr(err)
+		cmd.Println(tree.String())
+		cmd.Println("Warp decode command ran successfully")
+	},
+}
+
+// decodeWarpBytes detects whether b is a signed Warp message, an unsigned Warp message
+// or a bare Warp payload, and decodes it accordingly.
+func decodeWarpBytes(b []byte) (*decodedTree, error) {
+	if signedMsg, err := avalancheWarp.ParseMessage(b); err == nil {
+		return decodeSignedWarpMessage(signedMsg), nil
+	}
+	if unsignedMsg, err := avalancheWarp.ParseUnsignedMessage(b); err == nil {
+		return decodeUnsignedWarpMessage(unsignedMsg), nil
+	}
+	if tree := decodeWarpPayload(b); tree != nil {
+		return tree, nil
+	}
+	return nil, errUnknownWarpPayload
+}
+
+func decodeSignedWarpMessage(msg *avalancheWarp.Message) *decodedTree {
+	tree := newDecodedTree("Signed Warp Message")
+	tree.addField("ID", msg.ID().Hex())
+	tree.addChild(decodeUnsignedWarpMessage(&msg.UnsignedMessage))
+
+	sigTree := newDecodedTree(fmt.Sprintf("%T", msg.Signature))
+	if sig, ok := msg.Signature.(*avalancheWarp.BitSetSignature); ok {
+		sigTree.Name = "BitSetSignature"
+		signers := set.BitsFromBytes(sig.Signers)
+		indices := make([]int, 0, signers.Len())
+		for i := 0; i < signers.BitLen(); i++ {
+			if signers.Contains(i) {
+				indices = append(indices, i)
+			}
+		}
+		sigTree.addField("Signers", indices)
+		sigTree.addField("Num Signers", len(indices))
+		sigTree.addField("Signature", "0x"+hex.EncodeToString(sig.Signature[:]))
+	}
+	tree.addChild(sigTree)
+	return tree
+}
+
+func decodeUnsignedWarpMessage(msg *avalancheWarp.UnsignedMessage) *decodedTree {
+	tree := newDecodedTree("Unsigned Warp Message")
+	tree.addField("ID", msg.ID().Hex())
+	tree.addField("Network ID", msg.NetworkID)
+	tree.addField("Source Chain ID", msg.SourceChainID)
+	if payloadTree := decodeWarpPayload(msg.Payload); payloadTree != nil {
+		tree.addChild(payloadTree)
+	} else {
+		tree.addChild(rawPayloadTree(msg.Payload))
+	}
+	return tree
+}
+
+// decodeWarpPayload decodes the payload of an unsigned Warp message, returning nil if it is
+// neither a Warp payload nor a P-Chain message.
+func decodeWarpPayload(b []byte) *decodedTree {
+	p, err := warpPayload.Parse(b)
+	if err != nil {
+		return decodePChainMessage(b)
+	}
+	switch p := p.(type) {
+	case *warpPayload.Hash:
+		tree := newDecodedTree("Hash")
+		tree.addField("Hash", p.Hash.Hex())
+		return tree
+	case *warpPayload.AddressedCall:
+		tree := newDecodedTree("AddressedCall")
+		if len(p.SourceAddress) == 0 {
+			tree.addField("Source Address", "(empty, P-Chain)")
+		} else {
+			tree.addField("Source Address", "0x"+hex.EncodeToString(p.SourceAddress))
+		}
+		tree.addChild(decodeAddressedCallPayload(p.Payload))
+		return tree
+	default:
+		return nil
+	}
+}
+
+// decodeAddressedCallPayload decodes the payload of an AddressedCall. P-Chain messages are
+// detected by their codec encoding, and ABI encoded payloads are only accepted if they
+// re-encode to the same bytes, since ABI decoding alone is too permissive to tell them apart.
+func decodeAddressedCallPayload(b []byte) *decodedTree {
+	if tree := decodePChainMessage(b); tree != nil {
+		return tree
+	}
+
+	var teleporterMessage teleportermessenger.TeleporterMessage
+	if err := teleporterMessage.Unpack(b); err == nil && repacks(teleporterMessage.Pack, b) {
+		return teleporterMessageTree(teleporterMessage)
+	}
+
+	var validatorSetSigMessage validatorsetsig.ValidatorSetSigMessage
+	if err := validatorSetSigMessage.Unpack(b); err == nil && repacks(validatorSetSigMessage.Pack, b) {
+		tree := newDecodedTree("ValidatorSetSigMessage")
+		tree.addField("Target Blockchain ID", ids.ID(validatorSetSigMessage.TargetBlockchainID))
+		tree.addField("ValidatorSetSig Address", validatorSetSigMessage.ValidatorSetSigAddress.Hex())
+		tree.addField("Target Contract Address", validatorSetSigMessage.TargetContractAddress.Hex())
+		tree.addField("Nonce", validatorSetSigMessage.Nonce)
+		tree.addField("Value", validatorSetSigMessage.Value)
+		tree.addField("Payload", "0x"+hex.EncodeToString(validatorSetSigMessage.Payload))
+		return tree
+	}
+
+	entry, destinationAddress, err := teleporterregistry.UnpackTeleporterRegistryWarpPayload(b)
+	if err == nil && repacks(func() ([]byte, error) {
+		return teleporterregistry.PackTeleporterRegistryWarpPayload(entry, destinationAddress)
+	}, b) {
+		tree := newDecodedTree("TeleporterRegistry Protocol Entry")
+		tree.addField("Version", entry.Version)
+		tree.addField("Protocol Address", entry.ProtocolAddress.Hex())
+		tree.addField("Destination Address", destinationAddress.Hex())
+		return tree
+	}
+
+	return rawPayloadTree(b)
+}
+
+// decodePChainMessage decodes the P-Chain Warp messages used by validator managers,
+// returning nil if b is not one of them.
+func decodePChainMessage(b []byte) *decodedTree {
+	p, err := warpMessage.Parse(b)
+	if err != nil {
+		return nil
+	}
+	switch p := p.(type) {
+	case *warpMessage.RegisterL1Validator:
+		tree := newDecodedTree("RegisterL1Validator")
+		tree.addField("Validation ID", p.ValidationID())
+		tree.addField("Subnet ID", p.SubnetID)
+		tree.addField("Node ID", nodeIDString(p.NodeID))
+		tree.addField("BLS Public Key", "0x"+hex.EncodeToString(p.BLSPublicKey[:]))
+		tree.addField("Expiry", p.Expiry)
+		tree.addField("Weight", p.Weight)
+		tree.addChild(pChainOwnerTree("Remaining Balance Owner", p.RemainingBalanceOwner))
+		tree.addChild(pChainOwnerTree("Disable Owner", p.DisableOwner))
+		return tree
+	case *warpMessage.L1ValidatorRegistration:
+		tree := newDecodedTree("L1ValidatorRegistration")
+		tree.addField("Validation ID", p.ValidationID)
+		tree.addField("Registered", p.Registered)
+		return tree
+	case *warpMessage.L1ValidatorWeight:
+		tree := newDecodedTree("L1ValidatorWeight")
+		tree.addField("Validation ID", p.ValidationID)
+		tree.addField("Nonce", p.Nonce)
+		tree.addField("Weight", p.Weight)
+		return tree
+	case *warpMessage.SubnetToL1Conversion:
+		tree := newDecodedTree("SubnetToL1Conversion")
+		tree.addField("Conversion ID", p.ID)
+		return tree
+	default:
+		return nil
+	}
+}
+
+func pChainOwnerTree(name string, owner warpMessage.PChainOwner) *decodedTree {
+	tree := newDecodedTree(name)
+	tree.addField("Threshold", owner.Threshold)
+	addresses := make([]string, len(owner.Addresses))
+	for i, address := range owner.Addresses {
+		addresses[i] = address.String()
+	}
+	tree.addField("Addresses", addresses)
+	return tree
+}
+
+func teleporterMessageTree(msg teleportermessenger.TeleporterMessage) *decodedTree {
+	tree := newDecodedTree("TeleporterMessage")
+	tree.addField("Message Nonce", msg.MessageNonce)
+	tree.addField("Origin Sender Address", msg.OriginSenderAddress.Hex())
+	tree.addField("Destination Blockchain ID", ids.ID(msg.DestinationBlockchainID))
+	tree.addField("Destination Address", msg.DestinationAddress.Hex())
+	tree.addField("Required Gas Limit", msg.RequiredGasLimit)
+	relayers := make([]string, len(msg.AllowedRelayerAddresses))
+	for i, relayer := range msg.AllowedRelayerAddresses {
+		relayers[i] = relayer.Hex()
+	}
+	tree.addField("Allowed Relayer Addresses", relayers)
+	tree.addField("Message", "0x"+hex.EncodeToString(msg.Message))
+	for _, receipt := range msg.Receipts {
+		receiptTree := newDecodedTree("TeleporterMessageReceipt")
+		receiptTree.addField("Received Message Nonce", receipt.ReceivedMessageNonce)
+		receiptTree.addField("Relayer Reward Address", receipt.RelayerRewardAddress.Hex())
+		tree.addChild(receiptTree)
+	}
+	return tree
+}
+
+func rawPayloadTree(b []byte) *decodedTree {
+	tree := newDecodedTree("Unknown Payload")
+	tree.addField("Bytes", "0x"+hex.EncodeToString(b))
+	return tree
+}
+
+// nodeIDString formats a node ID carried as a byte slice, falling back to hex if it has the wrong length.
+func nodeIDString(b []byte) string {
+	nodeID, err := ids.ToNodeID(b)
+	if err != nil {
+		return "0x" + hex.EncodeToString(b)
+	}
+	return nodeID.String()
+}
+
+func repacks(pack func() ([]byte, error), b []byte) bool {
+	packed, err := pack()
+	return err == nil && bytes.Equal(packed, b)
+}
+
+func init() {
+	rootCmd.AddCommand(warpCmd)
+	warpCmd.AddCommand(warpDecodeCmd)
+}
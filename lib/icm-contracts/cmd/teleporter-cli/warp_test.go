@@ -0,0 +1,196 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	avalancheWarp "github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	warpMessage "github.com/ava-labs/avalanchego/vms/platformvm/warp/message"
+	warpPayload "github.com/ava-labs/avalanchego/vms/platformvm/warp/payload"
+	validatorsetsig "github.com/ava-labs/icm-contracts/abi-bindings/go/governance/ValidatorSetSig"
+	teleportermessenger "github.com/ava-labs/icm-contracts/abi-bindings/go/teleporter/TeleporterMessenger"
+	teleporterregistry "github.com/ava-labs/icm-contracts/abi-bindings/go/teleporter/registry/TeleporterRegistry"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWarpDecodeCmd(t *testing.T) {
+	var tests = []struct {
+		name string
+		args []string
+		err  error
+		out  string
+	}{
+		{
+			name: "no args",
+			args: []string{"warp", "decode"},
+			err:  fmt.Errorf("accepts 1 arg(s), received 0"),
+		},
+		{
+			name: "help",
+			args: []string{"warp", "decode", "--help"},
+			err:  nil,
+			out:  "Given the hex encoded bytes of a signed Warp message, an unsigned Warp message",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := executeTestCmd(t, rootCmd, tt.args...)
+			if tt.err != nil {
+				require.ErrorContains(t, err, tt.err.Error())
+			} else {
+				require.NoError(t, err)
+				require.Contains(t, out, tt.out)
+			}
+		})
+	}
+}
+
+func TestDecodeWarpBytes(t *testing.T) {
+	address := common.HexToAddress("0x253b2784c75e510dD0fF1da844684a1aC0aa5fcf")
+
+	teleporterMessage := teleportermessenger.TeleporterMessage{
+		MessageNonce:            big.NewInt(1),
+		OriginSenderAddress:     address,
+		DestinationBlockchainID: ids.ID{1},
+		DestinationAddress:      address,
+		RequiredGasLimit:        big.NewInt(100_000),
+		AllowedRelayerAddresses: []common.Address{},
+		Receipts: []teleportermessenger.TeleporterMessageReceipt{
+			{ReceivedMessageNonce: big.NewInt(7), RelayerRewardAddress: address},
+		},
+		Message: []byte{1, 2, 3, 4},
+	}
+	teleporterMessageBytes, err := teleporterMessage.Pack()
+	require.NoError(t, err)
+
+	validatorSetSigMessage := validatorsetsig.ValidatorSetSigMessage{
+		TargetBlockchainID:     ids.ID{2},
+		ValidatorSetSigAddress: address,
+		TargetContractAddress:  address,
+		Nonce:                  big.NewInt(3),
+		Value:                  big.NewInt(0),
+		Payload:                []byte{5, 6},
+	}
+	validatorSetSigBytes, err := validatorSetSigMessage.Pack()
+	require.NoError(t, err)
+
+	registryBytes, err := teleporterregistry.PackTeleporterRegistryWarpPayload(
+		teleporterregistry.ProtocolRegistryEntry{Version: big.NewInt(2), ProtocolAddress: address},
+		address,
+	)
+	require.NoError(t, err)
+
+	weight, err := warpMessage.NewL1ValidatorWeight(ids.ID{3}, 4, 5)
+	require.NoError(t, err)
+
+	hash, err := warpPayload.NewHash(ids.ID{4})
+	require.NoError(t, err)
+
+	newUnsignedMessage := func(sourceAddress []byte, payload []byte) *avalancheWarp.UnsignedMessage {
+		addressedCall, err := warpPayload.NewAddressedCall(sourceAddress, payload)
+		require.NoError(t, err)
+		unsignedMsg, err := avalancheWarp.NewUnsignedMessage(1, ids.ID{5}, addressedCall.Bytes())
+		require.NoError(t, err)
+		return unsignedMsg
+	}
+
+	teleporterUnsignedMsg := newUnsignedMessage(address.Bytes(), teleporterMessageBytes)
+	signedMsg, err := avalancheWarp.NewMessage(teleporterUnsignedMsg, &avalancheWarp.BitSetSignature{
+		Signers: []byte{0b101},
+	})
+	require.NoError(t, err)
+
+	var tests = []struct {
+		name  string
+		bytes []byte
+		err   error
+		path  []string
+		out   string
+	}{
+		{
+			name:  "signed teleporter message",
+			bytes: signedMsg.Bytes(),
+			path:  []string{"Signed Warp Message", "Unsigned Warp Message", "AddressedCall", "TeleporterMessage"},
+			out:   "Signers: [0 2]",
+		},
+		{
+			name:  "unsigned teleporter message",
+			bytes: teleporterUnsignedMsg.Bytes(),
+			path:  []string{"Unsigned Warp Message", "AddressedCall", "TeleporterMessage", "TeleporterMessageReceipt"},
+			out:   "Received Message Nonce: 7",
+		},
+		{
+			name:  "p-chain message",
+			bytes: newUnsignedMessage(nil, weight.Bytes()).Bytes(),
+			path:  []string{"Unsigned Warp Message", "AddressedCall", "L1ValidatorWeight"},
+			out:   "Source Address: (empty, P-Chain)",
+		},
+		{
+			name:  "validator set sig message",
+			bytes: newUnsignedMessage(address.Bytes(), validatorSetSigBytes).Bytes(),
+			path:  []string{"Unsigned Warp Message", "AddressedCall", "ValidatorSetSigMessage"},
+			out:   "Payload: 0x0506",
+		},
+		{
+			name:  "registry entry",
+			bytes: newUnsignedMessage(nil, registryBytes).Bytes(),
+			path:  []string{"Unsigned Warp Message", "AddressedCall", "TeleporterRegistry Protocol Entry"},
+			out:   "Version: 2",
+		},
+		{
+			name:  "unknown addressed call payload",
+			bytes: newUnsignedMessage(address.Bytes(), []byte{1, 2, 3}).Bytes(),
+			path:  []string{"Unsigned Warp Message", "AddressedCall", "Unknown Payload"},
+			out:   "Bytes: 0x010203",
+		},
+		{
+			name:  "bare payload",
+			bytes: hash.Bytes(),
+			path:  []string{"Hash"},
+			out:   "Hash: " + ids.ID{4}.Hex(),
+		},
+		{
+			name:  "bare p-chain message",
+			bytes: weight.Bytes(),
+			path:  []string{"L1ValidatorWeight"},
+			out:   "Nonce: 4",
+		},
+		{
+			name:  "unknown bytes",
+			bytes: []byte{1, 2, 3},
+			err:   errUnknownWarpPayload,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree, err := decodeWarpBytes(tt.bytes)
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Contains(t, tree.String(), tt.out)
+
+			// Follow the last child at each level, which is the decoded payload.
+			node := tree
+			for i, name := range tt.path {
+				if i > 0 {
+					var next *decodedTree
+					for _, child := range node.Children {
+						if child.Name == name {
+							next = child
+						}
+					}
+					require.NotNil(t, next, "missing %s under %s", name, node.Name)
+					node = next
+				}
+				require.Equal(t, name, node.Name)
+			}
+		})
+	}
+}
@@ -0,0 +1,287 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/validators"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+	avajson "github.com/ava-labs/avalanchego/utils/json"
+	"github.com/ava-labs/avalanchego/utils/rpc"
+	avalancheWarp "github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	warpUtils "github.com/ava-labs/icm-contracts/utils/warp-utils"
+	"github.com/ava-labs/subnet-evm/precompile/contracts/warp"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+// pChainPath is the path of the P-Chain API on an AvalancheGo node.
+const pChainPath = "/ext/bc/P"
+
+var (
+	warpVerifyValidators      string
+	warpVerifyPChainRPC       string
+	warpVerifySubnetID        string
+	warpVerifyPChainHeight    uint64
+	warpVerifyQuorumNumerator uint64
+
+	errNoValidatorSetSource       = errors.New("one of --validators or --pchain-rpc is required")
+	errMultipleValidatorSetSource = errors.New("only one of --validators or --pchain-rpc can be set")
+	errDuplicateValidator         = errors.New("duplicate validator node ID")
+)
+
+var warpVerifyCmd = &cobra.Command{
+	Use: "verify (--validators FILE | --pchain-rpc URL [--subnet-id ID] [--pchain-height N]) " +
+		"[--quorum-numerator N] SIGNED_WARP_MESSAGE",
+	Short: "Verifies the aggregate signature of a signed Warp message against a validator set",
+	Long: `Given a hex encoded signed Warp message, this command verifies its BitSetSignature against a
+validator set, reports which validators signed it and their share of the stake, and checks the
+signed weight against the quorum. The signature and the quorum are checked independently, so a
+signature that is valid for another view of the validator set can be told apart from one that
+is invalid.
+
+The validator set is either read from a JSON file passed with --validators, holding a list of
+{"nodeID": "NodeID-...", "publicKey": "0x...", "weight": N} validators or the result of the
+P-Chain's platform.getValidatorsAt, or fetched from the node passed with --pchain-rpc at
+--pchain-height, which defaults to the current P-Chain height. The signing subnet defaults to
+the subnet validating the message's source chain. The quorum defaults to the Warp precompile's
+default of 67%. The command exits with an error if the signature would be rejected.`,
+	Args: cobra.ExactArgs(1),
+	Run:  warpVerifyRun,
+}
+
+// fileValidator is a validator as listed in a --validators file.
+type fileValidator struct {
+	NodeID    ids.NodeID     `json:"nodeID"`
+	PublicKey string         `json:"publicKey"`
+	Weight    avajson.Uint64 `json:"weight"`
+}
+
+// getValidatorOutput is a validator as returned by platform.getValidatorsAt, keyed by node ID.
+type getValidatorOutput struct {
+	PublicKey *string        `json:"publicKey"`
+	Weight    avajson.Uint64 `json:"weight"`
+}
+
+func warpVerifyRun(cmd *cobra.Command, args []string) {
+	b, err := hex.DecodeString(strings.TrimPrefix(args[0], "0x"))
+	cobra.CheckErr(err)
+	signedMsg, err := avalancheWarp.ParseMessage(b)
+	cobra.CheckErr(err)
+
+	var validatorSet map[ids.NodeID]*validators.GetValidatorOutput
+	switch {
+	case warpVerifyValidators != "" && warpVerifyPChainRPC != "":
+		cobra.CheckErr(errMultipleValidatorSetSource)
+	case warpVerifyValidators != "":
+		b, err := os.ReadFile(warpVerifyValidators)
+		cobra.CheckErr(err)
+		validatorSet, err = parseValidatorSet(b)
+		cobra.CheckErr(err)
+	case warpVerifyPChainRPC != "":
+		validatorSet, err = fetchValidatorSet(context.Background(), signedMsg.SourceChainID)
+		cobra.CheckErr(err)
+	default:
+		cobra.CheckErr(errNoValidatorSetSource)
+	}
+
+	verification, err := warpUtils.VerifyWarpSignature(
+		signedMsg,
+		validatorSet,
+		warpVerifyQuorumNumerator,
+		warp.WarpQuorumDenominator,
+	)
+	cobra.CheckErr(err)
+
+	verificationJson, err := json.MarshalIndent(verification, "", "  ")
+	cobra.CheckErr(err)
+	cmd.Println("Warp Message ID: " + signedMsg.ID().String())
+	cmd.Printf("Network ID: %d\n", signedMsg.NetworkID)
+	cmd.Println("Source Chain ID: " + signedMsg.SourceChainID.String())
+	cmd.Println("Signature Verification:\n" + string(verificationJson) + "\n")
+	cobra.CheckErr(verification.Err())
+	cmd.Println("Warp verify command ran successfully")
+}
+
+// parseValidatorSet parses a --validators file, either a list of validators or the result of
+// platform.getValidatorsAt, optionally wrapped in its JSON-RPC response.
+func parseValidatorSet(b []byte) (map[ids.NodeID]*validators.GetValidatorOutput, error) {
+	b = bytes.TrimSpace(b)
+	if bytes.HasPrefix(b, []byte("[")) {
+		var listed []fileValidator
+		if err := json.Unmarshal(b, &listed); err != nil {
+			return nil, err
+		}
+		validatorSet := make(map[ids.NodeID]*validators.GetValidatorOutput, len(listed))
+		for _, vdr := range listed {
+			if _, ok := validatorSet[vdr.NodeID]; ok {
+				return nil, fmt.Errorf("%w: %s", errDuplicateValidator, vdr.NodeID)
+			}
+			output, err := newGetValidatorOutput(vdr.NodeID, &vdr.PublicKey, vdr.Weight)
+			if err != nil {
+				return nil, err
+			}
+			validatorSet[vdr.NodeID] = output
+		}
+		return validatorSet, nil
+	}
+
+	var wrapped struct {
+		Result     json.RawMessage `json:"result"`
+		Validators json.RawMessage `json:"validators"`
+	}
+	if err := json.Unmarshal(b, &wrapped); err != nil {
+		return nil, err
+	}
+	switch {
+	case len(wrapped.Result) > 0:
+		return parseValidatorSet(wrapped.Result)
+	case len(wrapped.Validators) > 0:
+		return parseValidatorSet(wrapped.Validators)
+	}
+
+	var keyed map[string]getValidatorOutput
+	if err := json.Unmarshal(b, &keyed); err != nil {
+		return nil, err
+	}
+	validatorSet := make(map[ids.NodeID]*validators.GetValidatorOutput, len(keyed))
+	for key, vdr := range keyed {
+		nodeID, err := ids.NodeIDFromString(key)
+		if err != nil {
+			return nil, err
+		}
+		output, err := newGetValidatorOutput(nodeID, vdr.PublicKey, vdr.Weight)
+		if err != nil {
+			return nil, err
+		}
+		validatorSet[nodeID] = output
+	}
+	return validatorSet, nil
+}
+
+// newGetValidatorOutput builds a validator from its hex encoded compressed BLS public key, which
+// may be empty for validators without one.
+func newGetValidatorOutput(
+	nodeID ids.NodeID,
+	publicKey *string,
+	weight avajson.Uint64,
+) (*validators.GetValidatorOutput, error) {
+	output := &validators.GetValidatorOutput{NodeID: nodeID, Weight: uint64(weight)}
+	if publicKey == nil || *publicKey == "" {
+		return output, nil
+	}
+	pkBytes, err := hexutil.Decode(*publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key of %s: %w", nodeID, err)
+	}
+	output.PublicKey, err = bls.PublicKeyFromCompressedBytes(pkBytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key of %s: %w", nodeID, err)
+	}
+	return output, nil
+}
+
+// fetchValidatorSet fetches the validator set of the signing subnet from the P-Chain API of the
+// node passed with --pchain-rpc.
+func fetchValidatorSet(
+	ctx context.Context,
+	sourceChainID ids.ID,
+) (map[ids.NodeID]*validators.GetValidatorOutput, error) {
+	endpoint, err := url.Parse(warpVerifyPChainRPC)
+	if err != nil {
+		return nil, err
+	}
+	if endpoint.Path == "" || endpoint.Path == "/" {
+		endpoint.Path = pChainPath
+	}
+	requester := rpc.NewEndpointRequester(endpoint.String())
+
+	var subnetID ids.ID
+	if warpVerifySubnetID != "" {
+		subnetID, err = ids.FromString(warpVerifySubnetID)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		var validatedBy struct {
+			SubnetID ids.ID `json:"subnetID"`
+		}
+		params := map[string]ids.ID{"blockchainID": sourceChainID}
+		if err := requester.SendRequest(ctx, "platform.validatedBy", params, &validatedBy); err != nil {
+			return nil, fmt.Errorf("failed to get the subnet validating %s: %w", sourceChainID, err)
+		}
+		subnetID = validatedBy.SubnetID
+	}
+
+	height := avajson.Uint64(warpVerifyPChainHeight)
+	if height == 0 {
+		var current struct {
+			Height avajson.Uint64 `json:"height"`
+		}
+		if err := requester.SendRequest(ctx, "platform.getHeight", struct{}{}, &current); err != nil {
+			return nil, fmt.Errorf("failed to get the P-Chain height: %w", err)
+		}
+		height = current.Height
+	}
+	logger.Info(
+		"Fetching validator set",
+		zap.String("subnetID", subnetID.String()),
+		zap.Uint64("pChainHeight", uint64(height)),
+	)
+
+	var reply json.RawMessage
+	err = requester.SendRequest(ctx, "platform.getValidatorsAt", struct {
+		Height   avajson.Uint64 `json:"height"`
+		SubnetID ids.ID         `json:"subnetID"`
+	}{Height: height, SubnetID: subnetID}, &reply)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the validator set of %s: %w", subnetID, err)
+	}
+	return parseValidatorSet(reply)
+}
+
+func init() {
+	warpCmd.AddCommand(warpVerifyCmd)
+	warpVerifyCmd.Flags().StringVar(
+		&warpVerifyValidators,
+		"validators",
+		"",
+		"JSON file holding the validator set of the signing subnet",
+	)
+	warpVerifyCmd.Flags().StringVar(
+		&warpVerifyPChainRPC,
+		"pchain-rpc",
+		"",
+		"URL of a node to fetch the validator set of the signing subnet from",
+	)
+	warpVerifyCmd.Flags().StringVar(
+		&warpVerifySubnetID,
+		"subnet-id",
+		"",
+		"Subnet ID whose validators sign the message, if not the source blockchain's subnet",
+	)
+	warpVerifyCmd.Flags().Uint64Var(
+		&warpVerifyPChainHeight,
+		"pchain-height",
+		0,
+		"P-Chain height to fetch the validator set at, defaults to the current height",
+	)
+	warpVerifyCmd.Flags().Uint64Var(
+		&warpVerifyQuorumNumerator,
+		"quorum-numerator",
+		warp.WarpDefaultQuorumNumerator,
+		"Percentage of the validator set's weight required to sign the message",
+	)
+}
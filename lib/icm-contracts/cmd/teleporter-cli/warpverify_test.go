@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWarpVerifyCmd(t *testing.T) {
+	var tests = []struct {
+		name string
+		args []string
+		err  error
+		out  string
+	}{
+		{
+			name: "no args",
+			args: []string{"warp", "verify"},
+			err:  fmt.Errorf("accepts 1 arg(s), received 0"),
+		},
+		{
+			name: "help",
+			args: []string{"warp", "verify", "--help"},
+			err:  nil,
+			out:  "Given a hex encoded signed Warp message, this command verifies its BitSetSignature",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := executeTestCmd(t, rootCmd, tt.args...)
+			if tt.err != nil {
+				require.ErrorContains(t, err, tt.err.Error())
+			} else {
+				require.NoError(t, err)
+				require.Contains(t, out, tt.out)
+			}
+		})
+	}
+}
+
+func TestParseValidatorSet(t *testing.T) {
+	sk, err := bls.NewSecretKey()
+	require.NoError(t, err)
+	pk := bls.PublicFromSecretKey(sk)
+	publicKey := hexutil.Encode(bls.PublicKeyToCompressedBytes(pk))
+	nodeID := ids.BuildTestNodeID([]byte{1})
+	otherNodeID := ids.BuildTestNodeID([]byte{2})
+
+	var tests = []struct {
+		name    string
+		input   string
+		weights map[ids.NodeID]uint64
+		err     error
+	}{
+		{
+			name: "list",
+			input: fmt.Sprintf(`[{"nodeID": "%s", "publicKey": "%s", "weight": 20}, {"nodeID": "%s", "weight": "10"}]`,
+				nodeID, publicKey, otherNodeID),
+			weights: map[ids.NodeID]uint64{nodeID: 20, otherNodeID: 10},
+		},
+		{
+			name: "getValidatorsAt result",
+			input: fmt.Sprintf(`{"%s": {"publicKey": "%s", "weight": "20"}, "%s": {"weight": "10"}}`,
+				nodeID, publicKey, otherNodeID),
+			weights: map[ids.NodeID]uint64{nodeID: 20, otherNodeID: 10},
+		},
+		{
+			name: "getValidatorsAt response",
+			input: fmt.Sprintf(
+				`{"jsonrpc": "2.0", "result": {"validators": {"%s": {"publicKey": "%s", "weight": "20"}}}, "id": 1}`,
+				nodeID, publicKey,
+			),
+			weights: map[ids.NodeID]uint64{nodeID: 20},
+		},
+		{
+			name:  "duplicate validator",
+			input: fmt.Sprintf(`[{"nodeID": "%s", "weight": 20}, {"nodeID": "%s", "weight": 10}]`, nodeID, nodeID),
+			err:   errDuplicateValidator,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validatorSet, err := parseValidatorSet([]byte(tt.input))
+			require.ErrorIs(t, err, tt.err)
+			if tt.err != nil {
+				return
+			}
+			require.Len(t, validatorSet, len(tt.weights))
+			for id, weight := range tt.weights {
+				require.Equal(t, id, validatorSet[id].NodeID)
+				require.Equal(t, weight, validatorSet[id].Weight)
+			}
+			require.Equal(t, pk, validatorSet[nodeID].PublicKey)
+			if vdr, ok := validatorSet[otherNodeID]; ok {
+				require.Nil(t, vdr.PublicKey)
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	teleportermessenger "github.com/ava-labs/icm-contracts/abi-bindings/go/teleporter/TeleporterMessenger"
+	"github.com/ava-labs/subnet-evm/accounts/abi/bind"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/ethclient"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+const watchResubscribeDelay = 5 * time.Second
+
+var (
+	wsEndpoint        string
+	watchAddress      string
+	watchDestination  string
+	watchSender       string
+	watchMessageIDArg string
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch --ws WS_URL --teleporter-address CONTRACT_ADDRESS",
+	Short: "Streams decoded Teleporter events as they are emitted",
+	Long: `Subscribes to a TeleporterMessenger contract over WebSocket and prints each
+SendCrossChainMessage, ReceiveCrossChainMessage and MessageExecutionFailed event
+in a more human readable format as soon as it is emitted. Events can optionally be
+filtered by destination blockchain ID, origin sender address and message ID. If the
+WebSocket connection drops, the command resubscribes automatically. Events emitted
+while disconnected are not replayed.`,
+	Args: cobra.NoArgs,
+	Run:  watchRun,
+}
+
+// watchFilter restricts which events are printed by the watch command.
+// Nil fields match every event.
+type watchFilter struct {
+	destinationBlockchainID *ids.ID
+	sender                  *common.Address
+	messageID               *ids.ID
+}
+
+func (f watchFilter) matches(messageID [32]byte, message teleportermessenger.TeleporterMessage) bool {
+	if f.messageID != nil && *f.messageID != ids.ID(messageID) {
+		return false
+	}
+	if f.destinationBlockchainID != nil && *f.destinationBlockchainID != ids.ID(message.DestinationBlockchainID) {
+		return false
+	}
+	if f.sender != nil && *f.sender != message.OriginSenderAddress {
+		return false
+	}
+	return true
+}
+
+// messageIDTopics returns the indexed message ID filter to pass to the Watch* filterers.
+func (f watchFilter) messageIDTopics() [][32]byte {
+	if f.messageID == nil {
+		return nil
+	}
+	return [][32]byte{*f.messageID}
+}
+
+func watchRun(cmd *cobra.Command, args []string) {
+	address, err := parseAddress(watchAddress)
+	cobra.CheckErr(err)
+
+	var filter watchFilter
+	if watchDestination != "" {
+		destinationBlockchainID, err := parseBlockchainID(watchDestination)
+		cobra.CheckErr(err)
+		filter.destinationBlockchainID = &destinationBlockchainID
+	}
+	if watchSender != "" {
+		sender, err := parseAddress(watchSender)
+		cobra.CheckErr(err)
+		filter.sender = &sender
+	}
+	if watchMessageIDArg != "" {
+		messageID, err := parseHexID(watchMessageIDArg)
+		cobra.CheckErr(err)
+		filter.messageID = &messageID
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	for {
+		err := watchEvents(ctx, cmd, address, filter)
+		if ctx.Err() != nil {
+			cmd.Println("Watch command stopped")
+			return
+		}
+		logger.Warn(
+			"Teleporter event subscription dropped, resubscribing",
+			zap.Error(err),
+			zap.Duration("delay", watchResubscribeDelay),
+		)
+		select {
+		case <-ctx.Done():
+			cmd.Println("Watch command stopped")
+			return
+		case <-time.After(watchResubscribeDelay):
+		}
+	}
+}
+
+// watchEvents subscribes to the Teleporter events and prints them until the context
+// is cancelled or one of the subscriptions fails.
+func watchEvents(
+	ctx context.Context,
+	cmd *cobra.Command,
+	address common.Address,
+	filter watchFilter,
+) error {
+	wsClient, err := ethclient.DialContext(ctx, wsEndpoint)
+	if err != nil {
+		return err
+	}
+	defer wsClient.Close()
+
+	messenger, err := teleportermessenger.NewTeleporterMessengerFilterer(address, wsClient)
+	if err != nil {
+		return err
+	}
+
+	var destinationTopics [][32]byte
+	if filter.destinationBlockchainID != nil {
+		destinationTopics = [][32]byte{*filter.destinationBlockchainID}
+	}
+	opts := &bind.WatchOpts{Context: ctx}
+
+	sendCh := make(chan *teleportermessenger.TeleporterMessengerSendCrossChainMessage)
+	sendSub, err := messenger.WatchSendCrossChainMessage(opts, sendCh, filter.messageIDTopics(), destinationTopics)
+	if err != nil {
+		return err
+	}
+	defer sendSub.Unsubscribe()
+
+	receiveCh := make(chan *teleportermessenger.TeleporterMessengerReceiveCrossChainMessage)
+	receiveSub, err := messenger.WatchReceiveCrossChainMessage(opts, receiveCh, filter.messageIDTopics(), nil, nil)
+	if err != nil {
+		return err
+	}
+	defer receiveSub.Unsubscribe()
+
+	failedCh := make(chan *teleportermessenger.TeleporterMessengerMessageExecutionFailed)
+	failedSub, err := messenger.WatchMessageExecutionFailed(opts, failedCh, filter.messageIDTopics(), nil)
+	if err != nil {
+		return err
+	}
+	defer failedSub.Unsubscribe()
+
+	logger.Info("Watching Teleporter events", zap.String("address", address.Hex()))
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-sendSub.Err():
+			return err
+		case err := <-receiveSub.Err():
+			return err
+		case err := <-failedSub.Err():
+			return err
+		case event := <-sendCh:
+			if filter.matches(event.MessageID, event.Message) {
+				printWatchedEvent(cmd, event.Raw)
+			}
+		case event := <-receiveCh:
+			if filter.matches(event.MessageID, event.Message) {
+				printWatchedEvent(cmd, event.Raw)
+			}
+		case event := <-failedCh:
+			if filter.matches(event.MessageID, event.Message) {
+				printWatchedEvent(cmd, event.Raw)
+			}
+		}
+	}
+}
+
+func printWatchedEvent(cmd *cobra.Command, log types.Log) {
+	event, out, err := teleportermessenger.ParseLog(log)
+	if err != nil {
+		logger.Error("Failed to parse Teleporter event", zap.Error(err))
+		return
+	}
+
+	cmd.Println(event.String() + " Log:")
+	cmd.Println(out.(fmt.Stringer).String() + "\n")
+}
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+	watchCmd.Flags().StringVar(&wsEndpoint, "ws", "", "WebSocket endpoint to connect to the node")
+	watchCmd.Flags().StringVarP(&watchAddress, "teleporter-address", "t", "", "Teleporter contract address")
+	watchCmd.Flags().StringVar(
+		&watchDestination,
+		"destination-blockchain-id",
+		"",
+		"Only print events for messages to this blockchain ID (CB58 or hex)",
+	)
+	watchCmd.Flags().StringVar(&watchSender, "sender", "", "Only print events for messages from this origin sender")
+	watchCmd.Flags().StringVar(&watchMessageIDArg, "message-id", "", "Only print events for this message ID")
+
+	err := watchCmd.MarkFlagRequired("ws")
+	cobra.CheckErr(err)
+	err = watchCmd.MarkFlagRequired("teleporter-address")
+	cobra.CheckErr(err)
+}
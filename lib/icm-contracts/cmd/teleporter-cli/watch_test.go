@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	teleportermessenger "github.com/ava-labs/icm-contracts/abi-bindings/go/teleporter/TeleporterMessenger"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchCmd(t *testing.T) {
+	var tests = []struct {
+		name string
+		args []string
+		err  error
+		out  string
+	}{
+		{
+			name: "no args",
+			args: []string{"watch"},
+			err:  fmt.Errorf("required flag(s) \"teleporter-address\", \"ws\" not set"),
+		},
+		{
+			name: "help",
+			args: []string{"watch", "--help"},
+			err:  nil,
+			out:  "Subscribes to a TeleporterMessenger contract over WebSocket",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := executeTestCmd(t, rootCmd, tt.args...)
+			if tt.err != nil {
+				require.ErrorContains(t, err, tt.err.Error())
+			} else {
+				require.NoError(t, err)
+				require.Contains(t, out, tt.out)
+			}
+		})
+	}
+}
+
+func TestWatchFilter(t *testing.T) {
+	messageID := ids.ID{1}
+	destinationBlockchainID := ids.ID{2}
+	sender := common.HexToAddress("0x0123456789abcdef0123456789abcdef01234567")
+	message := teleportermessenger.TeleporterMessage{
+		OriginSenderAddress:     sender,
+		DestinationBlockchainID: destinationBlockchainID,
+	}
+	otherID := ids.ID{3}
+	otherSender := common.HexToAddress("0x1111111111111111111111111111111111111111")
+
+	var tests = []struct {
+		name     string
+		filter   watchFilter
+		expected bool
+	}{
+		{
+			name:     "empty",
+			filter:   watchFilter{},
+			expected: true,
+		},
+		{
+			name: "all match",
+			filter: watchFilter{
+				destinationBlockchainID: &destinationBlockchainID,
+				sender:                  &sender,
+				messageID:               &messageID,
+			},
+			expected: true,
+		},
+		{
+			name:     "message ID mismatch",
+			filter:   watchFilter{messageID: &otherID},
+			expected: false,
+		},
+		{
+			name:     "destination mismatch",
+			filter:   watchFilter{destinationBlockchainID: &otherID},
+			expected: false,
+		},
+		{
+			name:     "sender mismatch",
+			filter:   watchFilter{sender: &otherSender},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, tt.filter.matches(messageID, message))
+		})
+	}
+}
@@ -817,17 +817,11 @@ func CreateOffChainRegistryMessage(
 	registryAddress common.Address,
 	entry teleporterregistry.ProtocolRegistryEntry,
 ) *avalancheWarp.UnsignedMessage {
-	sourceAddress := []byte{}
-	payloadBytes, err := teleporterregistry.PackTeleporterRegistryWarpPayload(entry, registryAddress)
-	Expect(err).Should(BeNil())
-
-	addressedPayload, err := payload.NewAddressedCall(sourceAddress, payloadBytes)
-	Expect(err).Should(BeNil())
-
-	unsignedMessage, err := avalancheWarp.NewUnsignedMessage(
+	unsignedMessage, err := teleporterregistry.NewOffChainRegistryMessage(
 		networkID,
 		l1.BlockchainID,
-		addressedPayload.Bytes(),
+		registryAddress,
+		entry,
 	)
 	Expect(err).Should(BeNil())
 
@@ -0,0 +1,133 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package utils
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/validators"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+	"github.com/ava-labs/avalanchego/utils/set"
+	avalancheWarp "github.com/ava-labs/avalanchego/vms/platformvm/warp"
+)
+
+var (
+	ErrUnsupportedSignature = errors.New("signature is not a BitSetSignature")
+	ErrInvalidQuorum        = errors.New("quorum numerator must be positive and at most the denominator")
+)
+
+// ValidatorSignature is a validator of the canonical validator set of a Warp message's signers,
+// and whether it signed the message.
+type ValidatorSignature struct {
+	Index     int
+	NodeIDs   []ids.NodeID
+	PublicKey []byte
+	Weight    uint64
+	// StakeShare is the validator's share of the total weight of the validator set.
+	StakeShare float64
+	Signed     bool
+}
+
+// SignatureVerification is the result of verifying the BitSetSignature of a signed Warp message
+// against a validator set.
+type SignatureVerification struct {
+	Validators        []ValidatorSignature
+	NumSigners        int
+	SignedWeight      uint64
+	TotalWeight       uint64
+	QuorumNumerator   uint64
+	QuorumDenominator uint64
+	QuorumReached     bool
+	SignatureValid    bool
+}
+
+// Err returns the first reason the signature would be rejected by a Warp verifier, in the order
+// checked by avalancheWarp.BitSetSignature.Verify, or nil if it would be accepted.
+func (v *SignatureVerification) Err() error {
+	if !v.QuorumReached {
+		return avalancheWarp.VerifyWeight(v.SignedWeight, v.TotalWeight, v.QuorumNumerator, v.QuorumDenominator)
+	}
+	if !v.SignatureValid {
+		return avalancheWarp.ErrInvalidSignature
+	}
+	return nil
+}
+
+// VerifyWarpSignature verifies the BitSetSignature of msg against validatorSet, as returned by
+// the P-Chain's getValidatorsAt for the signing subnet. The validator set is put in canonical
+// order, so that the signature's bit set identifies which validators signed, and the signers'
+// weight is checked against the quorumNum/quorumDen quorum. Unlike the Warp verifier, both the
+// quorum and the aggregate signature are always checked, so that a view of the validator set
+// that disagrees with the signers can be told apart from an invalid signature. An error is
+// returned only if the signature cannot be checked at all.
+func VerifyWarpSignature(
+	msg *avalancheWarp.Message,
+	validatorSet map[ids.NodeID]*validators.GetValidatorOutput,
+	quorumNum uint64,
+	quorumDen uint64,
+) (*SignatureVerification, error) {
+	if quorumNum == 0 || quorumNum > quorumDen {
+		return nil, fmt.Errorf("%w: %d/%d", ErrInvalidQuorum, quorumNum, quorumDen)
+	}
+	signature, ok := msg.Signature.(*avalancheWarp.BitSetSignature)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", ErrUnsupportedSignature, msg.Signature)
+	}
+	signerIndices := set.BitsFromBytes(signature.Signers)
+	if len(signerIndices.Bytes()) != len(signature.Signers) {
+		return nil, avalancheWarp.ErrInvalidBitSet
+	}
+	aggregateSignature, err := bls.SignatureFromBytes(signature.Signature[:])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", avalancheWarp.ErrParseSignature, err)
+	}
+
+	vdrs, totalWeight, err := avalancheWarp.FlattenValidatorSet(validatorSet)
+	if err != nil {
+		return nil, err
+	}
+	signers, err := avalancheWarp.FilterValidators(signerIndices, vdrs)
+	if err != nil {
+		return nil, err
+	}
+	// Because signers is a subset of vdrs, this can never error.
+	signedWeight, _ := avalancheWarp.SumWeight(signers)
+
+	verification := &SignatureVerification{
+		Validators:        make([]ValidatorSignature, len(vdrs)),
+		NumSigners:        len(signers),
+		SignedWeight:      signedWeight,
+		TotalWeight:       totalWeight,
+		QuorumNumerator:   quorumNum,
+		QuorumDenominator: quorumDen,
+		QuorumReached:     avalancheWarp.VerifyWeight(signedWeight, totalWeight, quorumNum, quorumDen) == nil,
+	}
+	for i, vdr := range vdrs {
+		verification.Validators[i] = ValidatorSignature{
+			Index:      i,
+			NodeIDs:    vdr.NodeIDs,
+			PublicKey:  bls.PublicKeyToCompressedBytes(vdr.PublicKey),
+			Weight:     vdr.Weight,
+			StakeShare: stakeShare(vdr.Weight, totalWeight),
+			Signed:     signerIndices.Contains(i),
+		}
+	}
+	if len(signers) > 0 {
+		aggregatePublicKey, err := avalancheWarp.AggregatePublicKeys(signers)
+		if err != nil {
+			return nil, err
+		}
+		verification.SignatureValid = bls.Verify(aggregatePublicKey, aggregateSignature, msg.UnsignedMessage.Bytes())
+	}
+	return verification, nil
+}
+
+func stakeShare(weight uint64, totalWeight uint64) float64 {
+	if totalWeight == 0 {
+		return 0
+	}
+	return float64(weight) / float64(totalWeight)
+}
@@ -0,0 +1,142 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/validators"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+	"github.com/ava-labs/avalanchego/utils/set"
+	avalancheWarp "github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	"github.com/ava-labs/subnet-evm/precompile/contracts/warp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyWarpSignature(t *testing.T) {
+	unsignedMsg, err := avalancheWarp.NewUnsignedMessage(1, ids.ID{1}, []byte{1, 2, 3})
+	require.NoError(t, err)
+	otherMsg, err := avalancheWarp.NewUnsignedMessage(1, ids.ID{2}, []byte{1, 2, 3})
+	require.NoError(t, err)
+
+	validatorSet := map[ids.NodeID]*validators.GetValidatorOutput{}
+	secretKeys := map[string]*bls.SecretKey{}
+	for i, weight := range []uint64{50, 30, 20} {
+		sk, err := bls.NewSecretKey()
+		require.NoError(t, err)
+		pk := bls.PublicFromSecretKey(sk)
+		nodeID := ids.BuildTestNodeID([]byte{byte(i)})
+		validatorSet[nodeID] = &validators.GetValidatorOutput{NodeID: nodeID, PublicKey: pk, Weight: weight}
+		secretKeys[string(bls.PublicKeyToUncompressedBytes(pk))] = sk
+	}
+	// A validator without a BLS key counts towards the total weight but cannot sign.
+	validatorSet[ids.BuildTestNodeID([]byte{3})] = &validators.GetValidatorOutput{Weight: 100}
+	canonical, _, err := avalancheWarp.FlattenValidatorSet(validatorSet)
+	require.NoError(t, err)
+	weightIndex := func(weight uint64) int {
+		for i, vdr := range canonical {
+			if vdr.Weight == weight {
+				return i
+			}
+		}
+		t.Fatalf("no validator with weight %d", weight)
+		return -1
+	}
+
+	sign := func(msg *avalancheWarp.UnsignedMessage, weights ...uint64) *avalancheWarp.Message {
+		signers := set.NewBits()
+		var signatures []*bls.Signature
+		for _, weight := range weights {
+			i := weightIndex(weight)
+			signers.Add(i)
+			signatures = append(signatures, bls.Sign(secretKeys[string(canonical[i].PublicKeyBytes)], msg.Bytes()))
+		}
+		aggregate, err := bls.AggregateSignatures(signatures)
+		require.NoError(t, err)
+		signature := &avalancheWarp.BitSetSignature{Signers: signers.Bytes()}
+		copy(signature.Signature[:], bls.SignatureToBytes(aggregate))
+		signedMsg, err := avalancheWarp.NewMessage(unsignedMsg, signature)
+		require.NoError(t, err)
+		return signedMsg
+	}
+
+	var tests = []struct {
+		name              string
+		msg               *avalancheWarp.Message
+		validatorSet      map[ids.NodeID]*validators.GetValidatorOutput
+		quorumNum         uint64
+		expectedWeight    uint64
+		expectedQuorum    bool
+		expectedSignature bool
+		expectedErr       error
+		err               error
+	}{
+		{
+			name:              "quorum",
+			msg:               sign(unsignedMsg, 50, 30, 20),
+			validatorSet:      validatorSet,
+			quorumNum:         50,
+			expectedWeight:    100,
+			expectedQuorum:    true,
+			expectedSignature: true,
+		},
+		{
+			name:              "below default quorum",
+			msg:               sign(unsignedMsg, 50, 30, 20),
+			validatorSet:      validatorSet,
+			quorumNum:         warp.WarpDefaultQuorumNumerator,
+			expectedWeight:    100,
+			expectedSignature: true,
+			expectedErr:       avalancheWarp.ErrInsufficientWeight,
+		},
+		{
+			name:           "signature of another message",
+			msg:            sign(otherMsg, 50, 30),
+			validatorSet:   validatorSet,
+			quorumNum:      40,
+			expectedWeight: 80,
+			expectedQuorum: true,
+			expectedErr:    avalancheWarp.ErrInvalidSignature,
+		},
+		{
+			name:         "invalid quorum",
+			msg:          sign(unsignedMsg, 50),
+			validatorSet: validatorSet,
+			quorumNum:    101,
+			err:          ErrInvalidQuorum,
+		},
+		{
+			name:         "signer missing from validator set",
+			msg:          sign(unsignedMsg, 50, 30, 20),
+			validatorSet: map[ids.NodeID]*validators.GetValidatorOutput{},
+			quorumNum:    warp.WarpDefaultQuorumNumerator,
+			err:          avalancheWarp.ErrUnknownValidator,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verification, err := VerifyWarpSignature(tt.msg, tt.validatorSet, tt.quorumNum, warp.WarpQuorumDenominator)
+			require.ErrorIs(t, err, tt.err)
+			if tt.err != nil {
+				return
+			}
+			require.Equal(t, tt.expectedWeight, verification.SignedWeight)
+			require.Equal(t, uint64(200), verification.TotalWeight)
+			require.Equal(t, tt.expectedQuorum, verification.QuorumReached)
+			require.Equal(t, tt.expectedSignature, verification.SignatureValid)
+			require.ErrorIs(t, verification.Err(), tt.expectedErr)
+			require.Len(t, verification.Validators, 3)
+			signed := 0
+			for _, vdr := range verification.Validators {
+				if vdr.Signed {
+					signed++
+				}
+			}
+			require.Equal(t, verification.NumSigners, signed)
+			require.InDelta(t, 0.25, verification.Validators[weightIndex(50)].StakeShare, 1e-9)
+		})
+	}
+}